@@ -12,6 +12,8 @@ import (
 	"strings"
 
 	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/lock"
+	"github.com/gardener/docforge/pkg/reactor"
 	"github.com/gardener/docforge/pkg/resourcehandlers"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -42,10 +44,25 @@ type Options struct {
 	HugoPrettyUrls               bool              `mapstructure:"hugo-pretty-urls"` // TODO: hugo defaults to pretty urls -> make sense to use 'hugo-ugly-urls' instead
 	FlagsHugoSectionFiles        []string          `mapstructure:"hugo-section-files"`
 	HugoBaseURL                  string            `mapstructure:"hugo-base-url"`
-	UseGit                       bool              `mapstructure:"use-git"` // TODO: get rid of this option
+	HugoPageBundles              bool              `mapstructure:"hugo-page-bundles"`
+	MkDocs                       bool              `mapstructure:"mkdocs"`
+	MkDocsConfig                 string            `mapstructure:"mkdocs-config"`
+	UseGit                       bool              `mapstructure:"use-git"`
 	CacheHomeDir                 string            `mapstructure:"cache-dir"`
 	Credentials                  []Credential      `mapstructure:"credentials"` // TODO: one way to provide credentials (e.g. use only 'github-oauth-token-map')
 	ResourceMappings             map[string]string `mapstructure:"resourceMappings"`
+	ArchiveMappings              []ArchiveMapping  `mapstructure:"archiveMappings"`
+	LockFile                     string            `mapstructure:"lock-file"`
+	Locked                       bool              `mapstructure:"locked"`
+	Versions                     []string          `mapstructure:"versions"`
+	VersionsDataFile             string            `mapstructure:"versions-data-file"`
+	PropertiesPrecedence         string            `mapstructure:"properties-precedence"`
+	DownloadAllow                []string          `mapstructure:"download-allow"`
+	DownloadInclude              []string          `mapstructure:"download-include"`
+	DownloadExclude              []string          `mapstructure:"download-exclude"`
+	ResourcesPlacement           string            `mapstructure:"resources-placement"`
+	ResourcesName                string            `mapstructure:"resources-name"`
+	ResourcesMappingFile         string            `mapstructure:"resources-mapping-file"`
 	GhOAuthToken                 string            `mapstructure:"github-oauth-token"`     // TODO: one way to provide credentials
 	GhOAuthTokens                map[string]string `mapstructure:"github-oauth-token-map"` // TODO: one way to provide credentials
 }
@@ -55,6 +72,18 @@ type Credential struct {
 	Host       string
 	Username   string
 	OAuthToken string `mapstructure:"o-auth-token"` // TODO: one way to provide credentials
+	// Type is the type of the host: github (default), gitlab, gitea (or forgejo) or bitbucket
+	Type string
+}
+
+// ArchiveMapping maps a local repository archive to the repository URL and ref it is created from
+type ArchiveMapping struct {
+	// Repository is the repository URL, e.g. https://github.com/gardener/docforge
+	Repository string
+	// Ref is the branch, tag or commit of the archived content
+	Ref string
+	// Archive is the path to the .tar.gz, .tgz, .tar or .zip archive
+	Archive string
 }
 
 var vip *viper.Viper
@@ -68,29 +97,56 @@ func NewCommand(ctx context.Context) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 			var (
-				doc     *api.Documentation
-				rhs     []resourcehandlers.ResourceHandler
-				err     error
-				options *Options
+				doc      *api.Documentation
+				rhs      []resourcehandlers.ResourceHandler
+				err      error
+				options  *Options
+				locker   *lock.Locker
+				versions []*Version
 			)
 
 			options, err = NewOptions()
 			if err != nil {
 				return err
 			}
-			if rhs, err = initResourceHandlers(ctx, options); err != nil {
+			if locker, err = newLocker(options); err != nil {
 				return err
 			}
-			if doc, err = manifest(ctx, options.DocumentationManifestPath, rhs, options.Variables, options.Hugo); err != nil {
-				return err
+			if len(options.Versions) > 0 {
+				if versions, err = parseVersions(options.Versions); err != nil {
+					return err
+				}
+				if doc, rhs, err = versionedManifest(ctx, options, versions, locker); err != nil {
+					return err
+				}
+			} else {
+				if rhs, err = initResourceHandlers(ctx, options); err != nil {
+					return err
+				}
+				if locker != nil {
+					rhs = locker.Wrap(rhs...)
+				}
+				if doc, err = manifest(ctx, options.DocumentationManifestPath, rhs); err != nil {
+					return err
+				}
 			}
 			reactor, err := NewReactor(options, rhs)
 			if err != nil {
 				return err
 			}
+			if versions != nil {
+				// the manifests of the versions are resolved by versionedManifest
+				reactor.Options.ManifestResolved = true
+				if err = writeVersionsData(reactor.Options.Writer, options.VersionsDataFile, versions); err != nil {
+					return err
+				}
+			}
 			if err = reactor.Run(ctx, doc, options.DryRun); err != nil {
 				return err
 			}
+			if locker != nil && !options.Locked {
+				return locker.Write(ctx, options.LockFile)
+			}
 			return nil
 		},
 	}
@@ -104,6 +160,8 @@ func NewCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(completion)
 	genCmdDocs := NewGenCmdDocs()
 	cmd.AddCommand(genCmdDocs)
+	manifestCmd := NewManifestCmd(ctx)
+	cmd.AddCommand(manifestCmd)
 
 	klog.InitFlags(nil)
 	AddFlags(cmd)
@@ -195,14 +253,70 @@ func configureFlags(command *cobra.Command) {
 		"Rewrites the relative links of documentation files to root-relative where possible.")
 	_ = vip.BindPFlag("hugo-base-url", command.Flags().Lookup("hugo-base-url"))
 
+	command.Flags().Bool("hugo-page-bundles", false,
+		"Build documentation bundle for hugo with page bundles, i.e. the documents are written as name/index.md and the resources linked by a single document are downloaded in its bundle. Only useful with --hugo=true")
+	_ = vip.BindPFlag("hugo-page-bundles", command.Flags().Lookup("hugo-page-bundles"))
+
+	command.Flags().Bool("mkdocs", false,
+		"Build documentation for MkDocs. The documents are written in the docs folder of the destination, the section files as index.md, and the mkdocs.yml with the navigation of the documentation structure in the destination. Can't be used with --hugo=true")
+	_ = vip.BindPFlag("mkdocs", command.Flags().Lookup("mkdocs"))
+
+	command.Flags().String("mkdocs-config", "",
+		"Path to the base MkDocs configuration file, the generated navigation replaces its nav section. Only useful with --mkdocs=true")
+	_ = vip.BindPFlag("mkdocs-config", command.Flags().Lookup("mkdocs-config"))
+
 	command.Flags().Bool("use-git", false,
-		"Use Git for replication")
+		"Use Git for replication. GitHub repositories are cloned in the cache directory instead of being read through the GitHub API.")
 	_ = vip.BindPFlag("use-git", command.Flags().Lookup("use-git"))
 
 	command.Flags().StringSlice("hugo-section-files", []string{"readme.md", "readme", "read.me", "index.md", "index"},
-		"When building a Hugo-compliant documentation bundle, files with filename matching one form this list (in that order) will be renamed to _index.md. Only useful with --hugo=true")
+		"When building a Hugo-compliant documentation bundle, files with filename matching one form this list (in that order) will be renamed to _index.md. Only useful with --hugo=true or --mkdocs=true")
 	_ = vip.BindPFlag("hugo-section-files", command.Flags().Lookup("hugo-section-files"))
 
+	command.Flags().String("lock-file", "",
+		"Lock file listing the repository refs referenced by the documentation with the commit SHAs they are resolved to. The lock file is written after the build, unless --locked is set.")
+	_ = vip.BindPFlag("lock-file", command.Flags().Lookup("lock-file"))
+
+	command.Flags().Bool("locked", false,
+		"Pins the repository refs to the commit SHAs from the lock file specified by --lock-file. Refs missing in the lock file fail the build.")
+	_ = vip.BindPFlag("locked", command.Flags().Lookup("locked"))
+
+	command.Flags().StringSlice("versions", []string{},
+		"Builds the documentation once per version into versioned sub-directories of the destination. A version is a repository ref (branch, tag or commit) or <name>=<ref>, the ref of the manifest URL is replaced by the version ref and the 'version' manifest variable is set to it.")
+	_ = vip.BindPFlag("versions", command.Flags().Lookup("versions"))
+
+	command.Flags().String("versions-data-file", "versions.json",
+		"Path relative to the destination of the versions data file written when building multiple versions. Only useful with --versions.")
+	_ = vip.BindPFlag("versions-data-file", command.Flags().Lookup("versions-data-file"))
+
+	command.Flags().String("properties-precedence", string(api.ExplicitPrecedence),
+		"Precedence rule for the conflicting properties of merged container nodes. Must be one of: 'explicit' (the properties defined in the manifest win) or 'selected' (the properties of the nodes resolved by node selectors win).")
+	_ = vip.BindPFlag("properties-precedence", command.Flags().Lookup("properties-precedence"))
+
+	command.Flags().StringSlice("download-allow", reactor.DefaultDownloadAllow,
+		"Host patterns, optionally followed by a path prefix, of the absolute links to embeddable resources (e.g. images) that are downloaded, e.g. 'github.com/gardener/' or '*.github.tools.sap'. Relative links to embeddable resources are always downloaded.")
+	_ = vip.BindPFlag("download-allow", command.Flags().Lookup("download-allow"))
+
+	command.Flags().StringSlice("download-include", []string{},
+		"Regular expressions matching the absolute links to embeddable resources that are downloaded.")
+	_ = vip.BindPFlag("download-include", command.Flags().Lookup("download-include"))
+
+	command.Flags().StringSlice("download-exclude", []string{},
+		"Regular expressions matching the absolute links to embeddable resources that are not downloaded. Takes precedence over --download-allow and --download-include. The download policy is overridden for the documents of a node and its descendants by the node property 'download: true|false'.")
+	_ = vip.BindPFlag("download-exclude", command.Flags().Lookup("download-exclude"))
+
+	command.Flags().String("resources-placement", reactor.SharedPlacement,
+		"Placement of the downloaded resources. Must be one of: 'shared' (in the resources download path of the destination), 'document' (next to the referencing documents) or 'section' (in the resources download path of the referencing documents folder). If the resources download path starts with '/', the links to the resources are absolute paths, otherwise relative.")
+	_ = vip.BindPFlag("resources-placement", command.Flags().Lookup("resources-placement"))
+
+	command.Flags().String("resources-name", reactor.DefaultResourceName,
+		"Naming template of the downloaded resources. The variables are: $name (the resource name without extension), $ext (the resource extension), $hash (a hash of the resource URL) and $contenthash (a hash of the resource content). The name may contain '/', e.g. '$contenthash$ext' or 'images/$name$ext'.")
+	_ = vip.BindPFlag("resources-name", command.Flags().Lookup("resources-name"))
+
+	command.Flags().String("resources-mapping-file", "",
+		"Path relative to the destination of a JSON report mapping the URLs of the downloaded resources to the stored files. Resources with the same content are stored once per placement folder. Not written if empty.")
+	_ = vip.BindPFlag("resources-mapping-file", command.Flags().Lookup("resources-mapping-file"))
+
 	cacheDir := ""
 	userHomeDir, err := os.UserHomeDir()
 	if err == nil {
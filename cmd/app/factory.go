@@ -6,16 +6,27 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/lock"
 	"github.com/gardener/docforge/pkg/reactor"
 	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/archive"
+	"github.com/gardener/docforge/pkg/resourcehandlers/bitbucket"
+	"github.com/gardener/docforge/pkg/resourcehandlers/git"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitea"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitlab"
+	"github.com/gardener/docforge/pkg/resourcehandlers/local"
 	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+	"github.com/gardener/docforge/pkg/resourcehandlers/web"
 	"github.com/gardener/docforge/pkg/util/osshim"
 	"github.com/gardener/docforge/pkg/writers"
 	"github.com/google/go-github/v43/github"
@@ -34,6 +45,45 @@ func NewReactor(o *Options, rhs []resourcehandlers.ResourceHandler) (*reactor.Re
 		PrettyURLs:     o.HugoPrettyUrls,
 		BaseURL:        o.HugoBaseURL,
 		IndexFileNames: o.FlagsHugoSectionFiles,
+		PageBundles:    o.HugoPageBundles,
+	}
+
+	mkdocs := &reactor.MkDocs{Enabled: o.MkDocs}
+	if o.MkDocs {
+		if o.Hugo {
+			return nil, errors.New("--mkdocs and --hugo can't be used together")
+		}
+		if o.MkDocsConfig != "" {
+			config, err := os.ReadFile(o.MkDocsConfig)
+			if err != nil {
+				return nil, fmt.Errorf("reading MkDocs configuration %s failed: %v", o.MkDocsConfig, err)
+			}
+			mkdocs.Config = config
+		}
+	}
+
+	precedence := api.Precedence(o.PropertiesPrecedence)
+	switch precedence {
+	case "":
+		precedence = api.ExplicitPrecedence
+	case api.ExplicitPrecedence, api.SelectedPrecedence:
+	default:
+		return nil, fmt.Errorf("unknown properties precedence '%s'. Must be one of %v", o.PropertiesPrecedence, []api.Precedence{api.ExplicitPrecedence, api.SelectedPrecedence})
+	}
+
+	downloadPolicy := &reactor.DownloadPolicy{Allow: o.DownloadAllow}
+	var err error
+	if downloadPolicy.Include, err = compileRegexps(o.DownloadInclude, "download-include"); err != nil {
+		return nil, err
+	}
+	if downloadPolicy.Exclude, err = compileRegexps(o.DownloadExclude, "download-exclude"); err != nil {
+		return nil, err
+	}
+
+	switch o.ResourcesPlacement {
+	case "", reactor.SharedPlacement, reactor.DocumentPlacement, reactor.SectionPlacement:
+	default:
+		return nil, fmt.Errorf("unknown resources placement '%s'. Must be one of %v", o.ResourcesPlacement, []string{reactor.SharedPlacement, reactor.DocumentPlacement, reactor.SectionPlacement})
 	}
 
 	opt := &reactor.Options{
@@ -47,19 +97,38 @@ func NewReactor(o *Options, rhs []resourcehandlers.ResourceHandler) (*reactor.Re
 		Resolve:                      o.Resolve,
 		ManifestPath:                 o.DocumentationManifestPath,
 		Hugo:                         hugo,
+		MkDocs:                       mkdocs,
+		PropertiesPrecedence:         precedence,
+		DownloadPolicy:               downloadPolicy,
+		ResourcesLayout: &reactor.ResourcesLayout{
+			Placement: o.ResourcesPlacement,
+			Name:      o.ResourcesName,
+		},
+		ResourcesMappingFile: o.ResourcesMappingFile,
 	}
 
+	docsPath := opt.DestinationPath
+	if mkdocs.Enabled {
+		docsPath = filepath.Join(opt.DestinationPath, reactor.MkDocsDocsDir)
+	}
 	if o.DryRun {
 		opt.DryRunWriter = writers.NewDryRunWritersFactory(os.Stdout)
-		opt.Writer = opt.DryRunWriter.GetWriter(opt.DestinationPath)
-		opt.ResourceDownloadWriter = opt.DryRunWriter.GetWriter(filepath.Join(opt.DestinationPath, opt.ResourcesPath))
+		opt.Writer = opt.DryRunWriter.GetWriter(docsPath)
+		// the download targets are relative to the documents root, according to the resources placement
+		opt.ResourceDownloadWriter = opt.DryRunWriter.GetWriter(docsPath)
+		opt.ConfigWriter = opt.DryRunWriter.GetWriter(opt.DestinationPath)
 	} else {
 		opt.Writer = &writers.FSWriter{
-			Root: opt.DestinationPath,
-			Hugo: opt.Hugo.Enabled,
+			Root:        docsPath,
+			Hugo:        opt.Hugo.Enabled,
+			PageBundles: opt.Hugo.PageBundles,
+			MkDocs:      mkdocs.Enabled,
 		}
 		opt.ResourceDownloadWriter = &writers.FSWriter{
-			Root: filepath.Join(opt.DestinationPath, opt.ResourcesPath),
+			Root: docsPath,
+		}
+		opt.ConfigWriter = &writers.FSWriter{
+			Root: opt.DestinationPath,
 		}
 	}
 
@@ -73,9 +142,39 @@ func NewReactor(o *Options, rhs []resourcehandlers.ResourceHandler) (*reactor.Re
 	return reactor.NewReactor(opt)
 }
 
+func compileRegexps(exprs []string, flag string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, e := range exprs {
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s expression %s: %v", flag, e, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Supported Credential types
+const (
+	// GitHubType is the type of GitHub & GitHub Enterprise hosts
+	GitHubType = "github"
+	// GitLabType is the type of GitLab hosts
+	GitLabType = "gitlab"
+	// GiteaType is the type of Gitea hosts
+	GiteaType = "gitea"
+	// ForgejoType is the type of Forgejo hosts, handled as Gitea hosts
+	ForgejoType = "forgejo"
+	// BitbucketType is the type of Bitbucket Server hosts
+	BitbucketType = "bitbucket"
+)
+
 func initResourceHandlers(ctx context.Context, o *Options) ([]resourcehandlers.ResourceHandler, error) {
 	var rhs []resourcehandlers.ResourceHandler
 	var errs *multierror.Error
+	// archive handlers serve repository refs instead of the host handlers, must precede them
+	for _, m := range o.ArchiveMappings {
+		rhs = append(rhs, archive.NewArchive(m.Repository, m.Ref, m.Archive, o.Variables, o.Hugo))
+	}
 	for _, cred := range o.Credentials {
 		instance := cred.Host
 		if !strings.HasPrefix(instance, "https://") && !strings.HasPrefix(instance, "http://") {
@@ -87,33 +186,78 @@ func initResourceHandlers(ctx context.Context, o *Options) ([]resourcehandlers.R
 			continue
 		}
 		cachePath := filepath.Join(o.CacheHomeDir, "diskv", cred.Host)
-		client, httpClient, err := buildClient(ctx, cred.OAuthToken, instance, cachePath)
-		if err != nil {
-			errs = multierror.Append(errs, err)
+		var rh resourcehandlers.ResourceHandler
+		switch strings.ToLower(cred.Type) {
+		case "", GitHubType:
+			client, httpClient, err := buildClient(ctx, cred.OAuthToken, instance, cachePath)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+			}
+			rh = newResourceHandler(u.Host, o.CacheHomeDir, &cred.Username, cred.OAuthToken, client, httpClient, o.UseGit, o.ResourceMappings, o.Variables, o.Hugo)
+		case GitLabType:
+			rh = gitlab.NewGitLab(buildHTTPClient(ctx, "", cachePath), cred.OAuthToken, []string{u.Host}, o.Variables, o.Hugo)
+		case GiteaType, ForgejoType:
+			rh = gitea.NewGitea(buildHTTPClient(ctx, "", cachePath), cred.OAuthToken, []string{u.Host}, o.Variables, o.Hugo)
+		case BitbucketType:
+			rh = bitbucket.NewBitbucket(buildHTTPClient(ctx, "", cachePath), cred.Username, cred.OAuthToken, []string{u.Host}, o.Variables, o.Hugo)
+		default:
+			errs = multierror.Append(errs, fmt.Errorf("unsupported type %s for host %s", cred.Type, cred.Host))
+			continue
 		}
-		rh := newResourceHandler(u.Host, o.CacheHomeDir, &cred.Username, cred.OAuthToken, client, httpClient, o.UseGit, o.ResourceMappings, o.Variables, o.Hugo)
 		rhs = append(rhs, rh)
 	}
+	// local file system handler
+	rhs = append(rhs, local.NewLocal(&osshim.OsShim{}, o.Variables, o.Hugo))
+	// generic HTTP(S) handler accepts all remaining URLs, must be the last one
+	rhs = append(rhs, web.NewWeb(buildHTTPClient(ctx, "", filepath.Join(o.CacheHomeDir, "diskv", "web")), o.Variables, o.Hugo))
 
 	return rhs, errs.ErrorOrNil()
 }
 
-// TODO: remove unused params
+// newLocker creates lock.Locker recording the repository refs if lock file is specified
+// or pinning them to the locked commit SHAs in locked mode
+func newLocker(o *Options) (*lock.Locker, error) {
+	if o.Locked {
+		if o.LockFile == "" {
+			return nil, errors.New("--locked requires --lock-file")
+		}
+		return lock.Load(o.LockFile)
+	}
+	if o.LockFile == "" {
+		return nil, nil
+	}
+	return lock.NewLocker(), nil
+}
+
 func newResourceHandler(host, homeDir string, user *string, token string, client *github.Client, httpClient *http.Client, useGit bool, localMappings map[string]string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
 	rawHost := "raw." + host
 	if host == "github.com" {
 		rawHost = "raw.githubusercontent.com"
 	}
-
-	//	if useGit { TODO: remove unused resource handlers
-	//		return git.NewResourceHandler(filepath.Join(homeDir, git.CacheDir), user, token, client, httpClient, []string{host, rawHost}, localMappings, branchesMap, flagVars)
-	//	}
-	//	return ghrs.NewResourceHandler(client, httpClient, []string{host, rawHost}, branchesMap, flagVars)
-
+	if useGit {
+		return git.NewResourceHandler(filepath.Join(homeDir, git.CacheDir), user, token, client, httpClient, []string{host, rawHost}, localMappings, flagVars, hugoEnabled)
+	}
 	return pg.NewPG(client, httpClient, &osshim.OsShim{}, []string{host, rawHost}, localMappings, flagVars, hugoEnabled)
 }
 
 func buildClient(ctx context.Context, accessToken string, host string, cachePath string) (*github.Client, *http.Client, error) {
+	httpClient := buildHTTPClient(ctx, accessToken, cachePath)
+	var (
+		client *github.Client
+		err    error
+	)
+
+	if host == "https://github.com" {
+		client = github.NewClient(httpClient)
+		return client, httpClient, nil
+	}
+	client, err = github.NewEnterpriseClient(host, "", httpClient)
+	return client, httpClient, err
+}
+
+// buildHTTPClient creates HTTP client with transport level persistent cache,
+// the access token is used as OAuth2 bearer token if provided
+func buildHTTPClient(ctx context.Context, accessToken string, cachePath string) *http.Client {
 	base := http.DefaultTransport
 	if len(accessToken) > 0 {
 		// if token provided replace base RoundTripper
@@ -134,17 +278,5 @@ func buildClient(ctx context.Context, accessToken string, host string, cachePath
 		MarkCachedResponses: true,
 	}
 
-	httpClient := cacheTransport.Client()
-
-	var (
-		client *github.Client
-		err    error
-	)
-
-	if host == "https://github.com" {
-		client = github.NewClient(httpClient)
-		return client, httpClient, nil
-	}
-	client, err = github.NewEnterpriseClient(host, "", httpClient)
-	return client, httpClient, err
+	return cacheTransport.Client()
 }
@@ -7,47 +7,184 @@ package app
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/reactor"
 	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/local"
+	"github.com/spf13/cobra"
 )
 
 // Manifest reads the resource at uri, resolves it as template applying vars,
 // and finally parses it into api.Documentation model
-func manifest(ctx context.Context, uri string, resourceHandlers []resourcehandlers.ResourceHandler, flagsVars map[string]string, hugoEnabled bool) (*api.Documentation, error) {
-	var (
-		handler         resourcehandlers.ResourceHandler
-		manifestContent []byte
-	)
+func manifest(ctx context.Context, uri string, resourceHandlers []resourcehandlers.ResourceHandler) (*api.Documentation, error) {
+	var handler resourcehandlers.ResourceHandler
 	uri = strings.TrimSpace(uri)
 	registry := resourcehandlers.NewRegistry(resourceHandlers...)
 
+	uri, err := manifestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if handler = registry.Get(uri); handler == nil {
+		return nil, fmt.Errorf("no suitable reader found for %s. Is this path correct?", uri)
+	}
+	return handler.ResolveDocumentation(ctx, uri)
+}
+
+// manifestURI returns the file URI of manifests in the file system, so that relative paths
+// in the manifest are resolved against its location
+func manifestURI(uri string) (string, error) {
 	//check if uri is in file system
 	fileInfo, err := os.Stat(uri)
-	if err == nil {
-		//uri is from file system
+	if err != nil {
+		return uri, nil
+	}
+	//uri is from file system
+	if fileInfo.IsDir() {
+		return "", fmt.Errorf("top level manifest %s is a directory", uri)
+	}
+	if uri, err = filepath.Abs(uri); err != nil {
+		return "", err
+	}
+	return local.ToURI(uri), nil
+}
 
-		if fileInfo.IsDir() {
-			return nil, fmt.Errorf("top level manifest %s is a directory", uri)
-		}
+// NewManifestCmd creates the manifest command with subcommands working
+// on documentation manifests without building them
+func NewManifestCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Work with documentation manifests",
+	}
+	cmd.AddCommand(newManifestValidateCmd(ctx))
+	cmd.AddCommand(newManifestSchemaCmd())
+	return cmd
+}
 
-		if manifestContent, err = ioutil.ReadFile(uri); err != nil {
-			return nil, err
-		}
+func newManifestValidateCmd(ctx context.Context) *cobra.Command {
+	var (
+		vars map[string]string
+		hugo bool
+	)
+	cmd := &cobra.Command{
+		Use:   "validate <manifest>...",
+		Short: "Validate documentation manifests and the manifests they import",
+		Long: `Validates documentation manifests, including the manifests imported by nodesSelector paths, without building them.
+All problems are reported in <manifest>:<line>:<column>: <message> format. The positions refer to the manifest with resolved variables.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			options, err := NewOptions()
+			if err != nil {
+				return err
+			}
+			options.Variables = vars
+			options.Hugo = hugo
+			rhs, err := initResourceHandlers(ctx, options)
+			if err != nil {
+				return err
+			}
+			v := &manifestValidator{
+				registry: resourcehandlers.NewRegistry(rhs...),
+				vars:     vars,
+				hugo:     hugo,
+				out:      cmd.OutOrStdout(),
+				visited:  map[string]bool{},
+			}
+			for _, uri := range args {
+				if uri, err = manifestURI(strings.TrimSpace(uri)); err != nil {
+					return err
+				}
+				v.validate(ctx, uri)
+			}
+			if v.problems > 0 {
+				return fmt.Errorf("%d problem(s) found in documentation manifests", v.problems)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringToStringVar(&vars, "variables", map[string]string{},
+		"Variables applied to parameterized (using Go template) manifest.")
+	cmd.Flags().BoolVar(&hugo, "hugo", false,
+		"Validate the manifests for hugo bundles, i.e. nodes with index: true property collide with _index.md nodes.")
+	return cmd
+}
+
+func newManifestSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of documentation manifests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := api.JSONSchema()
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+}
+
+// manifestValidator validates manifests and follows the imported ones
+type manifestValidator struct {
+	registry resourcehandlers.Registry
+	vars     map[string]string
+	hugo     bool
+	out      io.Writer
+	visited  map[string]bool
+	problems int
+}
 
-		doc, err := api.ParseWithMetadata(manifestContent, "master", flagsVars, hugoEnabled)
+func (v *manifestValidator) validate(ctx context.Context, uri string) {
+	if v.visited[uri] {
+		return
+	}
+	v.visited[uri] = true
+	handler := v.registry.Get(uri)
+	if handler == nil {
+		v.report(uri, fmt.Errorf("no suitable reader found for %s. Is this path correct?", uri))
+		return
+	}
+	ref := "master"
+	if rr, ok := handler.(resourcehandlers.RefResolver); ok {
+		if _, r, err := rr.ResourceRef(uri); err == nil {
+			ref = r
+		}
+	}
+	b, err := handler.Read(ctx, uri)
+	if err != nil {
+		v.report(uri, err)
+		return
+	}
+	for _, e := range api.Validate(b, ref, v.vars, v.hugo) {
+		v.report(uri, e)
+	}
+	for _, p := range api.ManifestPaths(b, ref, v.vars) {
+		abs, err := handler.BuildAbsLink(uri, p)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+			v.report(uri, err)
+			continue
+		}
+		if isManifest, err := reactor.IsManifestPath(abs); err == nil && isManifest {
+			v.validate(ctx, abs)
 		}
-
-		return doc, nil
 	}
+}
 
-	if handler = registry.Get(uri); handler == nil {
-		return nil, fmt.Errorf("no suitable reader found for %s. Is this path correct?", uri)
+func (v *manifestValidator) report(uri string, err error) {
+	v.problems++
+	if u, e := url.Parse(uri); e == nil && u.Scheme == "file" {
+		uri = u.Path
 	}
-	return handler.ResolveDocumentation(ctx, uri)
+	if _, ok := err.(*api.ValidationError); ok {
+		fmt.Fprintf(v.out, "%s:%s\n", uri, err)
+		return
+	}
+	fmt.Fprintf(v.out, "%s: %s\n", uri, err)
 }
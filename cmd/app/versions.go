@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/lock"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/writers"
+)
+
+// Version is a documentation version built from a repository ref
+type Version struct {
+	// Name is the version name, e.g. v1.40
+	Name string `json:"name"`
+	// Ref is the branch, tag or commit the version is built from
+	Ref string `json:"ref"`
+	// Path is the versioned sub-directory in the documentation bundle
+	Path string `json:"path"`
+}
+
+// parseVersions parses the versions specified as `<ref>` or `<name>=<ref>`
+func parseVersions(specs []string) ([]*Version, error) {
+	var versions []*Version
+	paths := make(map[string]string)
+	for _, s := range specs {
+		v := &Version{Name: s, Ref: s}
+		if i := strings.Index(s, "="); i >= 0 {
+			v.Name, v.Ref = s[:i], s[i+1:]
+		}
+		if v.Name == "" || v.Ref == "" {
+			return nil, fmt.Errorf("invalid version %q, expected <ref> or <name>=<ref>", s)
+		}
+		v.Path = strings.ReplaceAll(v.Name, "/", "-")
+		if p, ok := paths[v.Path]; ok {
+			return nil, fmt.Errorf("versions %s and %s are built into the same directory %s", p, s, v.Path)
+		}
+		paths[v.Path] = s
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// versionedManifest resolves the manifest once per version and mounts the resolved structures
+// in versioned container nodes, so that the links between the versions are resolved by a single build.
+// Each version is resolved by own resource handlers which `version` variable is set to the version ref
+// and `versions` variable to the comma-separated refs of all versions. The returned resource handlers
+// are the handlers of all versions.
+func versionedManifest(ctx context.Context, o *Options, versions []*Version, locker *lock.Locker) (*api.Documentation, []resourcehandlers.ResourceHandler, error) {
+	var all []resourcehandlers.ResourceHandler
+	refs := make([]string, 0, len(versions))
+	for _, v := range versions {
+		refs = append(refs, v.Ref)
+	}
+	doc := &api.Documentation{}
+	for _, v := range versions {
+		vo := *o
+		vo.Variables = make(map[string]string, len(o.Variables)+2)
+		for k, val := range o.Variables {
+			vo.Variables[k] = val
+		}
+		vo.Variables["version"] = v.Ref
+		vo.Variables["versions"] = strings.Join(refs, ",")
+		rhs, err := initResourceHandlers(ctx, &vo)
+		if err != nil {
+			return nil, nil, err
+		}
+		vo.DocumentationManifestPath = versionManifestURI(o.DocumentationManifestPath, v.Ref, rhs)
+		if locker != nil {
+			rhs = locker.Wrap(rhs...)
+		}
+		all = append(all, rhs...)
+		vdoc, err := manifest(ctx, vo.DocumentationManifestPath, rhs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read manifest of version %s: %v", v.Name, err)
+		}
+		r, err := NewReactor(&vo, rhs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = r.ResolveManifest(ctx, vdoc); err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve manifest of version %s: %s. %+v", v.Name, vo.DocumentationManifestPath, err)
+		}
+		vn := &api.Node{Name: v.Path, Nodes: vdoc.Structure}
+		vn.SetParentsDownwards()
+		doc.Structure = append(doc.Structure, vn)
+	}
+	return doc, all, nil
+}
+
+// versionManifestURI returns the manifest URI with its ref replaced by the version ref.
+// Manifests without ref (e.g. local ones) can use the `version` variable instead.
+func versionManifestURI(uri string, ref string, rhs []resourcehandlers.ResourceHandler) string {
+	uri = strings.TrimSpace(uri)
+	rh := resourcehandlers.NewRegistry(rhs...).Get(uri)
+	if rr, ok := rh.(resourcehandlers.RefResolver); ok {
+		if _, r, err := rr.ResourceRef(uri); err == nil && r != "" {
+			if p, err := rr.PinRef(uri, ref); err == nil {
+				return p
+			}
+		}
+	}
+	return uri
+}
+
+// writeVersionsData writes the versions data file used by the site themes (e.g. for a version switcher).
+// The file path is relative to the destination.
+func writeVersionsData(w writers.Writer, fn string, versions []*Version) error {
+	b, err := json.MarshalIndent(map[string]interface{}{"versions": versions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir, name := filepath.Split(filepath.Clean(fn))
+	return w.Write(name, dir, append(b, '\n'), nil)
+}
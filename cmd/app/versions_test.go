@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/resourcehandlersfakes"
+	"github.com/gardener/docforge/pkg/writers/writersfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// refResourceHandler is a resource handler resolving the refs in the resource URIs
+type refResourceHandler struct {
+	*resourcehandlersfakes.FakeResourceHandler
+	*resourcehandlersfakes.FakeRefResolver
+}
+
+var _ = Describe("Versions", func() {
+	DescribeTable("parsing versions", func(specs []string, exp []*Version, expErr string) {
+		versions, err := parseVersions(specs)
+		if expErr != "" {
+			Expect(err).To(MatchError(expErr))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(Equal(exp))
+	},
+		Entry("refs", []string{"master", "v1.40.0"}, []*Version{
+			{Name: "master", Ref: "master", Path: "master"},
+			{Name: "v1.40.0", Ref: "v1.40.0", Path: "v1.40.0"},
+		}, ""),
+		Entry("named refs", []string{"latest=master", "v1.40=release-v1.40"}, []*Version{
+			{Name: "latest", Ref: "master", Path: "latest"},
+			{Name: "v1.40", Ref: "release-v1.40", Path: "v1.40"},
+		}, ""),
+		Entry("refs with slashes", []string{"release/v1.40"}, []*Version{
+			{Name: "release/v1.40", Ref: "release/v1.40", Path: "release-v1.40"},
+		}, ""),
+		Entry("missing name", []string{"=master"}, nil, `invalid version "=master", expected <ref> or <name>=<ref>`),
+		Entry("missing ref", []string{"latest="}, nil, `invalid version "latest=", expected <ref> or <name>=<ref>`),
+		Entry("duplicate names", []string{"latest=master", "latest=main"}, nil, "versions latest=master and latest=main are built into the same directory latest"),
+		Entry("duplicate paths", []string{"release/v1.40", "release-v1.40"}, nil, "versions release/v1.40 and release-v1.40 are built into the same directory release-v1.40"),
+	)
+
+	DescribeTable("versioning manifest URIs", func(uri string, ref string, exp string) {
+		rh := &refResourceHandler{&resourcehandlersfakes.FakeResourceHandler{}, &resourcehandlersfakes.FakeRefResolver{}}
+		rh.AcceptCalls(func(uri string) bool {
+			return strings.HasPrefix(uri, "https://github.com/")
+		})
+		rh.ResourceRefCalls(func(uri string) (string, string, error) {
+			p := strings.Split(strings.TrimPrefix(uri, "https://github.com/"), "/")
+			if len(p) < 4 {
+				return "", "", errors.New("no ref")
+			}
+			return "https://github.com/" + p[0] + "/" + p[1], p[3], nil
+		})
+		rh.PinRefCalls(func(uri string, ref string) (string, error) {
+			_, r, _ := rh.ResourceRef(uri)
+			return strings.Replace(uri, "/"+r+"/", "/"+ref+"/", 1), nil
+		})
+		Expect(versionManifestURI(uri, ref, []resourcehandlers.ResourceHandler{rh})).To(Equal(exp))
+	},
+		Entry("remote manifest", "https://github.com/gardener/docs/blob/master/.docforge/manifest.yaml", "v1.40.0",
+			"https://github.com/gardener/docs/blob/v1.40.0/.docforge/manifest.yaml"),
+		Entry("trimmed remote manifest", " https://github.com/gardener/docs/blob/master/manifest.yaml\n", "v1.40.0",
+			"https://github.com/gardener/docs/blob/v1.40.0/manifest.yaml"),
+		Entry("remote manifest without ref", "https://github.com/gardener/docs", "v1.40.0",
+			"https://github.com/gardener/docs"),
+		Entry("local manifest", "/docs/.docforge/manifest.yaml", "v1.40.0",
+			"/docs/.docforge/manifest.yaml"),
+	)
+
+	DescribeTable("writing versions data", func(fn string, expName string, expPath string) {
+		w := &writersfakes.FakeWriter{}
+		versions := []*Version{
+			{Name: "latest", Ref: "master", Path: "latest"},
+			{Name: "release/v1.40", Ref: "release/v1.40", Path: "release-v1.40"},
+		}
+		Expect(writeVersionsData(w, fn, versions)).To(Succeed())
+		Expect(w.WriteCallCount()).To(Equal(1))
+		name, path, b, node := w.WriteArgsForCall(0)
+		Expect(name).To(Equal(expName))
+		Expect(path).To(Equal(expPath))
+		Expect(node).To(BeNil())
+		Expect(string(b)).To(MatchJSON(`{"versions": [
+			{"name": "latest", "ref": "master", "path": "latest"},
+			{"name": "release/v1.40", "ref": "release/v1.40", "path": "release-v1.40"}
+		]}`))
+		Expect(string(b)).To(HaveSuffix("}\n"))
+	},
+		Entry("file in the destination", "versions.json", "versions.json", ""),
+		Entry("file in a sub-directory", "data/versions.json", "versions.json", "data/"),
+		Entry("unclean path", "./data//versions.json", "versions.json", "data/"),
+	)
+
+	It("returns the write errors", func() {
+		w := &writersfakes.FakeWriter{}
+		w.WriteReturns(errors.New("write failed"))
+		Expect(writeVersionsData(w, "versions.json", nil)).To(MatchError("write failed"))
+	})
+})
@@ -0,0 +1,275 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// evalCondition evaluates a `when` condition against the manifest variables.
+// A condition compares variables with values, e.g. `edition=enterprise` or `version>=1.30`,
+// using the `=` (or `==`), `!=`, `<`, `<=`, `>`, `>=` operators. The values are compared
+// as semantic versions, numbers or strings, whichever applies to both sides.
+// A variable alone is true if it is set to a value other than empty, `false` or `0`.
+// Conditions are combined with `&&`, `||`, `!` and parentheses.
+func evalCondition(expr string, vars map[string]string) (bool, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %v", expr, err)
+	}
+	p := &conditionParser{tokens: tokens, vars: vars}
+	res, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos].value)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %v", expr, err)
+	}
+	return res, nil
+}
+
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	stringToken
+	operatorToken
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// operators ordered by length, so that the longest operator is matched first
+var conditionOperators = []string{"&&", "||", "==", "!=", ">=", "<=", "=", ">", "<", "!", "(", ")"}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		if c == ' ' || c == '\t' {
+			i++
+			continue
+		}
+		if c == '"' || c == '\'' {
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: stringToken, value: expr[i+1 : i+1+end]})
+			i += end + 2
+			continue
+		}
+		if op := operatorAt(expr[i:]); op != "" {
+			tokens = append(tokens, token{kind: operatorToken, value: op})
+			i += len(op)
+			continue
+		}
+		start := i
+		for i < len(expr) && !strings.ContainsRune(" \t\"'&|=!<>()", rune(expr[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("unexpected %c at %d", c, i)
+		}
+		tokens = append(tokens, token{kind: wordToken, value: expr[start:i]})
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	return tokens, nil
+}
+
+func operatorAt(s string) string {
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// conditionParser is a recursive descent parser evaluating the condition tokens
+type conditionParser struct {
+	tokens []token
+	pos    int
+	vars   map[string]string
+}
+
+func (p *conditionParser) peek(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == operatorToken && p.tokens[p.pos].value == op
+}
+
+func (p *conditionParser) or() (bool, error) {
+	res, err := p.and()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var r bool
+		if r, err = p.and(); err == nil {
+			res = res || r
+		}
+	}
+	return res, err
+}
+
+func (p *conditionParser) and() (bool, error) {
+	res, err := p.unary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var r bool
+		if r, err = p.unary(); err == nil {
+			res = res && r
+		}
+	}
+	return res, err
+}
+
+func (p *conditionParser) unary() (bool, error) {
+	if p.peek("!") {
+		p.pos++
+		res, err := p.unary()
+		return !res, err
+	}
+	if p.peek("(") {
+		p.pos++
+		res, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if !p.peek(")") {
+			return false, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return res, nil
+	}
+	return p.comparison()
+}
+
+func (p *conditionParser) comparison() (bool, error) {
+	if p.pos >= len(p.tokens) {
+		return false, fmt.Errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	if t.kind != wordToken {
+		return false, fmt.Errorf("expected variable, got %s", t.value)
+	}
+	p.pos++
+	val := p.vars[t.value]
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != operatorToken || !isComparison(p.tokens[p.pos].value) {
+		return val != "" && val != "false" && val != "0", nil
+	}
+	op := p.tokens[p.pos].value
+	p.pos++
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind == operatorToken {
+		return false, fmt.Errorf("expected value after %s", op)
+	}
+	c := compareValues(val, p.tokens[p.pos].value)
+	p.pos++
+	switch op {
+	case "=", "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "=", "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// compareValues compares the values as semantic versions, numbers or strings
+func compareValues(a, b string) int {
+	if va, err := semver.NewVersion(a); err == nil {
+		if vb, err := semver.NewVersion(b); err == nil {
+			return va.Compare(vb)
+		}
+	}
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// resolveConditions removes the nodes and node selectors which `when` conditions are false
+func resolveConditions(d *Documentation, vars map[string]string) error {
+	var err error
+	if d.NodeSelector, err = resolveSelectorCondition(d.NodeSelector, vars); err != nil {
+		return err
+	}
+	d.Structure, err = resolveNodesConditions(d.Structure, vars)
+	return err
+}
+
+func resolveNodesConditions(nodes []*Node, vars map[string]string) ([]*Node, error) {
+	if nodes == nil {
+		return nil, nil
+	}
+	res := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if n.When != "" {
+			ok, err := evalCondition(n.When, vars)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			n.When = ""
+		}
+		var err error
+		isContainer := n.NodeSelector != nil || len(n.Nodes) > 0
+		if n.NodeSelector, err = resolveSelectorCondition(n.NodeSelector, vars); err != nil {
+			return nil, err
+		}
+		if n.Nodes, err = resolveNodesConditions(n.Nodes, vars); err != nil {
+			return nil, err
+		}
+		if isContainer && n.NodeSelector == nil && len(n.Nodes) == 0 {
+			// all content of the container is excluded
+			continue
+		}
+		res = append(res, n)
+	}
+	return res, nil
+}
+
+func resolveSelectorCondition(ns *NodeSelector, vars map[string]string) (*NodeSelector, error) {
+	if ns == nil || ns.When == "" {
+		return ns, nil
+	}
+	ok, err := evalCondition(ns.When, vars)
+	if err != nil || !ok {
+		return nil, err
+	}
+	ns.When = ""
+	return ns, nil
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"github.com/gardener/docforge/pkg/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Conditions", func() {
+	DescribeTable("evaluating when conditions", func(when string, vars map[string]string, included bool) {
+		manifest := []byte(`
+structure:
+- name: always.md
+  source: https://github.com/gardener/docforge/blob/master/README.md
+- name: conditional.md
+  source: https://github.com/gardener/docforge/blob/master/README.md
+  when: '` + when + `'
+`)
+		doc, err := api.Parse(manifest, vars, false)
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, n := range doc.Structure {
+			names = append(names, n.Name)
+			Expect(n.When).To(BeEmpty())
+		}
+		if included {
+			Expect(names).To(Equal([]string{"always.md", "conditional.md"}))
+		} else {
+			Expect(names).To(Equal([]string{"always.md"}))
+		}
+	},
+		Entry("equal", "edition=enterprise", map[string]string{"edition": "enterprise"}, true),
+		Entry("equal with ==", `edition == "enterprise"`, map[string]string{"edition": "oss"}, false),
+		Entry("not equal", "edition!=oss", map[string]string{"edition": "enterprise"}, true),
+		Entry("undefined variable", "edition=enterprise", map[string]string{}, false),
+		Entry("version greater or equal", "version>=1.30", map[string]string{"version": "v1.31.2"}, true),
+		Entry("version compared semantically", "version>=1.30", map[string]string{"version": "1.4"}, false),
+		Entry("numbers", "level<10", map[string]string{"level": "9"}, true),
+		Entry("variable alone", "beta", map[string]string{"beta": "true"}, true),
+		Entry("false variable", "beta", map[string]string{"beta": "false"}, false),
+		Entry("negation", "!beta", map[string]string{}, true),
+		Entry("and", "edition=enterprise && version>=1.30", map[string]string{"edition": "enterprise", "version": "1.29"}, false),
+		Entry("or with parentheses", "(edition=enterprise || edition=pro) && !beta", map[string]string{"edition": "pro"}, true),
+	)
+
+	It("excludes node selectors and emptied containers", func() {
+		doc, err := api.Parse([]byte(`
+structure:
+- name: docs
+  nodesSelector:
+    path: https://github.com/gardener/docforge/tree/master/docs
+    when: edition=enterprise
+- name: guides
+  nodes:
+  - name: enterprise.md
+    source: https://github.com/gardener/docforge/blob/master/enterprise.md
+    when: edition=enterprise
+- name: api
+  nodes:
+  - name: reference.md
+    source: https://github.com/gardener/docforge/blob/master/reference.md
+nodesSelector:
+  path: https://github.com/gardener/docforge/blob/master/enterprise.yaml
+  when: edition=enterprise
+`), map[string]string{"edition": "oss"}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.NodeSelector).To(BeNil())
+		Expect(doc.Structure).To(HaveLen(1))
+		Expect(doc.Structure[0].Name).To(Equal("api"))
+	})
+
+	It("fails on invalid conditions", func() {
+		_, err := api.Parse([]byte(`
+structure:
+- name: a.md
+  source: https://github.com/gardener/docforge/blob/master/a.md
+  when: edition=
+`), map[string]string{}, false)
+		Expect(err).To(MatchError(`invalid condition "edition=": expected value after =`))
+	})
+
+	It("provides template functions", func() {
+		doc, err := api.Parse([]byte(`
+structure:
+- name: {{ .edition | Default "oss" | ToUpper }}.md
+  source: https://github.com/gardener/docforge/blob/{{ Replace .version "v" "release-" }}/README.md
+{{- if When "version>=1.30" }}
+- name: new.md
+  source: https://github.com/gardener/docforge/blob/master/new.md?a=1&b=2
+{{- end }}
+`), map[string]string{"version": "v1.30"}, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Structure).To(HaveLen(2))
+		Expect(doc.Structure[0].Name).To(Equal("OSS.md"))
+		Expect(doc.Structure[0].Source).To(Equal("https://github.com/gardener/docforge/blob/release-1.30/README.md"))
+		// text is not HTML escaped
+		Expect(doc.Structure[1].Source).To(Equal("https://github.com/gardener/docforge/blob/master/new.md?a=1&b=2"))
+	})
+})
@@ -6,10 +6,12 @@ package api
 
 import (
 	"fmt"
-	"gopkg.in/yaml.v3"
-	"k8s.io/klog/v2"
+	"reflect"
 	"sort"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -75,14 +77,36 @@ func (n *Node) Sources() string {
 		return n.Source
 	}
 	if len(n.MultiSource) > 0 {
-		return strings.Join(n.MultiSource, ",")
+		var sources []string
+		for _, ms := range n.MultiSource {
+			sources = append(sources, ms.Source)
+		}
+		return strings.Join(sources, ",")
 	}
 	return ""
 }
 
 // IsDocument returns true if the node is a document node
 func (n *Node) IsDocument() bool {
-	return len(n.MultiSource) > 0 || len(n.Source) > 0
+	return len(n.MultiSource) > 0 || len(n.Source) > 0 || len(n.Content) > 0
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, a scalar is decoded as the item Source
+func (m *MultiSourceItem) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Source)
+	}
+	type plain MultiSourceItem
+	return value.Decode((*plain)(m))
+}
+
+// MarshalYAML implements yaml.Marshaler, an item with a Source only is encoded as the Source
+func (m MultiSourceItem) MarshalYAML() (interface{}, error) {
+	if m == (MultiSourceItem{Source: m.Source}) {
+		return m.Source, nil
+	}
+	type plain MultiSourceItem
+	return plain(m), nil
 }
 
 // RelativePath returns the relative path between two nodes on the same tree or the forest under a Documentation.Structure,
@@ -175,8 +199,28 @@ func (n *Node) String() string {
 	return string(node)
 }
 
+// Precedence is the rule resolving the conflicting properties of container nodes merged by Node.Union
+type Precedence string
+
+const (
+	// ExplicitPrecedence keeps the conflicting properties of the node the other nodes are merged in,
+	// i.e. the properties explicitly defined in the manifest
+	ExplicitPrecedence Precedence = "explicit"
+	// SelectedPrecedence takes the conflicting properties of the merged nodes, e.g. the ones of the nodes
+	// resolved by node selectors
+	SelectedPrecedence Precedence = "selected"
+)
+
 // Union merges the Node`s list of nodes, with the provided list recursively.
+// The properties of identically named container nodes are merged with ExplicitPrecedence.
 func (n *Node) Union(nodes []*Node) error {
+	return n.UnionWithPrecedence(nodes, ExplicitPrecedence)
+}
+
+// UnionWithPrecedence merges the Node`s list of nodes, with the provided list recursively.
+// The properties of identically named container nodes are merged deeply: nested maps are merged,
+// the missing list items are appended, and conflicting values are resolved by the precedence rule.
+func (n *Node) UnionWithPrecedence(nodes []*Node, precedence Precedence) error {
 	// merge is relevant for container nodes only
 	if n.IsDocument() {
 		return fmt.Errorf("not a container node %s", n.FullName("/"))
@@ -202,9 +246,8 @@ func (n *Node) Union(nodes []*Node) error {
 			} else {
 				if !existingNode.IsDocument() {
 					// merge recursively
-					// note: node properties merge is not supported; the properties from first node <existingNode> are active,
-					// as it is expected that they are defined explicitly in the manifest
-					if err := existingNode.Union(node.Nodes); err != nil {
+					existingNode.Properties = mergeProperties(existingNode.FullName("/"), existingNode.Properties, node.Properties, precedence)
+					if err := existingNode.UnionWithPrecedence(node.Nodes, precedence); err != nil {
 						return err
 					}
 				} else {
@@ -220,6 +263,174 @@ func (n *Node) Union(nodes []*Node) error {
 	return nil
 }
 
+// mergeProperties merges the properties of other node into the node properties, resolving the conflicts by precedence
+func mergeProperties(nodeName string, props map[string]interface{}, other map[string]interface{}, precedence Precedence) map[string]interface{} {
+	if len(other) == 0 {
+		return props
+	}
+	if props == nil {
+		props = make(map[string]interface{}, len(other))
+	}
+	for k, v := range other {
+		if strings.HasPrefix(k, "\x00") {
+			// internal properties, e.g. the source location of the existing container
+			if _, ok := props[k]; !ok {
+				props[k] = v
+			}
+			continue
+		}
+		props[k] = mergeValue(nodeName, k, props[k], v, precedence)
+	}
+	return props
+}
+
+// mergeValue merges value b into value a at properties path
+func mergeValue(nodeName string, path string, a interface{}, b interface{}, precedence Precedence) interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if am, ok := toStringMap(a); ok {
+		if bm, ok := toStringMap(b); ok {
+			for k, v := range bm {
+				am[k] = mergeValue(nodeName, path+"."+k, am[k], v, precedence)
+			}
+			return am
+		}
+	}
+	if al, ok := a.([]interface{}); ok {
+		if bl, ok := b.([]interface{}); ok {
+			for _, bv := range bl {
+				if !containsValue(al, bv) {
+					al = append(al, bv)
+				}
+			}
+			return al
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return a
+	}
+	v := a
+	if precedence == SelectedPrecedence {
+		v = b
+	}
+	klog.Warningf("Properties conflict on node %s: %s is %v in the explicitly defined node and %v in the selected one. Taking %v", nodeName, path, a, b, v)
+	return v
+}
+
+// toStringMap returns the YAML map with string keys
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		sm := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			sm[fmt.Sprint(k)] = val
+		}
+		return sm, true
+	}
+	return nil, false
+}
+
+func containsValue(l []interface{}, v interface{}) bool {
+	for _, lv := range l {
+		if reflect.DeepEqual(lv, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyOverlays patches the node hierarchy under container node n with the overlays, in their order
+func (n *Node) ApplyOverlays(overlays []*Overlay) error {
+	for _, o := range overlays {
+		target := n
+		if o.Path != "." {
+			if target = n.child(strings.Split(strings.Trim(o.Path, "/"), "/")); target == nil {
+				return fmt.Errorf("overlay path %s doesn't match any node", o.Path)
+			}
+		}
+		if err := target.applyOverlay(o); err != nil {
+			return fmt.Errorf("overlay path %s: %v", o.Path, err)
+		}
+	}
+	return nil
+}
+
+// child returns the descendant node found by names path
+func (n *Node) child(names []string) *Node {
+	for _, c := range n.Nodes {
+		if c.Name == names[0] {
+			if len(names) == 1 {
+				return c
+			}
+			return c.child(names[1:])
+		}
+	}
+	return nil
+}
+
+func (n *Node) applyOverlay(o *Overlay) error {
+	if o.Remove {
+		if n.parent == nil {
+			return fmt.Errorf("the selected structure root can't be removed")
+		}
+		var nodes []*Node
+		for _, pn := range n.parent.Nodes {
+			if pn != n {
+				nodes = append(nodes, pn)
+			}
+		}
+		n.parent.Nodes = nodes
+		return nil
+	}
+	if o.Rename != "" {
+		if n.parent == nil {
+			return fmt.Errorf("the selected structure root can't be renamed")
+		}
+		if n.parent.child([]string{o.Rename}) != nil {
+			return fmt.Errorf("node %s already exists", o.Rename)
+		}
+		n.Name = o.Rename
+	}
+	if len(o.Order) > 0 {
+		if n.IsDocument() {
+			return fmt.Errorf("not a container node %s", n.FullName("/"))
+		}
+		nodes := make([]*Node, 0, len(n.Nodes))
+		ordered := make(map[*Node]bool, len(o.Order))
+		for _, name := range o.Order {
+			c := n.child([]string{name})
+			if c == nil {
+				return fmt.Errorf("ordered node %s doesn't exist", name)
+			}
+			nodes = append(nodes, c)
+			ordered[c] = true
+		}
+		for _, c := range n.Nodes {
+			if !ordered[c] {
+				nodes = append(nodes, c)
+			}
+		}
+		n.Nodes = nodes
+	}
+	for k, v := range o.Properties {
+		if v == nil {
+			delete(n.Properties, k)
+			continue
+		}
+		if n.Properties == nil {
+			n.Properties = make(map[string]interface{})
+		}
+		n.Properties[k] = v
+	}
+	return nil
+}
+
 // Cleanup removes empty nodes that do not contain markdowns
 func (n *Node) Cleanup() {
 	var children []*Node
@@ -86,7 +86,7 @@ var _ = Describe("API Nodes", func() {
 			})
 			When("get multi source", func() {
 				BeforeEach(func() {
-					child.MultiSource = []string{"https://test/part1.md", "https://test/part2.md"}
+					child.MultiSource = []api.MultiSourceItem{{Source: "https://test/part1.md"}, {Source: "https://test/part2.md"}}
 				})
 				It("returns multi source locations", func() {
 					Expect(res).To(Equal("https://test/part1.md,https://test/part2.md"))
@@ -139,6 +139,115 @@ var _ = Describe("API Nodes", func() {
 			Entry("path from A to A1", "A", "A1", "./A1"),
 		)
 	})
+	Context("UnionWithPrecedence", func() {
+		var (
+			node       *api.Node
+			nodes      []*api.Node
+			precedence api.Precedence
+			err        error
+		)
+		BeforeEach(func() {
+			node = &api.Node{Name: "docs", Nodes: []*api.Node{{Name: "sub", Properties: map[string]interface{}{
+				"frontmatter": map[string]interface{}{"title": "Sub", "tags": []interface{}{"a"}, "menu": map[string]interface{}{"weight": 1}},
+			}}}}
+			nodes = []*api.Node{{Name: "sub", Properties: map[string]interface{}{
+				"frontmatter": map[string]interface{}{"title": "Generated", "tags": []interface{}{"a", "b"}, "menu": map[string]interface{}{"parent": "docs"}, "description": "Sub docs"},
+				"index":       true,
+			}, Nodes: []*api.Node{{Name: "a.md", Source: "https://test/a.md"}}}}
+			node.SetParentsDownwards()
+		})
+		JustBeforeEach(func() {
+			err = node.UnionWithPrecedence(nodes, precedence)
+		})
+		When("explicit precedence", func() {
+			BeforeEach(func() {
+				precedence = api.ExplicitPrecedence
+			})
+			It("merges the properties deeply keeping the explicit values", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.Nodes[0].Properties).To(Equal(map[string]interface{}{
+					"frontmatter": map[string]interface{}{"title": "Sub", "tags": []interface{}{"a", "b"}, "menu": map[string]interface{}{"weight": 1, "parent": "docs"}, "description": "Sub docs"},
+					"index":       true,
+				}))
+				Expect(node.Nodes[0].Nodes).To(HaveLen(1))
+			})
+		})
+		When("selected precedence", func() {
+			BeforeEach(func() {
+				precedence = api.SelectedPrecedence
+			})
+			It("merges the properties deeply taking the selected values", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.Nodes[0].Properties).To(Equal(map[string]interface{}{
+					"frontmatter": map[string]interface{}{"title": "Generated", "tags": []interface{}{"a", "b"}, "menu": map[string]interface{}{"weight": 1, "parent": "docs"}, "description": "Sub docs"},
+					"index":       true,
+				}))
+			})
+		})
+	})
+	Context("ApplyOverlays", func() {
+		var (
+			node     *api.Node
+			overlays []*api.Overlay
+			err      error
+		)
+		BeforeEach(func() {
+			node = &api.Node{Nodes: []*api.Node{
+				{Name: "a.md", Source: "https://test/a.md", Properties: map[string]interface{}{"weight": 1, "draft": true}},
+				{Name: "sub", Nodes: []*api.Node{{Name: "b.md", Source: "https://test/b.md"}, {Name: "c.md", Source: "https://test/c.md"}}},
+				{Name: "d.md", Source: "https://test/d.md"},
+			}}
+			node.SetParentsDownwards()
+		})
+		JustBeforeEach(func() {
+			err = node.ApplyOverlays(overlays)
+		})
+		When("overlays match nodes", func() {
+			BeforeEach(func() {
+				overlays = []*api.Overlay{
+					{Path: "a.md", Properties: map[string]interface{}{"weight": 2, "draft": nil}},
+					{Path: "sub/c.md", Remove: true},
+					{Path: "sub", Rename: "guides"},
+					{Path: "guides/b.md", Rename: "install.md"},
+					{Path: ".", Order: []string{"d.md", "guides"}},
+				}
+			})
+			It("patches the nodes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				exp := &api.Node{Nodes: []*api.Node{
+					{Name: "d.md", Source: "https://test/d.md"},
+					{Name: "guides", Nodes: []*api.Node{{Name: "install.md", Source: "https://test/b.md"}}},
+					{Name: "a.md", Source: "https://test/a.md", Properties: map[string]interface{}{"weight": 2}},
+				}}
+				exp.SetParentsDownwards()
+				Expect(node).To(Equal(exp))
+			})
+		})
+		When("overlay path doesn't match", func() {
+			BeforeEach(func() {
+				overlays = []*api.Overlay{{Path: "sub/missing.md", Remove: true}}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("overlay path sub/missing.md doesn't match any node"))
+			})
+		})
+		When("renamed node collides", func() {
+			BeforeEach(func() {
+				overlays = []*api.Overlay{{Path: "a.md", Rename: "d.md"}}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("overlay path a.md: node d.md already exists"))
+			})
+		})
+		When("ordered node doesn't exist", func() {
+			BeforeEach(func() {
+				overlays = []*api.Overlay{{Path: "sub", Order: []string{"x.md"}}}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("overlay path sub: ordered node x.md doesn't exist"))
+			})
+		})
+	})
 	Context("Union", func() {
 		var (
 			node  *api.Node
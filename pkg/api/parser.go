@@ -8,23 +8,36 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"html/template"
+	"net/url"
+	"path"
 	"strings"
+	"text/template"
 
-	"github.com/gardener/docforge/pkg/util"
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"gopkg.in/yaml.v3"
 )
 
 // ParseWithMetadata parses a document's byte content given some other metainformation
+// The `versions` variable defaults to the targetBranch, unless the versions are provided by flagsVars
 func ParseWithMetadata(b []byte, targetBranch string, flagsVars map[string]string, hugoEnabled bool) (*Documentation, error) {
-	versionList := make([]string, 0)
-	versionList = append(versionList, targetBranch)
+	return Parse(b, metadataVars(targetBranch, flagsVars), hugoEnabled)
+}
+
+// metadataVars returns the manifest variables for a manifest from targetBranch
+func metadataVars(targetBranch string, flagsVars map[string]string) map[string]string {
+	// flagsVars are shared by the resource handlers, don't change them
+	vars := make(map[string]string, len(flagsVars)+1)
+	for k, v := range flagsVars {
+		vars[k] = v
+	}
+	if _, ok := vars["versions"]; !ok {
+		versionList := make([]string, 0)
+		versionList = append(versionList, targetBranch)
 
-	versions := strings.Join(versionList, ",")
-	flagsVars["versions"] = versions
-	return Parse(b, flagsVars, hugoEnabled)
+		vars["versions"] = strings.Join(versionList, ",")
+	}
+	return vars
 }
 
 // Parse is a function which construct documentation struct from given byte array
@@ -37,6 +50,9 @@ func Parse(b []byte, flagsVars map[string]string, hugoEnabled bool) (*Documentat
 	if err = yaml.Unmarshal(blob, docs); err != nil {
 		return nil, err
 	}
+	if err = resolveConditions(docs, flagsVars); err != nil {
+		return nil, err
+	}
 	// init parents
 	for _, n := range docs.Structure {
 		n.SetParentsDownwards()
@@ -82,12 +98,18 @@ func validateNode(n *Node) error {
 	if n.IsDocument() && n.Source == "" && n.Name == "" { // TODO: Apply this check on container nodes as well, once all manifests are fixed
 		errs = multierror.Append(errs, fmt.Errorf("node %s must contains at least one of these properties: source, name", n.FullName("/")))
 	}
-	if n.Source == "" && n.NodeSelector == nil && n.MultiSource == nil && n.Nodes == nil {
-		errs = multierror.Append(errs, fmt.Errorf("node %s must contains at least one of these properties: source, nodesSelector, multiSource, nodes", n.FullName("/")))
+	if n.Source == "" && n.NodeSelector == nil && n.MultiSource == nil && n.Content == "" && n.Nodes == nil {
+		errs = multierror.Append(errs, fmt.Errorf("node %s must contains at least one of these properties: source, nodesSelector, multiSource, content, nodes", n.FullName("/")))
 	}
-	if len(n.Sources()) > 0 && (n.Nodes != nil || n.NodeSelector != nil) {
+	if n.IsDocument() && (n.Nodes != nil || n.NodeSelector != nil) {
 		errs = multierror.Append(errs, fmt.Errorf("node %s must be categorized as a document or a container, please specify only one of the following groups of properties: %s",
-			n.FullName("/"), "(source/multiSource),(nodes,nodesSelector)"))
+			n.FullName("/"), "(source/multiSource/content),(nodes,nodesSelector)"))
+	}
+	if n.Content != "" && len(n.Sources()) > 0 {
+		errs = multierror.Append(errs, fmt.Errorf("node %s has a content and source or multiSource property defined at the same time", n.FullName("/")))
+	}
+	if n.GenerateIndex && n.IsDocument() {
+		errs = multierror.Append(errs, fmt.Errorf("node %s is a document node, generateIndex is applicable to container nodes only", n.FullName("/")))
 	}
 	if n.NodeSelector != nil {
 		if err := validateNodeSelector(n.NodeSelector, n.FullName("/")); err != nil {
@@ -105,7 +127,7 @@ func validateNode(n *Node) error {
 		}
 	}
 	for i, ms := range n.MultiSource {
-		if ms == "" {
+		if ms.Source == "" {
 			errs = multierror.Append(errs, fmt.Errorf("node %s contains empty multiSource value at position %d", n.FullName("/"), i))
 		}
 	}
@@ -180,8 +202,26 @@ func resolveVariables(manifestContent []byte, vars map[string]string) ([]byte, e
 	)
 	tplFuncMap := make(template.FuncMap)
 	tplFuncMap["Split"] = strings.Split
+	tplFuncMap["Join"] = func(sep string, elems []string) string { return strings.Join(elems, sep) }
 	tplFuncMap["Add"] = func(a, b int) int { return a + b }
-	if tmpl, err = template.New("").Funcs(tplFuncMap).Parse(string(manifestContent)); err != nil {
+	tplFuncMap["Sub"] = func(a, b int) int { return a - b }
+	tplFuncMap["Contains"] = strings.Contains
+	tplFuncMap["HasPrefix"] = strings.HasPrefix
+	tplFuncMap["HasSuffix"] = strings.HasSuffix
+	tplFuncMap["TrimPrefix"] = strings.TrimPrefix
+	tplFuncMap["TrimSuffix"] = strings.TrimSuffix
+	tplFuncMap["Replace"] = strings.ReplaceAll
+	tplFuncMap["ToLower"] = strings.ToLower
+	tplFuncMap["ToUpper"] = strings.ToUpper
+	tplFuncMap["Default"] = func(def string, val string) string {
+		if val == "" {
+			return def
+		}
+		return val
+	}
+	tplFuncMap["When"] = func(condition string) (bool, error) { return evalCondition(condition, vars) }
+	// undefined variables are resolved to empty strings
+	if tmpl, err = template.New("").Option("missingkey=zero").Funcs(tplFuncMap).Parse(string(manifestContent)); err != nil {
 		return nil, err
 	}
 	if err = tmpl.Execute(&b, vars); err != nil {
@@ -289,12 +329,12 @@ func getNodeName(node *Node, hugoEnabled bool) (string, error) {
 		}
 		// name != "" and evaluate name expression
 		if strings.IndexByte(name, '$') != -1 {
-			info, err := util.BuildResourceInfo(node.Source)
+			u, err := url.Parse(node.Source)
 			if err != nil {
 				return "", err
 			}
-			ext := info.GetResourceExt()
-			resourceName := strings.TrimSuffix(info.GetResourceName(), ext)
+			ext := path.Ext(u.Path)
+			resourceName := strings.TrimSuffix(path.Base(u.Path), ext)
 			name = strings.ReplaceAll(name, "$name", resourceName)
 			name = strings.ReplaceAll(name, "$uuid", uuid.New().String())
 			name = strings.ReplaceAll(name, "$ext", ext)
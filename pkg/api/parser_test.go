@@ -43,7 +43,7 @@ var _ = Describe("Parser", func() {
                   nodes:
                   - name: sub
                     properties: {index: true}`), nil,
-				fmt.Errorf("node test/sub must contains at least one of these properties: source, nodesSelector, multiSource, nodes")),
+				fmt.Errorf("node test/sub must contains at least one of these properties: source, nodesSelector, multiSource, content, nodes")),
 			Entry("node document mandatory properties", []byte(`
                 structure:
                 - name: test
@@ -58,6 +58,18 @@ var _ = Describe("Parser", func() {
                   - name: test2
                     source: https://github.com/gardener/docforge/blob/master/README.md`), nil,
 				fmt.Errorf("node /test must be categorized as a document or a container")),
+			Entry("node content is an alternative to source", []byte(`
+                structure:
+                - name: test.md
+                  source: https://github.com/gardener/docforge/blob/master/README.md
+                  content: "# Test"`), nil,
+				fmt.Errorf("node /test.md has a content and source or multiSource property defined at the same time")),
+			Entry("generate index is applicable to containers", []byte(`
+                structure:
+                - name: test.md
+                  content: "# Test"
+                  generateIndex: true`), nil,
+				fmt.Errorf("node /test.md is a document node, generateIndex is applicable to container nodes only")),
 			Entry("structure nodeSelector path is mandatory", []byte(`
                 structure:
                 - nodesSelector:
@@ -121,18 +133,47 @@ var _ = Describe("Parser", func() {
 						},
 					},
 				}, nil),
+			Entry("multiSource items", []byte(`
+                structure:
+                - name: overview.md
+                  multiSource:
+                  - https://github.com/gardener/docforge/blob/master/README.md
+                  - source: https://github.com/gardener/docforge/blob/master/docs/usage.md
+                    headingShift: 1
+                    dropFirstHeading: true
+                    heading: Usage
+                    separator: true`),
+				&api.Documentation{
+					Structure: []*api.Node{
+						{
+							Name: "overview.md",
+							MultiSource: []api.MultiSourceItem{
+								{Source: "https://github.com/gardener/docforge/blob/master/README.md"},
+								{
+									Source:           "https://github.com/gardener/docforge/blob/master/docs/usage.md",
+									HeadingShift:     1,
+									DropFirstHeading: true,
+									Heading:          "Usage",
+									Separator:        true,
+								},
+							},
+						},
+					},
+				}, nil),
 		)
 	})
 	Describe("Parsing with metadata", func() {
 		var (
 			manifest     []byte
 			targetBranch string
+			vars         map[string]string
 			got          *api.Documentation
 			err          error
 		)
+		BeforeEach(func() {
+			vars = map[string]string{}
+		})
 		JustBeforeEach(func() {
-			vars := map[string]string{}
-
 			got, err = api.ParseWithMetadata(manifest, targetBranch, vars, true)
 		})
 		Context("given a general use case", func() {
@@ -160,6 +201,34 @@ var _ = Describe("Parser", func() {
 						},
 					},
 				}))
+				Expect(vars).To(BeEmpty())
+			})
+		})
+		Context("given versions variable", func() {
+			BeforeEach(func() {
+				manifest = []byte(`structure:
+{{- range $version := Split .versions "," }}
+- name: {{ $version }}
+  source: https://github.com/gardener/docforge/blob/{{ $version }}/README.md
+{{- end }}
+`)
+				targetBranch = "master"
+				vars["versions"] = "v1.1,v1.0"
+			})
+			It("uses the provided versions", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(got).To(Equal(&api.Documentation{
+					Structure: []*api.Node{
+						{
+							Name:   "v1.1",
+							Source: "https://github.com/gardener/docforge/blob/v1.1/README.md",
+						},
+						{
+							Name:   "v1.0",
+							Source: "https://github.com/gardener/docforge/blob/v1.0/README.md",
+						},
+					},
+				}))
 			})
 		})
 
@@ -187,7 +256,7 @@ var _ = Describe("Parser", func() {
 								},
 								{
 									Name:        "node 2",
-									MultiSource: []string{"https://multitest/a.md", "https://multitest/b.md"},
+									MultiSource: []api.MultiSourceItem{{Source: "https://multitest/a.md"}, {Source: "https://multitest/b.md"}},
 									Properties: map[string]interface{}{
 										"custom_key": "custom_value",
 									},
@@ -253,8 +322,8 @@ var _ = Describe("Parser", func() {
 								},
 								{
 									Name: "02",
-									MultiSource: []string{
-										"https://github.com/gardener/gardener/blob/master/docs/deployment/deploy_gardenlet.md",
+									MultiSource: []api.MultiSourceItem{
+										{Source: "https://github.com/gardener/gardener/blob/master/docs/deployment/deploy_gardenlet.md"},
 									},
 								},
 							},
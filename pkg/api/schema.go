@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the published location of the manifest JSON Schema
+const SchemaID = "https://raw.githubusercontent.com/gardener/docforge/master/docs/manifest.schema.json"
+
+// anyOfRequired lists the alternative properties of the model types, at least one of them must be set
+var anyOfRequired = map[reflect.Type][]string{
+	reflect.TypeOf(Documentation{}): {"structure", "nodesSelector"},
+	reflect.TypeOf(Node{}):          {"source", "multiSource", "content", "nodes", "nodesSelector"},
+}
+
+// scalarShorthands lists the model types that can be defined by a string, which sets the given property
+var scalarShorthands = map[reflect.Type]string{
+	reflect.TypeOf(MultiSourceItem{}): "source",
+}
+
+// manifestField is a manifest property modelled by a struct field
+type manifestField struct {
+	name     string
+	required bool
+	typ      reflect.Type
+}
+
+// manifestFields returns the manifest properties of a model type, as declared by the yaml tags of its fields
+func manifestFields(t reflect.Type) []manifestField {
+	var fields []manifestField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		mf := manifestField{name: tag[0], required: true, typ: f.Type}
+		if mf.name == "" {
+			mf.name = strings.ToLower(f.Name)
+		}
+		for _, o := range tag[1:] {
+			if o == "omitempty" {
+				mf.required = false
+			}
+		}
+		fields = append(fields, mf)
+	}
+	return fields
+}
+
+// JSONSchema returns the JSON Schema of the documentation manifest, generated from the Documentation,
+// Node and NodeSelector models. Editors use it to validate and complete manifests.
+func JSONSchema() ([]byte, error) {
+	defs := map[string]interface{}{}
+	schema := typeSchema(reflect.TypeOf(Documentation{}), defs)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "Docforge documentation manifest"
+	schema["$defs"] = defs
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// typeSchema returns the schema of a struct type. The schemas of the nested struct types are
+// added to defs and referenced, so that recursive types like Node are supported.
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	for _, f := range manifestFields(t) {
+		props[f.name] = valueSchema(f.typ, defs)
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if alts, ok := anyOfRequired[t]; ok {
+		var anyOf []interface{}
+		for _, a := range alts {
+			anyOf = append(anyOf, map[string]interface{}{"required": []string{a}})
+		}
+		s["anyOf"] = anyOf
+	}
+	return s
+}
+
+// valueSchema returns the schema of a property type
+func valueSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return valueSchema(t.Elem(), defs)
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // break the recursion
+			defs[t.Name()] = typeSchema(t, defs)
+		}
+		ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := scalarShorthands[t]; ok {
+			return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}, ref}}
+		}
+		return ref
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": valueSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
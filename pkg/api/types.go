@@ -27,8 +27,8 @@ type Documentation struct {
 // recursive. They can contain other nodes in their `Nodes` property, which in turn
 // can contain other nodes and form a tree hierarchy in this way. On a file system
 // it is serialized as directory.
-// A Node that defines either of the content assignment properties -  `Source` or
-// `MultiSource` is a *document node*. The twо properties are alternatives.
+// A Node that defines either of the content assignment properties -  `Source`,
+// `MultiSource` or `Content` is a *document node*. The three properties are alternatives.
 // Only one can be used in a node. On a file system a document node
 // is serialized as file.
 type Node struct {
@@ -50,6 +50,10 @@ type Node struct {
 	// Optional if Source is specified, Mandatory otherwise
 	Name string `yaml:"name,omitempty"`
 	// Source declares a content assignment to this node from a single location.
+	// A URL fragment selects a section by its heading anchor, e.g. `README.md#installation`,
+	// or a line range, e.g. `README.md#L10-L20`, of the source document.
+	// Source code files, e.g. `main.go`, are embedded as fenced code blocks. Their fragment selects
+	// a line range or the lines between `docforge:start <name>` and `docforge:end <name>` marker comments.
 	//
 	// Mandatory if this is a document node and MultiSource is not specified.
 	// Applicable to document nodes only.
@@ -57,11 +61,22 @@ type Node struct {
 	// MultiSource is a sequence of contents for this document node from different locations.
 	//
 	// The content provided by the list of MultiSource is aggregated into a single
-	// document in the order in which they are declared.
+	// document in the order in which they are declared. The items are either source
+	// locations, or MultiSourceItem objects that adapt the headings of the contents
+	// to the aggregated document.
 	// Mandatory if this is a document node and Source is not specified.
 	// Applicable to document nodes only.
 	// Alternative to Source.
-	MultiSource []string `yaml:"multiSource,omitempty"`
+	MultiSource []MultiSourceItem `yaml:"multiSource,omitempty"`
+	// Content is an inline markdown content of this document node, e.g. a landing
+	// page or a section introduction that doesn't exist in any repository.
+	// Relative links in the content refer to the nodes in the documentation
+	// structure, e.g. `./install.md` links the install.md peer node.
+	//
+	// Mandatory if this is a document node and neither Source nor MultiSource is specified.
+	// Applicable to document nodes only.
+	// Alternative to Source and MultiSource.
+	Content string `yaml:"content,omitempty"`
 	// Nodes is a list of nodes that are descendants of this Node in the
 	// documentation structure.
 	// Applicable to container nodes only.
@@ -72,12 +87,21 @@ type Node struct {
 	// potentially explicitly defined descendants there. The merge strategy
 	// identifies identical nodes by their name and when there is a match, it performs
 	// a deep merge of their properties. When there are merger conflicts, the
-	// explicitly defined node wins.
+	// explicitly defined node wins, unless `--properties-precedence=selected` is set.
 	// Depending on the goal, a NodeSelector can coexist, or be an alternative to an
 	// explicitly defined structure.
 	//
 	// Applicable to container nodes only.
 	NodeSelector *NodeSelector `yaml:"nodesSelector,omitempty"`
+	// GenerateIndex generates the `_index.md` section file of this container node,
+	// if it has none. The generated page lists the titles of the child nodes with links
+	// to them, computed from the resolved structure. The titles are taken from the
+	// `title` front matter property of the nodes or derived from their names.
+	// Child container nodes without a section file are not listed.
+	//
+	// Optional
+	// Applicable to container nodes only.
+	GenerateIndex bool `yaml:"generateIndex,omitempty"`
 	// Properties are a map of arbitrary, key-value pairs to model custom, untyped
 	// node properties. The requirements and constraints on the properties depends
 	// on the feature that makes use of them.
@@ -88,11 +112,47 @@ type Node struct {
 	//
 	// Optional
 	Properties map[string]interface{} `yaml:"properties,omitempty"`
+	// When is a condition on the manifest variables, e.g. `edition=enterprise` or
+	// `version>=1.30 && edition!=oss`. The node is included in the structure only
+	// if the condition is true. Values are compared as semantic versions, numbers
+	// or strings. A variable alone is true if it is set to a value other than
+	// empty, `false` or `0`. Conditions are combined with `&&`, `||`, `!` and
+	// parentheses.
+	//
+	// Optional
+	When string `yaml:"when,omitempty"`
 
 	// private fields
 	parent *Node
 }
 
+// MultiSourceItem is a content of a document node aggregated from several sources.
+// An item with a Source only is serialized as its Source string.
+type MultiSourceItem struct {
+	// Source is the location of the content, see Node.Source.
+	//
+	// Mandatory
+	Source string `yaml:"source"`
+	// HeadingShift shifts the levels of the content headings, e.g. `1` turns the H1
+	// headings into H2 headings. The levels stay between 1 and 6.
+	//
+	// Optional
+	HeadingShift int `yaml:"headingShift,omitempty"`
+	// DropFirstHeading removes the first heading of the content, e.g. its title.
+	//
+	// Optional
+	DropFirstHeading bool `yaml:"dropFirstHeading,omitempty"`
+	// Heading is the text of a heading inserted before the content. Its level
+	// is the level of a shifted H1 heading, i.e. 1 + HeadingShift.
+	//
+	// Optional
+	Heading string `yaml:"heading,omitempty"`
+	// Separator inserts a thematic break (`---`) before the content.
+	//
+	// Optional
+	Separator bool `yaml:"separator,omitempty"`
+}
+
 // NodeSelector is a specification for selecting nodes from a location that is
 // resolved at runtime dynamically.
 type NodeSelector struct {
@@ -151,8 +211,14 @@ type NodeSelector struct {
 	// Element values can be scalar, object maps or arrays.
 	// An element in an array is referenced by its index: `.a.b[1]` references `b`
 	//   array element with index 1.
-	// Paths can include up to one wildcard `**` symbol that models *any* path node.
+	// Paths can include wildcards: `*` models any object element, `[*]` any array
+	// element and `**` any sequence of path nodes.
 	// A `.a.**.c` models any path starting with	`.a.` and ending with `.c`.
+
+	// A rule value can be a map of operators evaluated on the matching elements:
+	// `$eq`, `$ne`, `$in`, `$nin`, `$contains`, `$regex`, `$exists`, `$lt`, `$lte`,
+	// `$gt`, `$gte` and `$not`, e.g. `.tags: {$contains: install}` or
+	// `.lastmod: {$gt: 2022-01-01}`. All operators in a map must match.
 	//
 	// Optional
 	ExcludeFrontMatter map[string]interface{} `yaml:"excludeFrontMatter,omitempty"`
@@ -180,9 +246,55 @@ type NodeSelector struct {
 	// Element values can be scalar, object maps or arrays.
 	// An element in an array is referenced by its index: `.a.b[1]` references `b`
 	// array element with index 1.
-	// Paths can include up to one wildcard `**` symbol that models *any* path node.
+	// Paths can include wildcards: `*` models any object element, `[*]` any array
+	// element and `**` any sequence of path nodes.
 	// A `.a.**.c` models any path starting with	`.a.` and ending with `.c`.
+
+	// A rule value can be a map of operators evaluated on the matching elements:
+	// `$eq`, `$ne`, `$in`, `$nin`, `$contains`, `$regex`, `$exists`, `$lt`, `$lte`,
+	// `$gt`, `$gte` and `$not`, e.g. `.tags: {$contains: install}` or
+	// `.lastmod: {$gt: 2022-01-01}`. All operators in a map must match.
 	//
 	// Optional
 	FrontMatter map[string]interface{} `yaml:"frontMatter,omitempty"`
+	// Overlays patch the selected node structure, e.g. the structure of an imported
+	// manifest, without changing its source. They are applied in the order of
+	// declaration, after the structure is resolved.
+	//
+	// Optional
+	Overlays []*Overlay `yaml:"overlays,omitempty"`
+	// When is a condition on the manifest variables. The node selector is applied
+	// only if the condition is true. See Node.When for the condition syntax.
+	//
+	// Optional
+	When string `yaml:"when,omitempty"`
+}
+
+// Overlay patches a node in the structure selected by a NodeSelector
+type Overlay struct {
+	// Path selects the patched node by the names of the nodes leading to it in the
+	// selected structure, separated by `/`, e.g. `guides/install.md`. The names
+	// of document nodes include the `.md` extension. The `.` path selects the root
+	// of the selected structure.
+	//
+	// Mandatory
+	Path string `yaml:"path"`
+	// Rename is the new name of the node.
+	//
+	// Optional
+	Rename string `yaml:"rename,omitempty"`
+	// Remove removes the node from the structure.
+	//
+	// Optional
+	Remove bool `yaml:"remove,omitempty"`
+	// Order lists the names of the child nodes of a container node in the desired
+	// order. The child nodes not listed follow in their original order.
+	//
+	// Optional
+	Order []string `yaml:"order,omitempty"`
+	// Properties are added to the node properties. Existing properties with the
+	// same keys are replaced, and properties with null values are removed.
+	//
+	// Optional
+	Properties map[string]interface{} `yaml:"properties,omitempty"`
 }
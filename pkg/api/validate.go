@@ -0,0 +1,328 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a manifest problem located by its YAML position
+type ValidationError struct {
+	// Line is the line of the problem, starting from 1
+	Line int
+	// Column is the column of the problem, starting from 1
+	Column int
+	// Message describes the problem
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+var (
+	templateErrorRegexp = regexp.MustCompile(`^template: [^:]*:(\d+):(?:(\d+):)? ?(.*)$`)
+	yamlErrorRegexp     = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+)
+
+// Validate checks a manifest against the Documentation model. Unlike ParseWithMetadata, it doesn't stop
+// at the first problem, but reports all of them with the YAML positions in the manifest with resolved variables.
+// Imported manifests are not validated.
+func Validate(b []byte, targetBranch string, flagsVars map[string]string, hugoEnabled bool) []*ValidationError {
+	vars := metadataVars(targetBranch, flagsVars)
+	blob, err := resolveVariables(b, vars)
+	if err != nil {
+		return []*ValidationError{positionedError(err, templateErrorRegexp)}
+	}
+	var root yaml.Node
+	if err = yaml.Unmarshal(blob, &root); err != nil {
+		return []*ValidationError{positionedError(err, yamlErrorRegexp)}
+	}
+	v := &validator{hugoEnabled: hugoEnabled, vars: vars}
+	if len(root.Content) == 0 {
+		v.add(&yaml.Node{Line: 1, Column: 1}, "the manifest is empty")
+		return v.errs
+	}
+	v.object(root.Content[0], reflect.TypeOf(Documentation{}), "")
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].Line != v.errs[j].Line {
+			return v.errs[i].Line < v.errs[j].Line
+		}
+		return v.errs[i].Column < v.errs[j].Column
+	})
+	return v.errs
+}
+
+// positionedError converts template or YAML parsing errors to ValidationError
+func positionedError(err error, re *regexp.Regexp) *ValidationError {
+	m := re.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &ValidationError{Line: 1, Column: 1, Message: err.Error()}
+	}
+	e := &ValidationError{Column: 1, Message: m[len(m)-1]}
+	e.Line, _ = strconv.Atoi(m[1])
+	if len(m) == 4 && m[2] != "" {
+		e.Column, _ = strconv.Atoi(m[2])
+	}
+	return e
+}
+
+// validator collects the problems found in the YAML nodes of a manifest
+type validator struct {
+	hugoEnabled bool
+	vars        map[string]string
+	errs        []*ValidationError
+}
+
+func (v *validator) add(n *yaml.Node, format string, a ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, a...)})
+}
+
+// object validates a mapping modelled by the struct type t and returns its non-null properties.
+// The path of the mapping in the manifest (e.g. structure[0].nodesSelector) is empty for the manifest itself.
+func (v *validator) object(n *yaml.Node, t reflect.Type, path string) map[string]*yaml.Node {
+	what := path
+	if what == "" {
+		what = "manifest"
+	}
+	n = resolveAlias(n)
+	if n.Kind != yaml.MappingNode {
+		v.add(n, "%s must be an object", what)
+		return nil
+	}
+	fields := map[string]manifestField{}
+	for _, f := range manifestFields(t) {
+		fields[f.name] = f
+	}
+	props := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, val := n.Content[i], resolveAlias(n.Content[i+1])
+		f, ok := fields[k.Value]
+		if !ok {
+			v.add(k, "unknown property %s of %s", k.Value, what)
+			continue
+		}
+		if _, ok = props[k.Value]; ok {
+			v.add(k, "property %s of %s is already defined", k.Value, what)
+			continue
+		}
+		if isNull(val) {
+			continue
+		}
+		props[k.Value] = val
+		p := k.Value
+		if path != "" {
+			p = path + "." + k.Value
+		}
+		v.value(val, f.typ, p)
+	}
+	for _, f := range fields {
+		if f.required && props[f.name] == nil {
+			v.add(n, "%s must contain a %s property", what, f.name)
+		}
+	}
+	switch t {
+	case reflect.TypeOf(Documentation{}):
+		v.documentation(n, props)
+	case reflect.TypeOf(Node{}):
+		v.node(n, props, what)
+	case reflect.TypeOf(NodeSelector{}):
+		v.nodeSelector(props, what)
+	}
+	if w := props["when"]; w != nil {
+		if _, err := evalCondition(w.Value, v.vars); err != nil {
+			v.add(w, "%s: %v", what, err)
+		}
+	}
+	return props
+}
+
+// value validates a property value of type t
+func (v *validator) value(n *yaml.Node, t reflect.Type, what string) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		v.value(n, t.Elem(), what)
+	case reflect.Struct:
+		if _, ok := scalarShorthands[t]; ok && n.Kind == yaml.ScalarNode {
+			return
+		}
+		v.object(n, t, what)
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			v.add(n, "%s must be an array", what)
+			return
+		}
+		for i, item := range n.Content {
+			item = resolveAlias(item)
+			if isNull(item) {
+				v.add(item, "%s contains empty value at position %d", what, i)
+				continue
+			}
+			v.value(item, t.Elem(), fmt.Sprintf("%s[%d]", what, i))
+		}
+		if t.Elem() == reflect.TypeOf(&Node{}) {
+			v.peers(n.Content)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			v.add(n, "%s must be an object", what)
+		}
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			v.add(n, "%s must be a string", what)
+		}
+	case reflect.Bool:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" {
+			v.add(n, "%s must be a boolean", what)
+		}
+	case reflect.Int, reflect.Int32:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+			v.add(n, "%s must be an integer", what)
+		}
+	}
+}
+
+func (v *validator) documentation(n *yaml.Node, props map[string]*yaml.Node) {
+	if props["structure"] == nil && props["nodesSelector"] == nil {
+		v.add(n, "the document structure must contains at least one of these properties: structure, nodesSelector")
+	}
+}
+
+// node checks the rules for document and container nodes
+func (v *validator) node(n *yaml.Node, props map[string]*yaml.Node, what string) {
+	isDocument := props["source"] != nil || props["multiSource"] != nil || props["content"] != nil
+	isContainer := props["nodes"] != nil || props["nodesSelector"] != nil
+	if !isDocument && !isContainer {
+		v.add(n, "%s must contains at least one of these properties: source, nodesSelector, multiSource, content, nodes", what)
+	}
+	if isDocument && isContainer {
+		v.add(n, "%s must be categorized as a document or a container, please specify only one of the following groups of properties: %s",
+			what, "(source/multiSource/content),(nodes,nodesSelector)")
+	}
+	if props["source"] != nil && props["multiSource"] != nil {
+		v.add(n, "%s has a source and multiSource property defined at the same time", what)
+	}
+	if props["content"] != nil && (props["source"] != nil || props["multiSource"] != nil) {
+		v.add(n, "%s has a content and source or multiSource property defined at the same time", what)
+	}
+	if props["multiSource"] != nil && props["name"] == nil {
+		v.add(n, "%s must contain a name property when multiSource is defined", what)
+	}
+	if props["content"] != nil && props["name"] == nil {
+		v.add(n, "%s must contain a name property when content is defined", what)
+	}
+	if g := props["generateIndex"]; g != nil && isDocument {
+		v.add(g, "%s is a document node, generateIndex is applicable to container nodes only", what)
+	}
+}
+
+func (v *validator) nodeSelector(props map[string]*yaml.Node, what string) {
+	if p := props["path"]; p != nil && p.Value == "" {
+		v.add(p, "%s must contains a path property", what)
+	}
+	if ep := props["excludePaths"]; ep != nil && ep.Kind == yaml.SequenceNode {
+		for _, e := range ep.Content {
+			if _, err := regexp.Compile(e.Value); err != nil {
+				v.add(e, "invalid exclude path %s: %v", e.Value, err)
+			}
+		}
+	}
+}
+
+// peers checks the name collisions and section files of peer nodes
+func (v *validator) peers(items []*yaml.Node) {
+	names := map[string]*yaml.Node{}
+	var index, indexFile *yaml.Node
+	for _, item := range items {
+		item = resolveAlias(item)
+		n := &Node{}
+		if item.Kind != yaml.MappingNode || item.Decode(n) != nil {
+			// already reported
+			continue
+		}
+		if n.When != "" {
+			if ok, err := evalCondition(n.When, v.vars); err != nil || !ok {
+				// excluded node
+				continue
+			}
+		}
+		if name, err := getNodeName(n, v.hugoEnabled); err == nil {
+			if c, ok := names[name]; ok {
+				v.add(item, "node name %s collides with the peer node at line %d", name, c.Line)
+			} else {
+				names[name] = item
+			}
+		}
+		if !n.IsDocument() {
+			continue
+		}
+		if isIdx, ok := n.Properties["index"].(bool); ok && isIdx {
+			if index != nil {
+				v.add(item, "property index: true is already defined for the peer node at line %d", index.Line)
+			} else if indexFile != nil {
+				v.add(item, "index node collides with the _index.md peer node at line %d", indexFile.Line)
+			} else {
+				index = item
+			}
+		} else if n.Name == "_index.md" || n.Name == "_index" {
+			if indexFile != nil {
+				v.add(item, "_index.md is already defined for the peer node at line %d", indexFile.Line)
+			} else if index != nil {
+				v.add(item, "_index.md collides with the index node at line %d", index.Line)
+			} else {
+				indexFile = item
+			}
+		}
+	}
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+// ManifestPaths returns the paths of the nodes selectors in a manifest, used to follow the imported manifests.
+// The paths are returned as defined, i.e. they may be relative to the manifest location.
+func ManifestPaths(b []byte, targetBranch string, flagsVars map[string]string) []string {
+	blob, err := resolveVariables(b, metadataVars(targetBranch, flagsVars))
+	if err != nil {
+		return nil
+	}
+	// the structure is decoded as far as possible, the problems are reported by Validate
+	doc := &Documentation{}
+	_ = yaml.Unmarshal(blob, doc)
+	var paths []string
+	if doc.NodeSelector != nil && strings.TrimSpace(doc.NodeSelector.Path) != "" {
+		paths = append(paths, doc.NodeSelector.Path)
+	}
+	return append(paths, nodeSelectorPaths(doc.Structure)...)
+}
+
+func nodeSelectorPaths(nodes []*Node) []string {
+	var paths []string
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if n.NodeSelector != nil && strings.TrimSpace(n.NodeSelector.Path) != "" {
+			paths = append(paths, n.NodeSelector.Path)
+		}
+		paths = append(paths, nodeSelectorPaths(n.Nodes)...)
+	}
+	return paths
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/gardener/docforge/pkg/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	DescribeTable("validating manifests", func(manifest string, expErrs []string) {
+		errs := api.Validate([]byte(manifest), "master", map[string]string{}, true)
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		Expect(got).To(Equal(expErrs))
+	},
+		Entry("valid manifest", `
+structure:
+- name: docs
+  nodes:
+  - source: https://github.com/gardener/docforge/blob/master/README.md
+  - name: overview.md
+    multiSource: [a.md, b.md]
+  - name: more
+    nodesSelector:
+      path: ./docs
+      depth: 2
+      excludePaths: ["^_"]
+nodesSelector:
+  path: ./more.yaml
+`, nil),
+		Entry("invalid YAML", "structure:\n- name: docs\n  nodes: [\n", []string{
+			"3:1: did not find expected node content",
+		}),
+		Entry("template error", "structure:\n- name: {{ .missing\n", []string{
+			"3:1: unclosed action started at :2",
+		}),
+		Entry("all problems are reported", `
+structure:
+- name: docs
+  sources: README.md
+  nodes:
+  - name: a
+  - name: b
+    source: b.md
+    nodes: []
+  - multiSource: [a.md, ~]
+nodesSelector:
+  depth: two
+  excludePaths: ["("]
+`, []string{
+			"4:3: unknown property sources of structure[0]",
+			"6:5: structure[0].nodes[0] must contains at least one of these properties: source, nodesSelector, multiSource, content, nodes",
+			"7:5: structure[0].nodes[1] must be categorized as a document or a container, please specify only one of the following groups of properties: (source/multiSource/content),(nodes,nodesSelector)",
+			"10:5: structure[0].nodes[2] must contain a name property when multiSource is defined",
+			"10:25: structure[0].nodes[2].multiSource contains empty value at position 1",
+			"12:3: nodesSelector must contain a path property",
+			"12:10: nodesSelector.depth must be an integer",
+			"13:18: invalid exclude path (: error parsing regexp: missing closing ): `(`",
+		}),
+		Entry("inline content", `
+structure:
+- name: docs
+  generateIndex: true
+  nodes:
+  - name: intro.md
+    content: "# Introduction"
+  - content: "# Overview"
+    generateIndex: yes please
+  - name: readme.md
+    source: README.md
+    content: "# Readme"
+`, []string{
+			"8:5: structure[0].nodes[1] must contain a name property when content is defined",
+			"9:20: structure[0].nodes[1].generateIndex must be a boolean",
+			"9:20: structure[0].nodes[1] is a document node, generateIndex is applicable to container nodes only",
+			"10:5: structure[0].nodes[2] has a content and source or multiSource property defined at the same time",
+		}),
+		Entry("multiSource items", `
+structure:
+- name: overview.md
+  multiSource:
+  - README.md
+  - source: usage.md
+    headingShift: 1
+  - headingShift: one
+    drop: true
+`, []string{
+			"8:5: structure[0].multiSource[2] must contain a source property",
+			"8:19: structure[0].multiSource[2].headingShift must be an integer",
+			"9:5: unknown property drop of structure[0].multiSource[2]",
+		}),
+		Entry("wrong types", `
+structure:
+  name: docs
+nodesSelector: ./docs
+`, []string{
+			"3:3: structure must be an array",
+			"4:16: nodesSelector must be an object",
+		}),
+		Entry("empty manifest", "", []string{
+			"1:1: the manifest is empty",
+		}),
+		Entry("missing structure", "source: README.md\n", []string{
+			"1:1: unknown property source of manifest",
+			"1:1: the document structure must contains at least one of these properties: structure, nodesSelector",
+		}),
+		Entry("conditions", `
+structure:
+- name: overview.md
+  source: a.md
+  when: edition=enterprise
+- name: overview.md
+  source: b.md
+  when: edition!=enterprise
+- name: c.md
+  source: c.md
+  when: (edition
+`, []string{
+			"11:9: structure[2]: invalid condition \"(edition\": missing )",
+		}),
+		Entry("peer nodes collisions", `
+structure:
+- name: overview.md
+  source: a.md
+- name: overview.md
+  source: b.md
+- source: c.md
+  properties:
+    index: true
+- source: d.md
+  properties:
+    index: true
+`, []string{
+			"5:3: node name overview.md collides with the peer node at line 3",
+			"10:3: node name _index.md collides with the peer node at line 7",
+			"10:3: property index: true is already defined for the peer node at line 7",
+		}),
+	)
+
+	Describe("ManifestPaths", func() {
+		It("returns the node selector paths", func() {
+			paths := api.ManifestPaths([]byte(`
+structure:
+- name: docs
+  nodes:
+  - name: more
+    nodesSelector:
+      path: ./more.yaml
+  - name: {{ .versions }}
+    nodesSelector:
+      path: https://github.com/gardener/docforge/blob/{{ .versions }}/.docforge/manifest.yaml
+nodesSelector:
+  path: ./docs
+`), "v1.0.0", nil)
+			Expect(paths).To(Equal([]string{"./docs", "./more.yaml", "https://github.com/gardener/docforge/blob/v1.0.0/.docforge/manifest.yaml"}))
+		})
+	})
+
+	Describe("JSONSchema", func() {
+		It("matches the published schema", func() {
+			b, err := api.JSONSchema()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Valid(b)).To(BeTrue())
+			published, err := os.ReadFile(filepath.Join("..", "..", "docs", "manifest.schema.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(string(published)), "regenerate docs/manifest.schema.json with 'docforge manifest schema'")
+		})
+	})
+})
@@ -0,0 +1,321 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the lock file model, listing the repository refs pinned to commit SHAs
+type File struct {
+	Repositories []*Entry `yaml:"repositories"`
+}
+
+// Entry pins a repository ref to a commit SHA
+type Entry struct {
+	// Repository is the repository URL, e.g. https://github.com/gardener/docforge
+	Repository string `yaml:"repository"`
+	// Ref is the branch, tag or commit referenced by the resources URIs
+	Ref string `yaml:"ref"`
+	// SHA is the commit SHA the ref is resolved to
+	SHA string `yaml:"sha"`
+}
+
+var shaRegexp = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// Locker records the repository refs touched by the resource handlers and resolves them to
+// commit SHAs. In locked mode it rewrites the refs to the commit SHAs loaded from a lock file.
+type Locker struct {
+	locked   bool
+	handlers []*handler
+	mux      sync.Mutex
+	entries  map[string]*entry
+}
+
+// entry is a lock file entry with the resource used to resolve its ref
+type entry struct {
+	Entry
+	uri  string
+	rr   resourcehandlers.RefResolver
+	once sync.Once
+	err  error
+}
+
+// NewLocker creates a Locker recording the repository refs
+func NewLocker() *Locker {
+	return &Locker{entries: make(map[string]*entry)}
+}
+
+// Load creates a Locker in locked mode from the lock file fn
+func Load(fn string) (*Locker, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("reading lock file %s fails: %v", fn, err)
+	}
+	f := &File{}
+	if err = yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("parsing lock file %s fails: %v", fn, err)
+	}
+	l := &Locker{locked: true, entries: make(map[string]*entry)}
+	for _, e := range f.Repositories {
+		if !shaRegexp.MatchString(e.SHA) {
+			return nil, fmt.Errorf("invalid commit SHA %q for ref %s of repository %s in lock file %s", e.SHA, e.Ref, e.Repository, fn)
+		}
+		l.entries[key(e.Repository, e.Ref)] = &entry{Entry: *e}
+	}
+	return l, nil
+}
+
+// Wrap wraps the resource handlers, so that the refs of the resources they access
+// are recorded or, in locked mode, pinned to the locked commit SHAs
+func (l *Locker) Wrap(rhs ...resourcehandlers.ResourceHandler) []resourcehandlers.ResourceHandler {
+	res := make([]resourcehandlers.ResourceHandler, 0, len(rhs))
+	for _, rh := range rhs {
+		h := &handler{ResourceHandler: rh, l: l}
+		h.rr, _ = rh.(resourcehandlers.RefResolver)
+		l.handlers = append(l.handlers, h)
+		res = append(res, h)
+	}
+	return res
+}
+
+// Pin returns uri with its ref replaced by the locked commit SHA. In recording mode
+// the ref is recorded and uri is returned unchanged.
+func (l *Locker) Pin(uri string) (string, error) {
+	p, _, err := l.pin(uri)
+	return p, err
+}
+
+// Write resolves the recorded refs and writes them to the lock file fn
+func (l *Locker) Write(ctx context.Context, fn string) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	f := &File{}
+	for _, e := range l.entries {
+		if err := e.resolve(ctx); err != nil {
+			if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+				// nothing to pin, the missing resources are already reported
+				continue
+			}
+			return fmt.Errorf("resolving ref %s of repository %s fails: %v", e.Ref, e.Repository, err)
+		}
+		f.Repositories = append(f.Repositories, &e.Entry)
+	}
+	sort.Slice(f.Repositories, func(i, j int) bool {
+		if f.Repositories[i].Repository != f.Repositories[j].Repository {
+			return f.Repositories[i].Repository < f.Repositories[j].Repository
+		}
+		return f.Repositories[i].Ref < f.Repositories[j].Ref
+	})
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(fn, b, 0644); err != nil {
+		return fmt.Errorf("writing lock file %s fails: %v", fn, err)
+	}
+	return nil
+}
+
+// pin returns the pinned uri and, in recording mode, the entry of its ref
+func (l *Locker) pin(uri string) (string, *entry, error) {
+	rr := l.resolver(uri)
+	if rr == nil {
+		return uri, nil, nil
+	}
+	repo, ref, err := rr.ResourceRef(uri)
+	if err != nil {
+		// not a resource of versioned repository
+		return uri, nil, nil
+	}
+	k := key(repo, ref)
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	e, ok := l.entries[k]
+	if l.locked {
+		if shaRegexp.MatchString(ref) {
+			// already pinned
+			return uri, nil, nil
+		}
+		if !ok {
+			return "", nil, fmt.Errorf("ref %s of repository %s is not locked", ref, repo)
+		}
+		p, err := rr.PinRef(uri, e.SHA)
+		return p, nil, err
+	}
+	if !ok {
+		e = &entry{Entry: Entry{Repository: repo, Ref: ref}, uri: uri, rr: rr}
+		if shaRegexp.MatchString(ref) {
+			e.SHA = ref
+		}
+		l.entries[k] = e
+	}
+	return uri, e, nil
+}
+
+// pinResolved pins uri and, in recording mode, resolves its ref to commit SHA
+func (l *Locker) pinResolved(ctx context.Context, uri string) (string, error) {
+	p, e, err := l.pin(uri)
+	if err == nil && e != nil {
+		err = e.resolve(ctx)
+	}
+	return p, err
+}
+
+// pinNodes pins the sources and node selector paths in the node hierarchies
+func (l *Locker) pinNodes(nodes []*api.Node) error {
+	var err error
+	for _, n := range nodes {
+		if n.Source, err = l.Pin(n.Source); err != nil {
+			return err
+		}
+		for i := range n.MultiSource {
+			if n.MultiSource[i].Source, err = l.Pin(n.MultiSource[i].Source); err != nil {
+				return err
+			}
+		}
+		if n.NodeSelector != nil {
+			if n.NodeSelector.Path, err = l.Pin(n.NodeSelector.Path); err != nil {
+				return err
+			}
+		}
+		if err = l.pinNodes(n.Nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolver returns the RefResolver of the handler accepting uri, if any
+func (l *Locker) resolver(uri string) resourcehandlers.RefResolver {
+	if uri == "" {
+		return nil
+	}
+	for _, h := range l.handlers {
+		if h.Accept(uri) {
+			return h.rr
+		}
+	}
+	return nil
+}
+
+// resolve resolves the ref of the entry to commit SHA once
+func (e *entry) resolve(ctx context.Context) error {
+	e.once.Do(func() {
+		if e.SHA == "" {
+			e.SHA, e.err = e.rr.ResolveRef(ctx, e.uri)
+		}
+	})
+	return e.err
+}
+
+func key(repo, ref string) string {
+	return repo + "@" + ref
+}
+
+// handler wraps a resourcehandlers.ResourceHandler pinning the refs of the accessed resources
+type handler struct {
+	resourcehandlers.ResourceHandler
+	rr resourcehandlers.RefResolver
+	l  *Locker
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (h *handler) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.Node, error) {
+	if node.NodeSelector == nil {
+		return h.ResourceHandler.ResolveNodeSelector(ctx, node)
+	}
+	p, err := h.l.pinResolved(ctx, node.NodeSelector.Path)
+	if err != nil {
+		return nil, err
+	}
+	if p != node.NodeSelector.Path {
+		// don't change the original node
+		n := *node
+		ns := *node.NodeSelector
+		ns.Path = p
+		n.NodeSelector = &ns
+		node = &n
+	}
+	return h.ResourceHandler.ResolveNodeSelector(ctx, node)
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (h *handler) Read(ctx context.Context, uri string) ([]byte, error) {
+	p, err := h.l.pinResolved(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return h.ResourceHandler.Read(ctx, p)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+func (h *handler) ReadGitInfo(ctx context.Context, uri string) ([]byte, error) {
+	p, err := h.l.pinResolved(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return h.ResourceHandler.ReadGitInfo(ctx, p)
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+func (h *handler) BuildAbsLink(source, link string) (string, error) {
+	p, err := h.l.Pin(source)
+	if err != nil {
+		return "", err
+	}
+	return h.ResourceHandler.BuildAbsLink(p, link)
+}
+
+// Pin implements the resourcehandlers.LinkPinner#Pin
+func (h *handler) Pin(link string) (string, error) {
+	return h.l.Pin(link)
+}
+
+// SourceKey implements the resourcehandlers.SourceKeyer#SourceKey
+func (h *handler) SourceKey(uri string) string {
+	return resourcehandlers.SourceKey(h.ResourceHandler, uri)
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (h *handler) GetRawFormatLink(absLink string) (string, error) {
+	p, err := h.l.Pin(absLink)
+	if err != nil {
+		return "", err
+	}
+	return h.ResourceHandler.GetRawFormatLink(p)
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+// In locked mode, the refs in the documentation structure are pinned as well, so that
+// the links between the documents match the node sources.
+func (h *handler) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	p, err := h.l.pinResolved(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := h.ResourceHandler.ResolveDocumentation(ctx, p)
+	if err != nil || doc == nil || !h.l.locked {
+		return doc, err
+	}
+	if err = h.l.pinNodes(doc.Structure); err != nil {
+		return nil, err
+	}
+	if doc.NodeSelector != nil {
+		if doc.NodeSelector.Path, err = h.l.Pin(doc.NodeSelector.Path); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
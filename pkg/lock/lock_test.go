@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/lock"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/resourcehandlersfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	sha1 = "0123456789abcdef0123456789abcdef01234567"
+	sha2 = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+// versionedHandler is a resource handler of versioned repositories
+type versionedHandler struct {
+	*resourcehandlersfakes.FakeResourceHandler
+	*resourcehandlersfakes.FakeRefResolver
+}
+
+// newVersionedHandler creates a fake handler of https://github.com/<org>/<repo>/(blob|tree)/<ref>/<path> URLs
+func newVersionedHandler() *versionedHandler {
+	h := &versionedHandler{&resourcehandlersfakes.FakeResourceHandler{}, &resourcehandlersfakes.FakeRefResolver{}}
+	h.AcceptCalls(func(uri string) bool {
+		return strings.HasPrefix(uri, "https://github.com/")
+	})
+	h.ResourceRefCalls(func(uri string) (string, string, error) {
+		s := strings.Split(uri, "/")
+		if len(s) < 6 {
+			return "", "", errors.New("not a resource URL")
+		}
+		return strings.Join(s[:5], "/"), s[6], nil
+	})
+	h.PinRefCalls(func(uri string, sha string) (string, error) {
+		s := strings.Split(uri, "/")
+		s[6] = sha
+		return strings.Join(s, "/"), nil
+	})
+	h.ResolveRefCalls(func(ctx context.Context, uri string) (string, error) {
+		if strings.Contains(uri, "/missing/") {
+			return "", resourcehandlers.ErrResourceNotFound(uri)
+		}
+		if strings.Contains(uri, "/v1/") {
+			return sha2, nil
+		}
+		return sha1, nil
+	})
+	return h
+}
+
+var _ = Describe("Lock", func() {
+	var (
+		ctx    context.Context
+		tmpDir string
+		fn     string
+		vh     *versionedHandler
+		local  *resourcehandlersfakes.FakeResourceHandler
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.TODO()
+		tmpDir, err = os.MkdirTemp("", "docforge-lock-test")
+		Expect(err).NotTo(HaveOccurred())
+		fn = filepath.Join(tmpDir, "docforge.lock")
+		vh = newVersionedHandler()
+		local = &resourcehandlersfakes.FakeResourceHandler{}
+		local.AcceptCalls(func(uri string) bool {
+			return strings.HasPrefix(uri, "file://")
+		})
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	Describe("recording", func() {
+		It("writes the resolved refs of the accessed resources", func() {
+			l := lock.NewLocker()
+			rhs := l.Wrap(vh, local)
+			Expect(rhs).To(HaveLen(2))
+			_, err := rhs[0].Read(ctx, "https://github.com/org/repo/blob/master/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rhs[0].Read(ctx, "https://github.com/org/repo/blob/master/docs/overview.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rhs[0].ResolveNodeSelector(ctx, &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/org/docs/tree/v1/docs"}})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rhs[0].BuildAbsLink("https://github.com/org/other/blob/main/README.md", "docs/overview.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rhs[0].Read(ctx, "https://github.com/org/repo/blob/"+sha2+"/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rhs[0].Read(ctx, "https://github.com/org/missing/blob/master/README.md")
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+			_, err = rhs[1].Read(ctx, "file:///docs/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			// URIs are not changed
+			Expect(vh.ReadCallCount()).To(Equal(3))
+			_, uri := vh.ReadArgsForCall(0)
+			Expect(uri).To(Equal("https://github.com/org/repo/blob/master/README.md"))
+			Expect(vh.ResolveRefCallCount()).To(Equal(3))
+
+			Expect(l.Write(ctx, fn)).To(Succeed())
+			Expect(vh.ResolveRefCallCount()).To(Equal(4))
+			b, err := os.ReadFile(fn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`repositories:
+    - repository: https://github.com/org/docs
+      ref: v1
+      sha: ` + sha2 + `
+    - repository: https://github.com/org/other
+      ref: main
+      sha: ` + sha1 + `
+    - repository: https://github.com/org/repo
+      ref: ` + sha2 + `
+      sha: ` + sha2 + `
+    - repository: https://github.com/org/repo
+      ref: master
+      sha: ` + sha1 + `
+`))
+		})
+	})
+
+	It("keeps the source keys of the wrapped handlers", func() {
+		rhs := lock.NewLocker().Wrap(vh)
+		sk, ok := rhs[0].(resourcehandlers.SourceKeyer)
+		Expect(ok).To(BeTrue())
+		Expect(sk.SourceKey("https://github.com/org/repo/blob/master/README.md?raw=true#top")).To(Equal("https://github.com/org/repo/blob/master/README.md"))
+	})
+
+	Describe("locked", func() {
+		var (
+			l   *lock.Locker
+			rhs []resourcehandlers.ResourceHandler
+		)
+
+		BeforeEach(func() {
+			var err error
+			Expect(os.WriteFile(fn, []byte("repositories:\n- repository: https://github.com/org/repo\n  ref: master\n  sha: "+sha1+"\n"), 0644)).To(Succeed())
+			l, err = lock.Load(fn)
+			Expect(err).NotTo(HaveOccurred())
+			rhs = l.Wrap(vh, local)
+		})
+
+		It("pins the refs to the locked commit SHAs", func() {
+			_, err := rhs[0].Read(ctx, "https://github.com/org/repo/blob/master/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, uri := vh.ReadArgsForCall(0)
+			Expect(uri).To(Equal("https://github.com/org/repo/blob/" + sha1 + "/README.md"))
+			_, err = rhs[0].ReadGitInfo(ctx, "https://github.com/org/repo/blob/master/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, uri = vh.ReadGitInfoArgsForCall(0)
+			Expect(uri).To(Equal("https://github.com/org/repo/blob/" + sha1 + "/README.md"))
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/org/repo/tree/master/docs"}}
+			_, err = rhs[0].ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			_, n := vh.ResolveNodeSelectorArgsForCall(0)
+			Expect(n.NodeSelector.Path).To(Equal("https://github.com/org/repo/tree/" + sha1 + "/docs"))
+			Expect(node.NodeSelector.Path).To(Equal("https://github.com/org/repo/tree/master/docs"))
+			_, err = rhs[0].BuildAbsLink("https://github.com/org/repo/blob/master/README.md", "docs/overview.md")
+			Expect(err).NotTo(HaveOccurred())
+			source, _ := vh.BuildAbsLinkArgsForCall(0)
+			Expect(source).To(Equal("https://github.com/org/repo/blob/" + sha1 + "/README.md"))
+			// commit SHAs are already pinned
+			_, err = rhs[0].Read(ctx, "https://github.com/org/repo/blob/"+sha2+"/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, uri = vh.ReadArgsForCall(1)
+			Expect(uri).To(Equal("https://github.com/org/repo/blob/" + sha2 + "/README.md"))
+			Expect(vh.ResolveRefCallCount()).To(Equal(0))
+		})
+
+		It("pins the absolute links", func() {
+			p, ok := rhs[0].(resourcehandlers.LinkPinner)
+			Expect(ok).To(BeTrue())
+			link, err := p.Pin("https://github.com/org/repo/blob/master/docs/overview.md#usage")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("https://github.com/org/repo/blob/" + sha1 + "/docs/overview.md#usage"))
+			_, err = p.Pin("https://github.com/org/other/blob/master/README.md")
+			Expect(err).To(MatchError("ref master of repository https://github.com/org/other is not locked"))
+		})
+
+		It("fails for refs missing in the lock file", func() {
+			_, err := rhs[0].Read(ctx, "https://github.com/org/repo/blob/main/README.md")
+			Expect(err).To(MatchError("ref main of repository https://github.com/org/repo is not locked"))
+			Expect(vh.ReadCallCount()).To(Equal(0))
+		})
+
+		It("pins the documentation structure", func() {
+			local.ResolveDocumentationReturns(&api.Documentation{
+				Structure: []*api.Node{
+					{Source: "https://github.com/org/repo/blob/master/README.md"},
+					{Name: "docs", Nodes: []*api.Node{
+						{MultiSource: []api.MultiSourceItem{{Source: "file:///docs/intro.md"}, {Source: "https://github.com/org/repo/blob/master/docs/intro.md"}}},
+					}},
+				},
+				NodeSelector: &api.NodeSelector{Path: "https://github.com/org/repo/tree/master/docs"},
+			}, nil)
+			doc, err := rhs[1].ResolveDocumentation(ctx, "file:///docs/manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Structure[0].Source).To(Equal("https://github.com/org/repo/blob/" + sha1 + "/README.md"))
+			Expect(doc.Structure[1].Nodes[0].MultiSource).To(Equal([]api.MultiSourceItem{{Source: "file:///docs/intro.md"}, {Source: "https://github.com/org/repo/blob/" + sha1 + "/docs/intro.md"}}))
+			Expect(doc.NodeSelector.Path).To(Equal("https://github.com/org/repo/tree/" + sha1 + "/docs"))
+		})
+	})
+
+	It("fails to load invalid lock file", func() {
+		Expect(os.WriteFile(fn, []byte("repositories:\n- repository: https://github.com/org/repo\n  ref: master\n  sha: master\n"), 0644)).To(Succeed())
+		_, err := lock.Load(fn)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// NewCodeBlock creates a fenced code block with the code in the language, that can be empty. The block content
+// is appended to a copy of the document source, that is returned to render the document with the block.
+func NewCodeBlock(source []byte, code []byte, language string) (*ast.FencedCodeBlock, []byte) {
+	src := make([]byte, len(source), len(source)+len(language)+len(code)+2)
+	copy(src, source)
+	var info *ast.Text
+	if language != "" {
+		start := len(src)
+		src = append(src, language...)
+		info = ast.NewTextSegment(text.NewSegment(start, len(src)))
+	}
+	src = append(src, '\n')
+	block := ast.NewFencedCodeBlock(info)
+	var lines *text.Segments
+	src, lines = appendLines(src, code)
+	block.SetLines(lines)
+	block.SetBlankPreviousLines(true)
+	return block, src
+}
+
+// NewRawBlock creates a block with markdown content that is rendered as is. The block content
+// is appended to a copy of the document source, that is returned to render the document with the block.
+func NewRawBlock(source []byte, content []byte) (*ast.HTMLBlock, []byte) {
+	src := make([]byte, len(source), len(source)+len(content)+1)
+	copy(src, source)
+	// blocks of type 1 to 5 are rendered without modifications
+	block := ast.NewHTMLBlock(ast.HTMLBlockType2)
+	var lines *text.Segments
+	src, lines = appendLines(src, content)
+	block.SetLines(lines)
+	block.SetBlankPreviousLines(true)
+	return block, src
+}
+
+// appendLines appends the content to the source and returns the segments of the appended lines
+func appendLines(src []byte, content []byte) ([]byte, *text.Segments) {
+	start := len(src)
+	src = append(src, content...)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		src = append(src, '\n')
+	}
+	lines := text.NewSegments()
+	for i := start; i < len(src); {
+		end := i + bytes.IndexByte(src[i:], '\n') + 1
+		lines.Append(text.NewSegment(i, end))
+		i = end
+	}
+	return src, lines
+}
@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown_test
+
+import (
+	"bytes"
+
+	"github.com/gardener/docforge/pkg/markdown"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Code blocks", func() {
+	DescribeTable("creating code blocks", func(code string, language string, exp string) {
+		md := []byte("# Example\n\n<!-- code -->\n")
+		doc, err := markdown.Parse(md)
+		Expect(err).NotTo(HaveOccurred())
+		block, src := markdown.NewCodeBlock(md, []byte(code), language)
+		Expect(src[:len(md)]).To(Equal(md))
+		doc.ReplaceChild(doc, doc.LastChild(), block)
+		buf := &bytes.Buffer{}
+		Expect(markdown.NewLinkModifierRenderer().Render(buf, src, doc)).To(Succeed())
+		Expect(buf.String()).To(Equal(exp))
+	},
+		Entry("with language", "package main\n\nfunc main() {}\n", "go", "# Example\n\n```go\npackage main\n\nfunc main() {}\n```\n"),
+		Entry("without language", "make build", "", "# Example\n\n```\nmake build\n```\n"),
+		Entry("empty code", "", "yaml", "# Example\n\n```yaml\n```\n"),
+	)
+})
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	linesFragmentRgx = regexp.MustCompile(`^L(\d+)(?:-L(\d+))?$`)
+	// htmlAnchorRgx matches the HTML attributes defining anchors, e.g. `id="install"` or `name='install'`
+	htmlAnchorRgx = regexp.MustCompile(`\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+)
+
+// ExtractFragment cuts the document AST to a fragment of the source document. The fragment is either:
+// - a line range in GitHub format, e.g. `L10-L20` or `L10`, that selects the top-level blocks starting in it
+// - a heading anchor, e.g. `installation`, that selects the heading and the content up to the next heading
+// of the same or higher level
+// The document front matter is kept.
+func ExtractFragment(doc ast.Node, source []byte, fragment string) error {
+	if from, to, ok := LinesRange(fragment); ok {
+		if to < from {
+			return fmt.Errorf("invalid line range %s", fragment)
+		}
+		extractLines(doc, source, from, to)
+		return nil
+	}
+	return extractSection(doc, source, fragment)
+}
+
+// LinesRange parses a lines fragment, e.g. `L10-L20` or `L10`, and returns the first and last line
+func LinesRange(fragment string) (int, int, bool) {
+	m := linesFragmentRgx.FindStringSubmatch(fragment)
+	if m == nil {
+		return 0, 0, false
+	}
+	from, _ := strconv.Atoi(m[1])
+	to := from
+	if m[2] != "" {
+		to, _ = strconv.Atoi(m[2])
+	}
+	return from, to, true
+}
+
+// extractLines keeps the top-level blocks starting in the line range. Blocks without position, e.g. thematic breaks,
+// follow the preceding block.
+func extractLines(doc ast.Node, source []byte, from, to int) {
+	var remove []ast.Node
+	line := 0
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if start := blockStart(c); start >= 0 {
+			line = bytes.Count(source[:start], []byte("\n")) + 1
+		}
+		if line < from || line > to {
+			remove = append(remove, c)
+		}
+	}
+	for _, c := range remove {
+		doc.RemoveChild(doc, c)
+	}
+}
+
+// blockStart returns the source offset of a block, or -1 if the block has no position
+func blockStart(n ast.Node) int {
+	if n.Type() != ast.TypeBlock {
+		return -1
+	}
+	if n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start
+	}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if s := blockStart(c); s >= 0 {
+			return s
+		}
+	}
+	return -1
+}
+
+// extractSection keeps the top-level heading with the anchor and its section
+func extractSection(doc ast.Node, source []byte, anchor string) error {
+	var (
+		remove  []ast.Node
+		heading *ast.Heading
+		done    bool
+	)
+	anchors := map[string]int{}
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if h, ok := c.(*ast.Heading); ok {
+			if heading != nil && h.Level <= heading.Level {
+				done = true
+			}
+			if heading == nil && uniqueAnchor(HeadingAnchor(h, source), anchors) == anchor {
+				heading = h
+			}
+		}
+		if heading == nil || done {
+			remove = append(remove, c)
+		}
+	}
+	if heading == nil {
+		return fmt.Errorf("section #%s not found", anchor)
+	}
+	for _, c := range remove {
+		doc.RemoveChild(doc, c)
+	}
+	return nil
+}
+
+// uniqueAnchor suffixes the repeated anchors with their sequence number, e.g. `usage-1`
+func uniqueAnchor(anchor string, anchors map[string]int) string {
+	n, ok := anchors[anchor]
+	anchors[anchor] = n + 1
+	if !ok {
+		return anchor
+	}
+	return fmt.Sprintf("%s-%d", anchor, n)
+}
+
+// HeadingAnchor returns the GitHub style anchor of a heading, i.e. the heading text in lower case
+// without punctuation and with dashes instead of spaces, e.g. `getting-started` for `## Getting Started`
+func HeadingAnchor(h *ast.Heading, source []byte) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(string(h.Text(source))) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Anchors returns the anchors defined in a document, i.e. the heading anchors formatted as by HeadingAnchor,
+// with the repeated ones suffixed by their sequence number, and the `id` and `name` attributes of HTML elements
+func Anchors(doc ast.Node, source []byte) []string {
+	var res []string
+	anchors := map[string]int{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Heading:
+			res = append(res, uniqueAnchor(HeadingAnchor(v, source), anchors))
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock:
+			res = append(res, htmlAnchors(v.Lines(), source)...)
+		case *ast.RawHTML:
+			res = append(res, htmlAnchors(v.Segments, source)...)
+		}
+		return ast.WalkContinue, nil
+	})
+	return res
+}
+
+// htmlAnchors returns the anchors defined by the attributes of the HTML elements in the segments
+func htmlAnchors(segments *text.Segments, source []byte) []string {
+	var (
+		html bytes.Buffer
+		res  []string
+	)
+	for _, s := range segments.Sliced(0, segments.Len()) {
+		html.Write(s.Value(source))
+	}
+	for _, m := range htmlAnchorRgx.FindAllSubmatch(html.Bytes(), -1) {
+		res = append(res, string(m[1]))
+	}
+	return res
+}
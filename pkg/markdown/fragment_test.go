@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown_test
+
+import (
+	"bytes"
+
+	"github.com/gardener/docforge/pkg/markdown"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/yuin/goldmark/ast"
+)
+
+var _ = Describe("Fragments", func() {
+	md := `---
+title: Gardener
+---
+# Gardener
+
+Intro
+
+## Installation
+
+Install it.
+
+### Prerequisites
+
+Kubernetes.
+
+## Usage
+
+Use it.
+
+## Usage
+
+Use it again.
+`
+	DescribeTable("extracting fragments", func(fragment string, exp string, expErr string) {
+		doc, err := markdown.Parse([]byte(md))
+		Expect(err).NotTo(HaveOccurred())
+		err = markdown.ExtractFragment(doc, []byte(md), fragment)
+		if expErr != "" {
+			Expect(err).To(MatchError(expErr))
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		buf := &bytes.Buffer{}
+		Expect(markdown.NewLinkModifierRenderer().Render(buf, []byte(md), doc)).To(Succeed())
+		Expect(buf.String()).To(Equal(exp))
+	},
+		Entry("section", "installation", "---\ntitle: Gardener\n---\n\n## Installation\n\nInstall it.\n\n### Prerequisites\n\nKubernetes.\n", ""),
+		Entry("nested section", "prerequisites", "---\ntitle: Gardener\n---\n\n### Prerequisites\n\nKubernetes.\n", ""),
+		Entry("repeated heading", "usage-1", "---\ntitle: Gardener\n---\n\n## Usage\n\nUse it again.\n", ""),
+		Entry("missing section", "uninstall", "", "section #uninstall not found"),
+		Entry("line range", "L8-L10", "---\ntitle: Gardener\n---\n\n## Installation\n\nInstall it.\n", ""),
+		Entry("single line", "L6", "---\ntitle: Gardener\n---\n\nIntro\n", ""),
+		Entry("invalid line range", "L10-L8", "", "invalid line range L10-L8"),
+	)
+
+	DescribeTable("heading anchors", func(heading string, exp string) {
+		doc, err := markdown.Parse([]byte(heading))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(markdown.HeadingAnchor(doc.FirstChild().(*ast.Heading), []byte(heading))).To(Equal(exp))
+	},
+		Entry("words", "## Getting Started", "getting-started"),
+		Entry("punctuation", "## What's new? (v1.2)", "whats-new-v12"),
+		Entry("code span", "## The `docforge` CLI", "the-docforge-cli"),
+		Entry("dashes and underscores", "## pre-release_notes", "pre-release_notes"),
+	)
+
+	It("collects the document anchors", func() {
+		src := []byte("# Gardener\n\n<div id=\"overview\">\n\nIntro <a name='intro'></a>\n\n</div>\n\n- ## Usage\n\n## Usage\n")
+		doc, err := markdown.Parse(src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(markdown.Anchors(doc, src)).To(Equal([]string{"gardener", "overview", "intro", "usage", "usage-1"}))
+	})
+})
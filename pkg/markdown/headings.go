@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"github.com/yuin/goldmark/ast"
+)
+
+// ShiftHeadings shifts the levels of the document headings, keeping them between 1 and 6
+func ShiftHeadings(doc ast.Node, shift int) {
+	if shift == 0 {
+		return
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			h.Level = headingLevel(h.Level + shift)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// DropFirstHeading removes the first top-level heading of the document
+func DropFirstHeading(doc ast.Node) {
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == ast.KindHeading {
+			doc.RemoveChild(doc, c)
+			return
+		}
+	}
+}
+
+// PrependHeading inserts a heading with the text before the document content
+func PrependHeading(doc ast.Node, level int, text string) {
+	h := ast.NewHeading(headingLevel(level))
+	h.AppendChild(h, ast.NewString([]byte(text)))
+	prepend(doc, h)
+}
+
+// PrependThematicBreak inserts a thematic break before the document content
+func PrependThematicBreak(doc ast.Node) {
+	prepend(doc, ast.NewThematicBreak())
+}
+
+func prepend(doc ast.Node, block ast.Node) {
+	if first := doc.FirstChild(); first != nil {
+		// separate the content with a blank line
+		first.SetBlankPreviousLines(true)
+		doc.InsertBefore(doc, first, block)
+	} else {
+		doc.AppendChild(doc, block)
+	}
+	block.SetBlankPreviousLines(true)
+}
+
+func headingLevel(level int) int {
+	if level < 1 {
+		return 1
+	}
+	if level > 6 {
+		return 6
+	}
+	return level
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown_test
+
+import (
+	"bytes"
+
+	"github.com/gardener/docforge/pkg/markdown"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/yuin/goldmark/ast"
+)
+
+var _ = Describe("Headings", func() {
+	md := "# Title\n\nIntro\n\n## Usage\n\n##### Details\n"
+	DescribeTable("adapting headings", func(adapt func(doc ast.Node), exp string) {
+		doc, err := markdown.Parse([]byte(md))
+		Expect(err).NotTo(HaveOccurred())
+		adapt(doc)
+		buf := &bytes.Buffer{}
+		Expect(markdown.NewLinkModifierRenderer().Render(buf, []byte(md), doc)).To(Succeed())
+		Expect(buf.String()).To(Equal(exp))
+	},
+		Entry("shift", func(doc ast.Node) { markdown.ShiftHeadings(doc, 1) },
+			"## Title\n\nIntro\n\n### Usage\n\n###### Details\n"),
+		Entry("shift up", func(doc ast.Node) { markdown.ShiftHeadings(doc, -1) },
+			"# Title\n\nIntro\n\n# Usage\n\n#### Details\n"),
+		Entry("drop first heading", func(doc ast.Node) { markdown.DropFirstHeading(doc) },
+			"Intro\n\n## Usage\n\n##### Details\n"),
+		Entry("prepend heading", func(doc ast.Node) {
+			markdown.DropFirstHeading(doc)
+			markdown.PrependHeading(doc, 2, "Overview")
+		}, "## Overview\n\nIntro\n\n## Usage\n\n##### Details\n"),
+		Entry("prepend thematic break", func(doc ast.Node) { markdown.PrependThematicBreak(doc) },
+			"---\n\n# Title\n\nIntro\n\n## Usage\n\n##### Details\n"),
+	)
+})
@@ -573,6 +573,11 @@ func (r *Renderer) renderRawHTML(node ast.Node, entering bool) (ast.WalkStatus,
 
 func (r *Renderer) renderText(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
+		if s, ok := node.(*ast.String); ok {
+			// generated text
+			_, _ = r.writer.Write(s.Value)
+			return ast.WalkSkipChildren, nil
+		}
 		n := node.(*ast.Text)
 		txt := n.Text(r.source)
 		r.additionalIndents(txt, n)
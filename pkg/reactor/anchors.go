@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/markdown"
+)
+
+// anchorLink is a link to an anchor of a document node
+type anchorLink struct {
+	// source is the source document of the link
+	source string
+	// destination is the link destination as defined in the source document
+	destination string
+	// node is the document node which anchor is linked
+	node   *api.Node
+	anchor string
+}
+
+// anchorRegistry collects the anchors of the processed documents and the links to them
+type anchorRegistry struct {
+	anchors map[*api.Node]map[string]struct{}
+	links   []*anchorLink
+	mux     sync.Mutex
+}
+
+func newAnchorRegistry() *anchorRegistry {
+	return &anchorRegistry{anchors: make(map[*api.Node]map[string]struct{})}
+}
+
+// addLink registers a link to an anchor of a document node
+func (a *anchorRegistry) addLink(link *anchorLink) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.links = append(a.links, link)
+}
+
+// addAnchors registers the anchors of the document node content
+func (a *anchorRegistry) addAnchors(node *api.Node, content []byte) error {
+	doc, err := markdown.Parse(content)
+	if err != nil {
+		return err
+	}
+	anchors := make(map[string]struct{})
+	for _, anchor := range markdown.Anchors(doc, content) {
+		anchors[anchor] = struct{}{}
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.anchors[node] = anchors
+	return nil
+}
+
+// brokenAnchors returns the destinations of the links to missing anchors per source document.
+// Links to documents that were not processed are not checked.
+func (a *anchorRegistry) brokenAnchors() map[string][]string {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	res := make(map[string][]string)
+	for _, l := range a.links {
+		anchors, ok := a.anchors[l.node]
+		if !ok {
+			continue
+		}
+		if _, ok = anchors[l.anchor]; !ok {
+			res[l.source] = append(res[l.source], l.destination)
+		}
+	}
+	for source, destinations := range res {
+		sort.Strings(destinations)
+		res[source] = uniqueStrings(destinations)
+	}
+	return res
+}
+
+// uniqueStrings removes the repeated strings from a sorted slice
+func uniqueStrings(s []string) []string {
+	res := s[:0]
+	for _, v := range s {
+		if len(res) == 0 || v != res[len(res)-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_brokenAnchors(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	r := fakeReader{
+		docs + "a.md": []byte("# A\n\n## Setup\n\nSee [setup](#setup), [install](#install), [usage](./b.md#usage) and [config](./b.md#config).\n"),
+		docs + "b.md": []byte("# B\n\n## Usage\n\n<a id=\"flags\"></a>\nSee [A](./a.md#install).\n"),
+	}
+	tests := []struct {
+		name  string
+		nodes []*api.Node
+		want  map[string][]string
+	}{
+		{
+			name: "links between documents",
+			nodes: []*api.Node{
+				{Name: "a.md", Source: docs + "a.md"},
+				{Name: "b.md", Source: docs + "b.md"},
+			},
+			want: map[string][]string{
+				docs + "a.md": {"#install", "./b.md#config"},
+				docs + "b.md": {"./a.md#install"},
+			},
+		},
+		{
+			name: "links from inline content",
+			nodes: []*api.Node{
+				{Name: "b.md", Source: docs + "b.md"},
+				{Name: "c.md", Content: "[flags](./b.md#flags), [usage](./b.md#usage), [options](./b.md#options)\n"},
+			},
+			want: map[string][]string{
+				"root/c.md": {"./b.md#options"},
+			},
+		},
+		{
+			name: "links to documents not processed",
+			nodes: []*api.Node{
+				{Name: "b.md", Source: docs + "b.md"},
+			},
+			want: map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := fakeLinkHandler()
+			c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+			root := &api.Node{Name: "root", Nodes: tt.nodes}
+			root.SetParentsDownwards()
+			c.Prepare(tt.nodes)
+			for _, n := range tt.nodes {
+				var b bytes.Buffer
+				assert.NoError(t, c.Process(context.TODO(), &b, r, n))
+			}
+			assert.Equal(t, tt.want, c.BrokenAnchors())
+		})
+	}
+}
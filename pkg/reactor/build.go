@@ -6,10 +6,15 @@ package reactor
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/gardener/docforge/pkg/api"
 	"github.com/hashicorp/go-multierror"
 	"k8s.io/klog/v2"
 	"net/url"
+	"path"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -67,6 +72,22 @@ func (r *Reactor) Build(ctx context.Context, documentationStructure []*api.Node)
 	}
 	klog.Infof("Validation tasks processed: %d\n", r.ValidatorTasks.GetProcessedTasksCount())
 
+	brokenAnchors := r.DocumentWorker.NodeContentProcessor.BrokenAnchors()
+	sources := make([]string, 0, len(brokenAnchors))
+	for source := range brokenAnchors {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		klog.Warningf("broken anchors in source %s: %s\n", source, strings.Join(brokenAnchors[source], ", "))
+	}
+
+	if r.Options.ResourcesMappingFile != "" {
+		if err := r.writeResourcesMapping(); err != nil {
+			errors = multierror.Append(errors, err)
+		}
+	}
+
 	for _, rhHost := range []string{"https://github.com", "https://github.tools.sap", "https://github.wdf.sap.corp"} {
 		rh := r.ResourceHandlers.Get(rhHost)
 		u, _ := url.Parse(rhHost)
@@ -101,3 +122,16 @@ func (r *Reactor) Build(ctx context.Context, documentationStructure []*api.Node)
 
 	return errors.ErrorOrNil()
 }
+
+// writeResourcesMapping writes the report mapping the URLs of the downloaded resources to the stored files
+func (r *Reactor) writeResourcesMapping() error {
+	b, err := json.MarshalIndent(r.DocumentWorker.NodeContentProcessor.ResourcesMapping(), "", "  ")
+	if err != nil {
+		return err
+	}
+	dir, name := path.Split(path.Clean(r.Options.ResourcesMappingFile))
+	if err = r.Options.Writer.Write(name, dir, append(b, '\n'), nil); err != nil {
+		return fmt.Errorf("writing resources mapping %s failed: %v", r.Options.ResourcesMappingFile, err)
+	}
+	return nil
+}
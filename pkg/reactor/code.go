@@ -0,0 +1,271 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/markdown"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/yuin/goldmark/ast"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// codeLanguages maps the source code file extensions (or names) to the fenced code block languages
+	codeLanguages = map[string]string{
+		".go":        "go",
+		".mod":       "go",
+		".yaml":      "yaml",
+		".yml":       "yaml",
+		".json":      "json",
+		".sh":        "bash",
+		".bash":      "bash",
+		".py":        "python",
+		".js":        "javascript",
+		".ts":        "typescript",
+		".java":      "java",
+		".kt":        "kotlin",
+		".tf":        "hcl",
+		".hcl":       "hcl",
+		".toml":      "toml",
+		".xml":       "xml",
+		".sql":       "sql",
+		".proto":     "protobuf",
+		".rs":        "rust",
+		".c":         "c",
+		".h":         "c",
+		".cpp":       "cpp",
+		".cc":        "cpp",
+		".rb":        "ruby",
+		".ini":       "ini",
+		".mk":        "makefile",
+		"Makefile":   "makefile",
+		"Dockerfile": "dockerfile",
+	}
+	// codeDirectiveRgx matches `<!-- docforge:code <url>[#fragment] [language] -->`
+	codeDirectiveRgx = regexp.MustCompile(`^\s*<!--\s*docforge:code\s+(\S+)(?:\s+([\w+-]+))?\s*-->\s*$`)
+)
+
+const (
+	codeStartMarker = "docforge:start"
+	codeEndMarker   = "docforge:end"
+)
+
+// codeLanguage returns the language of a source code file, or false if the file is not a known source code file
+func codeLanguage(uri string) (string, bool) {
+	if u, err := url.Parse(uri); err == nil {
+		uri = u.Path
+	}
+	name := path.Base(uri)
+	if lang, ok := codeLanguages[name]; ok {
+		return lang, true
+	}
+	lang, ok := codeLanguages[strings.ToLower(path.Ext(name))]
+	return lang, ok
+}
+
+// codeContent creates a document with the source code snippet selected by the fragment as a fenced code block
+func codeContent(code []byte, uri string, fragment string, language string) (*docContent, error) {
+	snippet, err := selectCode(code, fragment)
+	if err != nil {
+		return nil, err
+	}
+	doc := ast.NewDocument()
+	doc.SetMeta(map[string]interface{}{})
+	block, src := markdown.NewCodeBlock(nil, snippet, language)
+	doc.AppendChild(doc, block)
+	return &docContent{docAst: doc, docCnt: src, docURI: uri}, nil
+}
+
+// selectCode returns the lines of the source code selected by the fragment. The fragment is either:
+// - a line range in GitHub format, e.g. `L10-L20` or `L10`
+// - a marker name, e.g. `setup`, that selects the lines between the lines containing
+// `docforge:start setup` and `docforge:end setup`
+// The common indentation of the selected lines is removed.
+func selectCode(code []byte, fragment string) ([]byte, error) {
+	if fragment == "" {
+		return code, nil
+	}
+	lines := strings.SplitAfter(string(code), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var selected []string
+	if from, to, ok := markdown.LinesRange(fragment); ok {
+		if from < 1 || to < from || from > len(lines) {
+			return nil, fmt.Errorf("invalid line range %s", fragment)
+		}
+		if to > len(lines) {
+			to = len(lines)
+		}
+		selected = lines[from-1 : to]
+	} else {
+		start, end := -1, -1
+		for i, l := range lines {
+			if start < 0 && isMarker(l, codeStartMarker, fragment) {
+				start = i
+			} else if start >= 0 && isMarker(l, codeEndMarker, fragment) {
+				end = i
+				break
+			}
+		}
+		if start < 0 || end < 0 {
+			return nil, fmt.Errorf("code marker %s not found", fragment)
+		}
+		for _, l := range lines[start+1 : end] {
+			// nested markers are not part of the snippet
+			if !strings.Contains(l, codeStartMarker+" ") && !strings.Contains(l, codeEndMarker+" ") {
+				selected = append(selected, l)
+			}
+		}
+	}
+	return []byte(strings.Join(dedent(selected), "")), nil
+}
+
+// isMarker checks if the line contains the marker with the name, e.g. `// docforge:start setup`
+func isMarker(line string, marker string, name string) bool {
+	i := strings.Index(line, marker+" ")
+	if i < 0 {
+		return false
+	}
+	fields := strings.Fields(line[i+len(marker):])
+	return len(fields) > 0 && fields[0] == name
+}
+
+// dedent removes the indentation common to the non-blank lines
+func dedent(lines []string) []string {
+	indent := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		i := len(l) - len(strings.TrimLeft(l, " \t"))
+		if first {
+			indent, first = l[:i], false
+			continue
+		}
+		j := 0
+		for j < len(indent) && j < i && indent[j] == l[j] {
+			j++
+		}
+		indent = indent[:j]
+	}
+	if indent == "" {
+		return lines
+	}
+	res := make([]string, len(lines))
+	for i, l := range lines {
+		res[i] = strings.TrimPrefix(l, indent)
+		if strings.TrimSpace(l) == "" && !strings.HasPrefix(l, indent) {
+			res[i] = strings.TrimLeft(l, " \t")
+		}
+	}
+	return res
+}
+
+// embedCode replaces the `<!-- docforge:code <url>[#fragment] [language] -->` directives in the document with
+// fenced code blocks of the referenced source code. Relative URLs are resolved against the document source,
+// the directives in inline content must use absolute URLs.
+func (c *nodeContentProcessor) embedCode(ctx context.Context, r Reader, dc *docContent) error {
+	blocks, directives, err := findDirectives(dc, codeDirectiveRgx)
+	if err != nil {
+		return err
+	}
+	for i, h := range blocks {
+		m := directives[i]
+		uri, fragment := splitFragment(m[1])
+		if uri, err = c.directiveURI(dc.docURI, uri, "code"); err != nil {
+			return err
+		}
+		if uri == "" {
+			continue
+		}
+		code, err := r.Read(ctx, uri)
+		if err != nil {
+			if resourceNotFound, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+				klog.Warningf("reading code %s failed: %s\n", m[1], resourceNotFound)
+				continue
+			}
+			return fmt.Errorf("reading code %s failed: %w", m[1], err)
+		}
+		snippet, err := selectCode(code, fragment)
+		if err != nil {
+			return fmt.Errorf("fail to embed code %s: %w", m[1], err)
+		}
+		language := m[2]
+		if language == "" {
+			language, _ = codeLanguage(uri)
+		}
+		var block *ast.FencedCodeBlock
+		block, dc.docCnt = markdown.NewCodeBlock(dc.docCnt, snippet, language)
+		h.Parent().ReplaceChild(h.Parent(), h, block)
+	}
+	return nil
+}
+
+// findDirectives returns the HTML blocks of the document matching the directive regular expression, with their submatches
+func findDirectives(dc *docContent, rgx *regexp.Regexp) ([]*ast.HTMLBlock, [][]string, error) {
+	var (
+		blocks     []*ast.HTMLBlock
+		directives [][]string
+	)
+	err := ast.Walk(dc.docAst, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.HTMLBlock)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		var text bytes.Buffer
+		for i := 0; i < h.Lines().Len(); i++ {
+			line := h.Lines().At(i)
+			text.Write(line.Value(dc.docCnt))
+		}
+		if h.HasClosure() {
+			closure := h.ClosureLine
+			text.Write(closure.Value(dc.docCnt))
+		}
+		if m := rgx.FindStringSubmatch(text.String()); m != nil {
+			blocks = append(blocks, h)
+			directives = append(directives, m)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks, directives, err
+}
+
+// directiveURI returns the absolute URI referenced by a document directive, or empty string if it can't be resolved
+func (c *nodeContentProcessor) directiveURI(docURI string, uri string, what string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s URL %s: %w", what, uri, err)
+	}
+	if u.IsAbs() {
+		return uri, nil
+	}
+	if docURI == "" {
+		return "", fmt.Errorf("%s URL %s in inline content must be absolute", what, uri)
+	}
+	handler := c.resourceHandlers.Get(docURI)
+	if handler == nil {
+		klog.Warningf("no handler to resolve %s %s from source %s\n", what, uri, docURI)
+		return "", nil
+	}
+	absLink, err := handler.BuildAbsLink(docURI, uri)
+	if err != nil {
+		if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+			klog.Warningf("%s %s from source %s not found: %v\n", what, uri, docURI, err)
+			return "", nil
+		}
+		return "", err
+	}
+	return absLink, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/resourcehandlersfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_processCode(t *testing.T) {
+	base := "https://github.com/gardener/docforge/blob/master/"
+	r := fakeReader{
+		base + "main.go":   []byte("package main\n\nfunc main() {\n\t// docforge:start run\n\tif err := run(); err != nil {\n\t\tpanic(err)\n\t}\n\t// docforge:end run\n}\n"),
+		base + "Makefile":  []byte("build:\n\tgo build ./...\n"),
+		base + "README.md": []byte("# Docforge\n\nRun it:\n\n<!-- docforge:code ./main.go#run -->\n\n- Build it:\n\n  <!-- docforge:code ./Makefile#L2 sh -->\n\n<!-- other comment -->\n"),
+	}
+	tests := []struct {
+		name    string
+		node    *api.Node
+		want    string
+		wantErr string
+	}{
+		{
+			name: "code source",
+			node: &api.Node{Name: "main.md", Source: base + "main.go#L1-L3"},
+			want: "```go\npackage main\n\nfunc main() {\n```\n",
+		},
+		{
+			name: "code marker in multiSource",
+			node: &api.Node{Name: "run.md", MultiSource: []api.MultiSourceItem{{Source: base + "main.go#run", Heading: "Run"}}},
+			want: "# Run\n\n```go\nif err := run(); err != nil {\n\tpanic(err)\n}\n```\n",
+		},
+		{
+			name: "code directives",
+			node: &api.Node{Name: "readme.md", Source: base + "README.md"},
+			want: "# Docforge\n\nRun it:\n\n```go\nif err := run(); err != nil {\n\tpanic(err)\n}\n```\n\n- Build it:\n  \n  ```sh\n  go build ./...\n  ```\n\n<!-- other comment -->\n",
+		},
+		{
+			name: "code directive in inline content",
+			node: &api.Node{Name: "build.md", Content: "Build it:\n\n<!-- docforge:code " + base + "Makefile -->\n"},
+			want: "Build it:\n\n```makefile\nbuild:\n\tgo build ./...\n```\n",
+		},
+		{
+			name:    "relative code directive in inline content",
+			node:    &api.Node{Name: "build.md", Content: "<!-- docforge:code ./Makefile -->\n"},
+			wantErr: "fail to embed code in content from node /build.md: code URL ./Makefile in inline content must be absolute",
+		},
+		{
+			name:    "missing code marker",
+			node:    &api.Node{Name: "main.md", Source: base + "main.go#setup"},
+			wantErr: "fail to extract source " + base + "main.go#setup from node /main.md: code marker setup not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &resourcehandlersfakes.FakeResourceHandler{}
+			h.AcceptReturns(true)
+			h.BuildAbsLinkStub = func(source, link string) (string, error) {
+				return source[:strings.LastIndex(source, "/")+1] + path.Clean(link), nil
+			}
+			c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+			var b bytes.Buffer
+			err := c.Process(context.TODO(), &b, r, tt.node)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
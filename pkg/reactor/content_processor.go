@@ -7,11 +7,8 @@ package reactor
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"fmt"
 	"net/url"
-	"path"
 	"strings"
 	"sync"
 
@@ -38,7 +35,15 @@ type nodeContentProcessor struct {
 	validator        Validator
 	resourceHandlers resourcehandlers.Registry
 	sourceLocations  map[string][]*api.Node
+	structure        []*api.Node
 	hugo             *Hugo
+	mkdocs           *MkDocs
+	downloadPolicy   *DownloadPolicy
+	resourcesLayout  *ResourcesLayout
+	resources        *resourcesRegistry
+	contents         *resourceContents
+	bundles          *bundleResources
+	anchors          *anchorRegistry
 	rwLock           sync.RWMutex
 }
 
@@ -47,6 +52,9 @@ type linkResolver struct {
 	*nodeContentProcessor
 	node   *api.Node
 	source string
+	// ctx and reader read the linked resources content, if needed
+	ctx    context.Context
+	reader Reader
 }
 
 // linkInfo defines a markdown link
@@ -56,6 +64,8 @@ type linkInfo struct {
 	destination         string
 	destinationNode     *api.Node
 	isEmbeddable        bool
+	// resourcePath is the path of a downloaded resource relative to the documentation root
+	resourcePath string
 }
 
 // docContent defines a document content
@@ -63,6 +73,8 @@ type docContent struct {
 	docAst ast.Node
 	docCnt []byte
 	docURI string
+	// separated is true if the content starts with a generated heading or separator
+	separated bool
 }
 
 // used in Hugo mode
@@ -78,19 +90,47 @@ type NodeContentProcessor interface {
 	Prepare(docStructure []*api.Node)
 	// Process node content and write the result in a buffer
 	Process(ctx context.Context, buffer *bytes.Buffer, reader Reader, node *api.Node) error
+	// BrokenAnchors returns the links to missing anchors of the processed documents per source document
+	BrokenAnchors() map[string][]string
+	// ResourcesMapping returns the paths of the downloaded resources per resource URL
+	ResourcesMapping() map[string][]string
+}
+
+// ContentProcessorOptions are the options of the documents content processing
+type ContentProcessorOptions struct {
+	// ResourcesRoot specifies the root location for downloaded resource.
+	// It is used to rewrite resource links in documents to relative paths.
+	ResourcesRoot string
+	// Hugo is the Hugo configuration, Hugo is disabled if nil
+	Hugo *Hugo
+	// MkDocs is the MkDocs configuration, MkDocs is disabled if nil
+	MkDocs *MkDocs
+	// DownloadPolicy decides which absolute links to embeddable resources are downloaded
+	DownloadPolicy *DownloadPolicy
+	// ResourcesLayout configures the naming and placement of the downloaded resources
+	ResourcesLayout *ResourcesLayout
 }
 
 // NewNodeContentProcessor creates NodeContentProcessor objects
-func NewNodeContentProcessor(resourcesRoot string, downloadJob DownloadScheduler, validator Validator, rh resourcehandlers.Registry, hugo *Hugo) NodeContentProcessor {
+func NewNodeContentProcessor(downloadJob DownloadScheduler, validator Validator, rh resourcehandlers.Registry, o *ContentProcessorOptions) NodeContentProcessor {
+	hugo := o.Hugo
+	if hugo == nil {
+		hugo = &Hugo{}
+	}
 	c := &nodeContentProcessor{
-		// resourcesRoot specifies the root location for downloaded resource.
-		// It is used to rewrite resource links in documents to relative paths.
-		resourcesRoot:    resourcesRoot,
+		resourcesRoot:    o.ResourcesRoot,
 		downloader:       downloadJob,
 		validator:        validator,
 		resourceHandlers: rh,
 		hugo:             hugo,
+		mkdocs:           o.MkDocs,
+		downloadPolicy:   o.DownloadPolicy,
+		resourcesLayout:  o.ResourcesLayout,
 		sourceLocations:  make(map[string][]*api.Node),
+		resources:        newResourcesRegistry(),
+		contents:         &resourceContents{},
+		bundles:          newBundleResources(),
+		anchors:          newAnchorRegistry(),
 	}
 	return c
 }
@@ -103,18 +143,30 @@ func (c *nodeContentProcessor) Prepare(structure []*api.Node) {
 	for _, node := range structure {
 		c.addSourceLocation(node)
 	}
+	c.structure = append(c.structure, structure...)
+	c.bundles.nodes = append(c.bundles.nodes, structure...)
 }
 
 func (c *nodeContentProcessor) Process(ctx context.Context, b *bytes.Buffer, r Reader, n *api.Node) error {
+	if c.pageBundles() {
+		c.scanBundleResources(ctx, r)
+	}
 	// api.Node content by priority
 	var nc []*docContent
 	nFullName := n.FullName("/")
 	// 1. Process Source
 	if len(n.Source) > 0 {
 		if dc := getCachedContent(n); dc != nil {
+			// the content read when filtering the node selector documents
+			uri, fragment := splitFragment(n.Source)
+			dc.docURI = uri
+			if err := c.processContent(ctx, r, n, dc, fragment, "source "+n.Source); err != nil {
+				return err
+			}
 			nc = append(nc, dc)
 		} else {
-			source, err := r.Read(ctx, n.Source)
+			uri, fragment := splitFragment(n.Source)
+			source, err := c.readSource(ctx, r, n, uri)
 			if err != nil {
 				if resourceNotFound, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
 					klog.Warningf("reading source %s from node %s failed: %s\n", n.Source, nFullName, resourceNotFound)
@@ -122,12 +174,20 @@ func (c *nodeContentProcessor) Process(ctx context.Context, b *bytes.Buffer, r R
 					return fmt.Errorf("reading source %s from node %s failed: %w", n.Source, nFullName, err)
 				}
 			}
-			if len(source) > 0 {
-				dc = &docContent{docCnt: source, docURI: n.Source}
+			if lang, ok := codeLanguage(uri); ok && len(source) > 0 {
+				if dc, err = codeContent(source, uri, fragment, lang); err != nil {
+					return fmt.Errorf("fail to extract source %s from node %s: %w", n.Source, nFullName, err)
+				}
+				nc = append(nc, dc)
+			} else if len(source) > 0 {
+				dc = &docContent{docCnt: source, docURI: uri}
 				dc.docAst, err = markdown.Parse(source)
 				if err != nil {
 					return fmt.Errorf("fail to parse source %s from node %s: %w", n.Source, nFullName, err)
 				}
+				if err = c.processContent(ctx, r, n, dc, fragment, "source "+n.Source); err != nil {
+					return err
+				}
 				nc = append(nc, dc)
 			} else if err == nil {
 				klog.Warningf("no content read from node %s source %s\n", nFullName, n.Source)
@@ -136,8 +196,10 @@ func (c *nodeContentProcessor) Process(ctx context.Context, b *bytes.Buffer, r R
 	}
 	// 2. Process MultiSource
 	if len(n.MultiSource) > 0 {
-		for i, src := range n.MultiSource {
-			source, err := r.Read(ctx, src)
+		for i, ms := range n.MultiSource {
+			src := ms.Source
+			uri, fragment := splitFragment(src)
+			source, err := c.readSource(ctx, r, n, uri)
 			if err != nil {
 				if resourceNotFound, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
 					klog.Warningf("reading multiSource[%d] %s from node %s failed: %s\n", i, src, nFullName, resourceNotFound)
@@ -145,18 +207,45 @@ func (c *nodeContentProcessor) Process(ctx context.Context, b *bytes.Buffer, r R
 					return fmt.Errorf("reading multiSource[%d] %s from node %s failed: %w", i, src, nFullName, err)
 				}
 			}
-			if len(source) > 0 {
-				dc := &docContent{docCnt: source, docURI: src}
+			if lang, ok := codeLanguage(uri); ok && len(source) > 0 {
+				dc, err := codeContent(source, uri, fragment, lang)
+				if err != nil {
+					return fmt.Errorf("fail to extract multiSource[%d] %s from node %s: %w", i, src, nFullName, err)
+				}
+				adaptHeadings(dc, ms)
+				nc = append(nc, dc)
+			} else if len(source) > 0 {
+				dc := &docContent{docCnt: source, docURI: uri}
 				dc.docAst, err = markdown.Parse(source)
 				if err != nil {
 					return fmt.Errorf("fail to parse multiSource[%d] %s from node %s: %w", i, src, nFullName, err)
 				}
+				if err = c.processContent(ctx, r, n, dc, fragment, fmt.Sprintf("multiSource[%d] %s", i, src)); err != nil {
+					return err
+				}
+				adaptHeadings(dc, ms)
 				nc = append(nc, dc)
 			} else if err == nil {
 				klog.Warningf("no content read from node %s multiSource[%d] %s\n", nFullName, i, src)
 			}
 		}
 	}
+	// 3. Process inline Content
+	if len(n.Content) > 0 {
+		// inline content has no source URI, its links are resolved in the node structure
+		dc := &docContent{docCnt: []byte(n.Content)}
+		var err error
+		if dc.docAst, err = markdown.Parse(dc.docCnt); err != nil {
+			return fmt.Errorf("fail to parse content from node %s: %w", nFullName, err)
+		}
+		if err = c.embedCode(ctx, r, dc); err != nil {
+			return fmt.Errorf("fail to embed code in content from node %s: %w", nFullName, err)
+		}
+		if err = c.includeContent(ctx, r, n, dc, nil); err != nil {
+			return fmt.Errorf("fail to resolve includes in content from node %s: %w", nFullName, err)
+		}
+		nc = append(nc, dc)
+	}
 	// if no content -> return
 	if len(nc) == 0 {
 		klog.Warningf("empty content for node %s\n", nFullName)
@@ -175,26 +264,41 @@ func (c *nodeContentProcessor) Process(ctx context.Context, b *bytes.Buffer, r R
 		return err
 	}
 	// 2. - write node content
+	start := b.Len()
 	for _, cnt := range nc {
-		rnd := c.getRenderer(n, cnt.docURI)
+		if cnt.separated && b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		rnd := c.getRenderer(ctx, r, n, cnt.docURI)
 		if err := rnd.Render(b, cnt.docCnt, cnt.docAst); err != nil {
 			return err
 		}
 	}
-	return nil
+	return c.anchors.addAnchors(n, b.Bytes()[start:])
+}
+
+func (c *nodeContentProcessor) BrokenAnchors() map[string][]string {
+	return c.anchors.brokenAnchors()
+}
+
+func (c *nodeContentProcessor) ResourcesMapping() map[string][]string {
+	return c.resources.resourcesMapping()
 }
 
 func (c *nodeContentProcessor) addSourceLocation(node *api.Node) {
 	if node.Source != "" {
-		c.sourceLocations[node.Source] = append(c.sourceLocations[node.Source], node)
+		key := c.sourceKey(node.Source)
+		c.sourceLocations[key] = append(c.sourceLocations[key], node)
 	} else if len(node.MultiSource) > 0 {
-		for _, s := range node.MultiSource {
-			c.sourceLocations[s] = append(c.sourceLocations[s], node)
+		for _, ms := range node.MultiSource {
+			key := c.sourceKey(ms.Source)
+			c.sourceLocations[key] = append(c.sourceLocations[key], node)
 		}
 	} else if len(node.Properties) > 0 {
 		if val, found := node.Properties[api.ContainerNodeSourceLocation]; found {
 			if sl, ok := val.(string); ok {
-				c.sourceLocations[sl] = append(c.sourceLocations[sl], node)
+				key := c.sourceKey(sl)
+				c.sourceLocations[key] = append(c.sourceLocations[key], node)
 				delete(node.Properties, api.ContainerNodeSourceLocation)
 			}
 		}
@@ -204,16 +308,80 @@ func (c *nodeContentProcessor) addSourceLocation(node *api.Node) {
 	}
 }
 
-func (c *nodeContentProcessor) getRenderer(n *api.Node, sourceURI string) renderer.Renderer {
-	lr := c.newLinkResolver(n, sourceURI)
+// sourceKey returns the source location key of a source URI, i.e. the URI without query & fragment,
+// or the key built by its resource handler, see resourcehandlers.SourceKeyer
+func (c *nodeContentProcessor) sourceKey(source string) string {
+	return resourcehandlers.SourceKey(c.resourceHandlers.Get(source), source)
+}
+
+// splitFragment splits a source URI to the URI without fragment and the fragment that selects
+// a section (e.g. `#installation`) or a line range (e.g. `#L10-L20`) of the source document
+func splitFragment(source string) (string, string) {
+	if i := strings.LastIndex(source, "#"); i >= 0 {
+		return source[:i], source[i+1:]
+	}
+	return source, ""
+}
+
+// adaptHeadings applies the heading options of a multiSource item to its content
+func adaptHeadings(dc *docContent, ms api.MultiSourceItem) {
+	if ms.DropFirstHeading {
+		markdown.DropFirstHeading(dc.docAst)
+	}
+	markdown.ShiftHeadings(dc.docAst, ms.HeadingShift)
+	if ms.Heading != "" {
+		markdown.PrependHeading(dc.docAst, 1+ms.HeadingShift, ms.Heading)
+		dc.separated = true
+	}
+	if ms.Separator {
+		markdown.PrependThematicBreak(dc.docAst)
+		dc.separated = true
+	}
+}
+
+// processContent extracts the fragment, embeds the code and resolves the includes of the parsed source content
+func (c *nodeContentProcessor) processContent(ctx context.Context, r Reader, n *api.Node, dc *docContent, fragment string, source string) error {
+	nFullName := n.FullName("/")
+	if err := extractFragment(dc, fragment); err != nil {
+		return fmt.Errorf("fail to extract %s from node %s: %w", source, nFullName, err)
+	}
+	if err := c.embedCode(ctx, r, dc); err != nil {
+		return fmt.Errorf("fail to embed code in %s from node %s: %w", source, nFullName, err)
+	}
+	if err := c.includeContent(ctx, r, n, dc, []string{includeKey(dc.docURI, fragment)}); err != nil {
+		return fmt.Errorf("fail to resolve includes in %s from node %s: %w", source, nFullName, err)
+	}
+	return nil
+}
+
+// readSource reads a source of the node, the sources read when scanning the page bundle resources are reused
+func (c *nodeContentProcessor) readSource(ctx context.Context, r Reader, n *api.Node, uri string) ([]byte, error) {
+	if source, ok := c.bundles.source(n, uri); ok {
+		return source, nil
+	}
+	return r.Read(ctx, uri)
+}
+
+// extractFragment cuts the document content to the fragment, if any
+func extractFragment(dc *docContent, fragment string) error {
+	if fragment == "" {
+		return nil
+	}
+	return markdown.ExtractFragment(dc.docAst, dc.docCnt, fragment)
+}
+
+func (c *nodeContentProcessor) getRenderer(ctx context.Context, r Reader, n *api.Node, sourceURI string) renderer.Renderer {
+	lr := c.newLinkResolver(ctx, r, n, sourceURI)
 	return markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.resolveLink))
 }
 
-func (c *nodeContentProcessor) newLinkResolver(node *api.Node, sourceURI string) *linkResolver {
+func (c *nodeContentProcessor) newLinkResolver(ctx context.Context, r Reader, node *api.Node, sourceURI string) *linkResolver {
 	return &linkResolver{
 		nodeContentProcessor: c,
 		node:                 node,
 		source:               sourceURI,
+		ctx:                  ctx,
+		reader:               r,
 	}
 }
 
@@ -281,6 +449,7 @@ func (l *linkResolver) resolveLink(dest string, isEmbeddable bool) (string, erro
 	if err = l.resolveBaseLink(link); err != nil {
 		return "", err
 	}
+	l.addAnchorLink(link)
 	if isEmbeddable {
 		if err = l.rawLink(link); err != nil {
 			return link.destination, err
@@ -288,26 +457,65 @@ func (l *linkResolver) resolveLink(dest string, isEmbeddable bool) (string, erro
 	}
 	if l.hugo.Enabled {
 		err = l.rewriteDestination(link)
+	} else if l.mkdocs.enabled() {
+		l.rewriteMkDocsDestination(link)
 	}
 	return link.destination, err
 }
 
+// addAnchorLink registers the links to anchors of the same document or of other document nodes for validation
+func (l *linkResolver) addAnchorLink(link *linkInfo) {
+	if link.isEmbeddable || link.URL.Fragment == "" {
+		return
+	}
+	node := link.destinationNode
+	if strings.HasPrefix(link.originalDestination, "#") {
+		node = l.node
+	}
+	if node == nil {
+		return
+	}
+	source := l.source
+	if source == "" {
+		source = l.node.FullName("/")
+	}
+	l.anchors.addLink(&anchorLink{
+		source:      source,
+		destination: link.originalDestination,
+		node:        node,
+		anchor:      link.URL.Fragment,
+	})
+}
+
 // resolve base link
 func (l *linkResolver) resolveBaseLink(link *linkInfo) error {
 	var err error
 	if strings.HasPrefix(link.destination, "#") || strings.HasPrefix(link.destination, "mailto:") {
 		return nil
 	}
+	// links from inline content are relative to the node in the structure
+	if l.source == "" && !link.URL.IsAbs() {
+		link.destinationNode = findVisibleNode(l.findNodeByRelativePath(l.node, link.URL.Path))
+		return nil
+	}
 	// build absolute link
 	var absLink string
 	if link.URL.IsAbs() {
 		// can we handle changes to this destination?
-		if l.resourceHandlers.Get(link.destination) == nil {
+		handler := l.resourceHandlers.Get(link.destination)
+		if handler == nil {
 			// we don't have a handler for it. Leave it be.
 			l.validator.ValidateLink(link.URL, link.destination, l.source)
 			return nil
 		}
 		absLink = link.destination
+		// pin the ref before looking up the source locations, as the node sources are pinned too
+		if p, ok := handler.(resourcehandlers.LinkPinner); ok {
+			if absLink, err = p.Pin(link.destination); err != nil {
+				klog.Warningf("failed to pin link %s from source %s: %v\n", link.destination, l.source, err)
+				absLink = link.destination
+			}
+		}
 	} else {
 		handler := l.resourceHandlers.Get(l.source) // handler must exist because source content has been read
 		// build absolute path for the destination using content source path as base
@@ -325,8 +533,9 @@ func (l *linkResolver) resolveBaseLink(link *linkInfo) error {
 	}
 	absURL, _ := url.Parse(absLink) // absLink should be valid URL
 	key := fmt.Sprintf("%s://%s%s", absURL.Scheme, absURL.Host, absURL.Path)
+	srcKey := l.sourceKey(absLink)
 	// Links to other documents are enforced relative when linking documents from the node structure.
-	if nl, ok := l.getNodesBySource(strings.TrimSuffix(key, "/")); ok {
+	if nl, ok := l.getNodesBySource(strings.TrimSuffix(srcKey, "/")); ok {
 		// found nodes with this source -> find the shortest path from l.node to one of nodes
 		nPath := ""
 		for _, n := range nl {
@@ -340,7 +549,8 @@ func (l *linkResolver) resolveBaseLink(link *linkInfo) error {
 			}
 		}
 		if link.destinationNode != nil { // i.e. visible destination node found
-			if link.URL.ForceQuery || link.URL.RawQuery != "" {
+			// the query is part of the source key, if it carries the ref
+			if (link.URL.ForceQuery || link.URL.RawQuery != "") && srcKey == key {
 				nPath = fmt.Sprintf("%s?%s", nPath, link.URL.RawQuery)
 			}
 			if link.URL.Fragment != "" {
@@ -354,27 +564,34 @@ func (l *linkResolver) resolveBaseLink(link *linkInfo) error {
 		}
 	}
 	// Links to resources that are not structure document nodes are scheduled for download and their destination is updated to relative path to predefined location for resources.
-	if link.isEmbeddable && downloadEmbeddable(link.URL) {
-		hash := md5.Sum([]byte(key)) // hash based on absolute link, but without query & fragment to avoid duplications
-		ext := path.Ext(link.URL.Path)
-		downloadResourceName := "$name_$hash$ext"
-		downloadResourceName = strings.ReplaceAll(downloadResourceName, "$name", strings.TrimSuffix(path.Base(link.URL.Path), ext))
-		downloadResourceName = strings.ReplaceAll(downloadResourceName, "$hash", hex.EncodeToString(hash[:])[:6])
-		downloadResourceName = strings.ReplaceAll(downloadResourceName, "$ext", ext)
-		resLocation := buildDownloadDestination(l.node, downloadResourceName, l.resourcesRoot)
-		if link.destination != resLocation {
-			klog.V(6).Infof("[%s] %s -> %s\n", l.source, link.destination, resLocation)
-			link.destination = resLocation
-		}
-		if err = l.downloader.Schedule(&DownloadTask{
-			absLink,
-			downloadResourceName,
-			l.source,
-			link.destination,
-		}); err != nil {
-			return err
+	if link.isEmbeddable {
+		if download, rule := l.downloadPolicy.download(link.URL, l.node); download {
+			klog.V(6).Infof("[%s] downloading %s: %s\n", l.source, absLink, rule)
+			hash, content, err := l.readResource(key, absLink)
+			if err != nil {
+				klog.Warningf("reading resource %s from source %s failed: %v\n", absLink, l.source, err)
+			} else {
+				var download bool
+				placement := l.placement(key)
+				link.resourcePath, download = l.resources.store(absLink, l.resourceScope(placement), hash, l.resourcePath(l.resourceName(absURL, hash), placement))
+				resLocation := l.resourceLink(link.resourcePath)
+				if link.destination != resLocation {
+					klog.V(6).Infof("[%s] %s -> %s\n", l.source, link.destination, resLocation)
+					link.destination = resLocation
+				}
+				if !download {
+					klog.V(6).Infof("[%s] resource %s already stored as %s\n", l.source, absLink, link.resourcePath)
+					return nil
+				}
+				return l.downloader.Schedule(&DownloadTask{
+					Source:    absLink,
+					Target:    link.resourcePath,
+					Referer:   l.source,
+					Reference: link.destination,
+					Content:   content,
+				})
+			}
 		}
-		return nil
 	}
 	// Rewrite with absolute link
 	if link.destination != absLink {
@@ -387,6 +604,22 @@ func (l *linkResolver) resolveBaseLink(link *linkInfo) error {
 	return nil
 }
 
+// readResource returns the hash identifying the resource with the key and the absolute link, see resourceHash.
+// The resource is read once while resolving the links to it, so that resources with the same content are stored once.
+// Resources that can't be read for other reasons than not found are identified by their key, so that their download
+// is retried by the download workers.
+func (l *linkResolver) readResource(key string, absLink string) (string, []byte, error) {
+	content, err := l.contents.read(l.ctx, l.reader, absLink)
+	if err != nil {
+		if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+			return "", nil, err
+		}
+		klog.Warningf("reading resource %s from source %s failed, it is named by its URL: %v\n", absLink, l.source, err)
+		return resourceHash(key, nil), nil, nil
+	}
+	return resourceHash(key, content), content, nil
+}
+
 // rewrite abs links to embedded objects to their raw link format if necessary, to ensure they are embeddable
 func (l *linkResolver) rawLink(link *linkInfo) error {
 	u, err := url.Parse(link.destination)
@@ -432,6 +665,10 @@ func (l *linkResolver) rewriteDestination(link *linkInfo) error {
 				dnPath = dnPath[:len(dnPath)-3]
 			}
 			dnPath = strings.TrimSuffix(dnPath, "_index")
+			if l.pageBundles() && (dnPath == "index" || strings.HasSuffix(dnPath, "/index")) {
+				// leaf bundle
+				dnPath = strings.TrimSuffix(dnPath, "index")
+			}
 			if !strings.HasSuffix(dnPath, "/") {
 				dnPath = fmt.Sprintf("%s/", dnPath)
 			}
@@ -464,6 +701,12 @@ func (l *linkResolver) rewriteDestination(link *linkInfo) error {
 				klog.V(6).Infof("[%s] %s -> %s\n", l.source, link.destination, dnPath)
 				link.destination = dnPath
 			}
+		} else if link.isEmbeddable && link.resourcePath != "" {
+			ePath := fmt.Sprintf("%s/%s", l.hugo.BaseURL, link.resourcePath)
+			if link.destination != ePath {
+				klog.V(6).Infof("[%s] %s -> %s\n", l.source, link.destination, ePath)
+				link.destination = ePath
+			}
 		} else if link.isEmbeddable {
 			ePath := link.destination
 			for strings.HasPrefix(ePath, "../") {
@@ -486,21 +729,6 @@ func (l *linkResolver) getNodesBySource(source string) ([]*api.Node, bool) {
 	return nl, ok
 }
 
-func downloadEmbeddable(u *url.URL) bool {
-	if !u.IsAbs() { // download all relative embeddable links
-		return true
-	}
-	// if embeddable link is absolute, download only if belongs to internal GitHub or own organization
-	// TODO: make it configurable
-	if u.Host == "github.tools.sap" || u.Host == "raw.github.tools.sap" || u.Host == "github.wdf.sap.corp" {
-		return true
-	}
-	if strings.HasPrefix(u.Path, "/gardener/") {
-		return u.Host == "github.com" || u.Host == "raw.githubusercontent.com"
-	}
-	return false
-}
-
 // findVisibleNode returns
 // - the node if it is a document api.Node
 // - first container node that contains index file if the api.Node is container
@@ -518,6 +746,49 @@ func findVisibleNode(n *api.Node) *api.Node {
 	return findVisibleNode(n.Parent())
 }
 
+// findNodeByRelativePath returns the node with the relative path p from node n, e.g. `../guides/install.md`,
+// or nil if no such node is found. The top-level nodes have no parent, their relative paths start from the
+// structure root.
+func (c *nodeContentProcessor) findNodeByRelativePath(n *api.Node, p string) *api.Node {
+	c.rwLock.RLock()
+	root := &api.Node{Nodes: c.structure}
+	c.rwLock.RUnlock()
+	parent := func(node *api.Node) *api.Node {
+		if node == root {
+			return nil
+		}
+		if node.Parent() == nil {
+			return root
+		}
+		return node.Parent()
+	}
+	current := parent(n)
+	for _, name := range strings.Split(strings.TrimSuffix(p, "/"), "/") {
+		if current == nil {
+			return nil
+		}
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			current = parent(current)
+			continue
+		}
+		var child *api.Node
+		for _, cn := range current.Nodes {
+			if cn.Name == name {
+				child = cn
+				break
+			}
+		}
+		current = child
+	}
+	if current == parent(n) || current == root {
+		return nil
+	}
+	return current
+}
+
 func swapPaths(path string, newPath string) bool {
 	if path == "" {
 		return true
@@ -530,24 +801,6 @@ func swapPaths(path string, newPath string) bool {
 	return strings.Count(path, "/") > strings.Count(newPath, "/")
 }
 
-// Builds destination path for links from node to resource in root path
-// If root is not specified as document root (with leading "/"), the
-// returned destinations are relative paths from the node to the resource
-// in root, e.g. "../../__resources/image.png", where root is "__resources".
-// If root is document root path, destinations are paths from the root,
-// e.g. "/__resources/image.png", where root is "/__resources".
-func buildDownloadDestination(node *api.Node, resourceName, root string) string {
-	if strings.HasPrefix(root, "/") {
-		return root + "/" + resourceName
-	}
-	resourceRelPath := fmt.Sprintf("%s/%s", root, resourceName)
-	parentsSize := len(node.Parents())
-	for ; parentsSize > 1; parentsSize-- {
-		resourceRelPath = "../" + resourceRelPath
-	}
-	return resourceRelPath
-}
-
 ///////////// frontmatter processor ////////
 
 func (f *frontmatterProcessor) processFrontmatter(docFrontmatter map[string]interface{}) (map[string]interface{}, error) {
@@ -592,7 +845,12 @@ func (f *frontmatterProcessor) getNodeTitle() string {
 	if f.node.Parent() != nil && f.nodeIsIndexFile(f.node.Name) {
 		title = f.node.Parent().Name
 	}
-	title = strings.TrimSuffix(title, ".md")
+	return nameToTitle(title)
+}
+
+// nameToTitle normalizes a node name as a title
+func nameToTitle(name string) string {
+	title := strings.TrimSuffix(name, ".md")
 	title = strings.ReplaceAll(title, "_", " ")
 	title = strings.ReplaceAll(title, "-", " ")
 	title = strings.Title(title)
@@ -5,7 +5,10 @@
 package reactor
 
 import (
+	"bytes"
+	"context"
 	"net/url"
+	"path"
 	"strings"
 	"testing"
 
@@ -27,6 +30,20 @@ func Test_processLink(t *testing.T) {
 	}
 	nodeA.Nodes = []*api.Node{nodeB}
 	nodeA.SetParentsDownwards()
+	// versioned structure
+	v1Doc := &api.Node{Name: "overview.md", Source: "https://github.com/gardener/gardener/blob/release-v1.10/docs/overview.md"}
+	v1Shared := &api.Node{Name: "shared.md", Source: "https://github.com/gardener/documentation/blob/master/shared.md"}
+	v2Doc := &api.Node{Name: "overview.md", Source: "https://github.com/gardener/gardener/blob/release-v1.11/docs/overview.md"}
+	v2Shared := &api.Node{Name: "shared.md", Source: v1Shared.Source}
+	v1 := &api.Node{Name: "v1.10", Nodes: []*api.Node{{Name: "docs", Nodes: []*api.Node{v1Doc, v1Shared}}}}
+	v2 := &api.Node{Name: "v1.11", Nodes: []*api.Node{{Name: "docs", Nodes: []*api.Node{v2Doc, v2Shared}}}}
+	v1.SetParentsDownwards()
+	v2.SetParentsDownwards()
+	versionedSources := map[string][]*api.Node{
+		v1Doc.Source:    {v1Doc},
+		v2Doc.Source:    {v2Doc},
+		v1Shared.Source: {v2Shared, v1Shared},
+	}
 
 	testCases := []struct {
 		name              string
@@ -158,6 +175,38 @@ func Test_processLink(t *testing.T) {
 			wantErr:           nil,
 			sourceLocations:   map[string][]*api.Node{nodeA.Source: {nodeA}, nodeB.Source: {nodeB}},
 		},
+		{
+			name:              "Absolute link to document pinned by the handler",
+			node:              nodeA,
+			destination:       "https://github.com/gardener/gardener/blob/master/docs/extensions/overview.md",
+			contentSourcePath: nodeA.Source,
+			wantDestination:   "./node_B.md",
+			wantErr:           nil,
+			sourceLocations:   map[string][]*api.Node{nodeA.Source: {nodeA}, nodeB.Source: {nodeB}},
+			mutate: func(c *nodeContentProcessor) {
+				h := &pinningHandler{&resourcehandlersfakes.FakeResourceHandler{}}
+				h.AcceptReturns(true)
+				c.resourceHandlers = resourcehandlers.NewRegistry(h)
+			},
+		},
+		{
+			name:              "Absolute link to document of another version",
+			node:              v1Doc,
+			destination:       v2Doc.Source + "#install",
+			contentSourcePath: v1Doc.Source,
+			wantDestination:   "../../v1.11/docs/overview.md#install",
+			wantErr:           nil,
+			sourceLocations:   versionedSources,
+		},
+		{
+			name:              "Absolute link to document in multiple versions",
+			node:              v1Doc,
+			destination:       v1Shared.Source,
+			contentSourcePath: v1Doc.Source,
+			wantDestination:   "./shared.md",
+			wantErr:           nil,
+			sourceLocations:   versionedSources,
+		},
 		{
 			name:              "Relative link to document NOT in download scope and NOT from structure",
 			node:              nodeA,
@@ -207,6 +256,9 @@ func Test_processLink(t *testing.T) {
 				resourceHandlers: resourcehandlers.NewRegistry(frh),
 				validator:        &fakeValidator{},
 				downloader:       &fakeDownload{},
+				downloadPolicy:   &DownloadPolicy{Allow: DefaultDownloadAllow},
+				resources:        newResourcesRegistry(),
+				contents:         &resourceContents{},
 				sourceLocations:  tc.sourceLocations,
 			}
 			if tc.mutate != nil {
@@ -216,6 +268,8 @@ func Test_processLink(t *testing.T) {
 				nodeContentProcessor: c,
 				node:                 tc.node,
 				source:               tc.contentSourcePath,
+				ctx:                  context.TODO(),
+				reader:               fakeReader{},
 			}
 			u, _ := url.Parse(tc.destination)
 			link := &linkInfo{
@@ -244,6 +298,161 @@ func Test_processLink(t *testing.T) {
 	}
 }
 
+func Test_processInlineContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		hugo     *Hugo
+		topLevel bool
+		want     string
+	}{
+		{
+			name: "links are kept",
+			hugo: &Hugo{},
+			want: "- [Install](./install.md)\n- [Guides](./guides/_index.md)\n- [Missing](./missing.md)\n",
+		},
+		{
+			name: "links are rewritten in Hugo mode",
+			hugo: &Hugo{Enabled: true, PrettyURLs: true},
+			want: "---\ntitle: Docs\n---\n\n- [Install](/docs/install/)\n- [Guides](/docs/guides/)\n- [Missing](./missing.md)\n",
+		},
+		{
+			name:     "top-level links are rewritten in Hugo mode",
+			hugo:     &Hugo{Enabled: true, PrettyURLs: true},
+			topLevel: true,
+			want:     "---\ntitle: ' Index'\n---\n\n- [Install](/install/)\n- [Guides](/guides/)\n- [Missing](./missing.md)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &api.Node{Name: "_index.md", Content: "- [Install](./install.md)\n- [Guides](./guides/_index.md)\n- [Missing](./missing.md)\n"}
+			docs := &api.Node{Name: "docs", Nodes: []*api.Node{
+				index,
+				{Name: "install.md", Source: "https://github.com/gardener/docforge/blob/master/docs/install.md"},
+				{Name: "guides", Nodes: []*api.Node{{Name: "_index.md", Content: "# Guides"}}},
+			}}
+			docs.SetParentsDownwards()
+			c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(), &ContentProcessorOptions{ResourcesRoot: "/__resources", Hugo: tt.hugo})
+			if tt.topLevel {
+				// the top-level nodes have no parent, as resolved by api.ResolveManifest
+				for _, n := range docs.Nodes {
+					n.SetParent(nil)
+				}
+				c.Prepare(docs.Nodes)
+			}
+			var b bytes.Buffer
+			err := c.Process(context.TODO(), &b, nil, index)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func Test_processRefQueryLinks(t *testing.T) {
+	doc := "https://bitbucket.example.com/projects/P/repos/docs/browse/overview.md"
+	v1Doc := &api.Node{Name: "overview.md", Source: doc + "?at=v1"}
+	v2Doc := &api.Node{Name: "overview.md", Source: doc + "?at=v2"}
+	structure := []*api.Node{
+		{Name: "v1", Nodes: []*api.Node{v1Doc}},
+		{Name: "v2", Nodes: []*api.Node{v2Doc}},
+	}
+	for _, n := range structure {
+		n.SetParentsDownwards()
+	}
+	r := fakeReader{v1Doc.Source: []byte("See [v2](" + v2Doc.Source + "#usage) and [v1](" + v1Doc.Source + ").\n")}
+	h := &refQueryHandler{fakeLinkHandler()}
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+	c.Prepare(structure)
+	var b bytes.Buffer
+	assert.NoError(t, c.Process(context.TODO(), &b, r, v1Doc))
+	assert.Equal(t, "See [v2](../v2/overview.md#usage) and [v1](overview.md).\n", b.String())
+}
+
+func Test_processSourceFragment(t *testing.T) {
+	readme := "https://github.com/gardener/docforge/blob/master/README.md"
+	r := fakeReader{readme: []byte("---\ntitle: Docforge\n---\n# Docforge\n\n## Installation\n\nSee [releases](#releases).\n\n## Usage\n\nRun it.\n")}
+	tests := []struct {
+		name    string
+		node    *api.Node
+		want    string
+		wantErr string
+	}{
+		{
+			name: "source section",
+			node: &api.Node{Name: "install.md", Source: readme + "#installation"},
+			want: "---\ntitle: Docforge\n---\n\n## Installation\n\nSee [releases](#releases).\n",
+		},
+		{
+			name: "multiSource sections and line ranges",
+			node: &api.Node{Name: "overview.md", MultiSource: []api.MultiSourceItem{{Source: readme + "#L4"}, {Source: readme + "#usage"}}},
+			want: "---\ntitle: Docforge\n---\n\n# Docforge\n## Usage\n\nRun it.\n",
+		},
+		{
+			name: "multiSource heading options",
+			node: &api.Node{Name: "overview.md", MultiSource: []api.MultiSourceItem{
+				{Source: readme + "#L4"},
+				{Source: readme + "#usage", HeadingShift: -1, DropFirstHeading: true, Heading: "How to use", Separator: true},
+			}},
+			want: "---\ntitle: Docforge\n---\n\n# Docforge\n\n---\n\n# How to use\n\nRun it.\n",
+		},
+		{
+			name:    "missing section",
+			node:    &api.Node{Name: "install.md", Source: readme + "#uninstall"},
+			wantErr: "fail to extract source " + readme + "#uninstall from node /install.md: section #uninstall not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+			var b bytes.Buffer
+			err := c.Process(context.TODO(), &b, r, tt.node)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+// pinningHandler pins the links with master ref to v1.10.0
+type pinningHandler struct {
+	*resourcehandlersfakes.FakeResourceHandler
+}
+
+func (h *pinningHandler) Pin(link string) (string, error) {
+	return strings.Replace(link, "/master/", "/v1.10.0/", 1), nil
+}
+
+// refQueryHandler keeps the query carrying the ref in the source keys, like the Bitbucket handler
+type refQueryHandler struct {
+	*resourcehandlersfakes.FakeResourceHandler
+}
+
+func (h *refQueryHandler) SourceKey(uri string) string {
+	return strings.Split(uri, "#")[0]
+}
+
+type fakeReader map[string][]byte
+
+func (f fakeReader) Read(_ context.Context, source string) ([]byte, error) {
+	return f[source], nil
+}
+
+// fakeLinkHandler returns a resource handler accepting all URLs, that resolves relative links against the source
+// URL and uses the absolute links as raw links
+func fakeLinkHandler() *resourcehandlersfakes.FakeResourceHandler {
+	h := &resourcehandlersfakes.FakeResourceHandler{}
+	h.AcceptReturns(true)
+	h.BuildAbsLinkStub = func(source, link string) (string, error) {
+		return "https://" + path.Join(path.Dir(strings.TrimPrefix(source, "https://")), link), nil
+	}
+	h.GetRawFormatLinkStub = func(absLink string) (string, error) {
+		return absLink, nil
+	}
+	return h
+}
+
 type fakeValidator struct{}
 
 func (f *fakeValidator) ValidateLink(_ *url.URL, _, _ string) bool {
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+)
+
+// DownloadProperty is the node property that overrides the download policy for the absolute links
+// to embeddable resources of the node documents and of its descendants, e.g. `download: false`
+const DownloadProperty = "download"
+
+// DefaultDownloadAllow are the default download patterns, i.e. the internal GitHub instances and the own organization
+var DefaultDownloadAllow = []string{
+	"github.tools.sap",
+	"raw.github.tools.sap",
+	"github.wdf.sap.corp",
+	"github.com/gardener/",
+	"raw.githubusercontent.com/gardener/",
+}
+
+// DownloadPolicy decides which absolute links to embeddable resources (e.g. images) are downloaded.
+// Relative links to embeddable resources are always downloaded.
+type DownloadPolicy struct {
+	// Allow are host patterns optionally followed by a path prefix, e.g. `*.github.tools.sap`
+	// or `github.com/gardener/`. The host patterns use the path.Match syntax.
+	Allow []string
+	// Include are regular expressions matching the URLs of downloaded resources
+	Include []*regexp.Regexp
+	// Exclude are regular expressions matching the URLs of resources that are not downloaded,
+	// they take precedence over Allow and Include
+	Exclude []*regexp.Regexp
+}
+
+// download checks if the embeddable resource linked by a node document is downloaded and returns the deciding rule.
+// The DefaultDownloadAllow patterns apply if there is no policy.
+func (p *DownloadPolicy) download(u *url.URL, node *api.Node) (bool, string) {
+	if !u.IsAbs() {
+		return true, "relative link"
+	}
+	for n := node; n != nil; n = n.Parent() {
+		if d, ok := n.Properties[DownloadProperty].(bool); ok {
+			return d, fmt.Sprintf("property %s: %t of node %s", DownloadProperty, d, n.FullName("/"))
+		}
+	}
+	if p == nil {
+		p = &DownloadPolicy{Allow: DefaultDownloadAllow}
+	}
+	link := u.String()
+	for _, e := range p.Exclude {
+		if e.MatchString(link) {
+			return false, fmt.Sprintf("exclude %s", e)
+		}
+	}
+	for _, i := range p.Include {
+		if i.MatchString(link) {
+			return true, fmt.Sprintf("include %s", i)
+		}
+	}
+	for _, a := range p.Allow {
+		if allowed(a, u) {
+			return true, fmt.Sprintf("allow %s", a)
+		}
+	}
+	return false, "no matching rule"
+}
+
+// allowed checks if the URL matches a host pattern optionally followed by a path prefix
+func allowed(pattern string, u *url.URL) bool {
+	host, prefix := pattern, ""
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		host, prefix = pattern[:i], pattern[i:]
+	}
+	if ok, err := path.Match(host, u.Host); err != nil || !ok {
+		return false
+	}
+	return strings.HasPrefix(u.Path, prefix)
+}
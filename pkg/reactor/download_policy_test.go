@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadPolicy_download(t *testing.T) {
+	policy := &DownloadPolicy{
+		Allow:   []string{"*.example.com", "github.com/gardener/"},
+		Include: []*regexp.Regexp{regexp.MustCompile(`^https://cdn\.io/.*\.svg$`)},
+		Exclude: []*regexp.Regexp{regexp.MustCompile(`/gardener/legacy/`)},
+	}
+	docs := &api.Node{Name: "docs", Properties: map[string]interface{}{DownloadProperty: false}}
+	doc := &api.Node{Name: "doc.md", Source: "https://github.com/gardener/docforge/blob/master/doc.md"}
+	hotLinked := &api.Node{Name: "hot.md", Source: "https://github.com/gardener/docforge/blob/master/hot.md"}
+	docs.Nodes = []*api.Node{hotLinked}
+	root := &api.Node{Name: "root", Nodes: []*api.Node{doc, docs}}
+	root.SetParentsDownwards()
+	tests := []struct {
+		name     string
+		policy   *DownloadPolicy
+		node     *api.Node
+		link     string
+		want     bool
+		wantRule string
+	}{
+		{
+			name:     "relative link",
+			policy:   policy,
+			node:     doc,
+			link:     "./image.png",
+			want:     true,
+			wantRule: "relative link",
+		},
+		{
+			name:     "host pattern",
+			policy:   policy,
+			node:     doc,
+			link:     "https://images.example.com/logo.png",
+			want:     true,
+			wantRule: "allow *.example.com",
+		},
+		{
+			name:     "host and path prefix",
+			policy:   policy,
+			node:     doc,
+			link:     "https://github.com/gardener/gardener/raw/master/logo.png",
+			want:     true,
+			wantRule: "allow github.com/gardener/",
+		},
+		{
+			name:     "other path",
+			policy:   policy,
+			node:     doc,
+			link:     "https://github.com/owner/repo/raw/master/logo.png",
+			want:     false,
+			wantRule: "no matching rule",
+		},
+		{
+			name:     "include",
+			policy:   policy,
+			node:     doc,
+			link:     "https://cdn.io/icons/logo.svg",
+			want:     true,
+			wantRule: `include ^https://cdn\.io/.*\.svg$`,
+		},
+		{
+			name:     "exclude",
+			policy:   policy,
+			node:     doc,
+			link:     "https://github.com/gardener/legacy/raw/master/logo.png",
+			want:     false,
+			wantRule: "exclude /gardener/legacy/",
+		},
+		{
+			name:     "node property",
+			policy:   policy,
+			node:     hotLinked,
+			link:     "https://github.com/gardener/gardener/raw/master/logo.png",
+			want:     false,
+			wantRule: "property download: false of node root/docs",
+		},
+		{
+			name:     "no policy",
+			node:     doc,
+			link:     "https://github.com/gardener/gardener/raw/master/logo.png",
+			want:     true,
+			wantRule: "allow github.com/gardener/",
+		},
+		{
+			name:     "no policy with other host",
+			node:     doc,
+			link:     "https://github.com/kubernetes/kubernetes/raw/master/logo.png",
+			want:     false,
+			wantRule: "no matching rule",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.link)
+			assert.NoError(t, err)
+			got, rule := tt.policy.download(u, tt.node)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantRule, rule)
+		})
+	}
+}
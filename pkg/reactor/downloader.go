@@ -12,6 +12,7 @@ import (
 	"github.com/gardener/docforge/pkg/resourcehandlers"
 	"github.com/gardener/docforge/pkg/writers"
 	"k8s.io/klog/v2"
+	"path"
 	"reflect"
 	"sync"
 )
@@ -49,6 +50,8 @@ type DownloadTask struct {
 	Target    string
 	Referer   string
 	Reference string
+	// Content is the content of the source, if it is already read
+	Content []byte
 }
 
 type downloadWorker struct {
@@ -58,8 +61,8 @@ type downloadWorker struct {
 	writer writers.Writer
 	// lock for accessing the downloadedResources map
 	mux sync.Mutex
-	// map with downloaded resources
-	downloadedResources map[string][]*DownloadTask
+	// map with downloaded resources by source and target
+	downloadedResources map[downloadKey][]*DownloadTask
 }
 
 func (d *downloadWorker) Download(ctx context.Context, task interface{}) error {
@@ -81,27 +84,42 @@ func (d *downloadWorker) Download(ctx context.Context, task interface{}) error {
 	return nil
 }
 
-// shouldDownload checks whether a download task for the same DownloadTask.Source is being processed
+// downloadKey identifies a downloaded resource, the same source can be downloaded to several targets
+// depending on the resources placement
+type downloadKey struct {
+	source string
+	target string
+}
+
+// shouldDownload checks whether a download task for the same DownloadTask.Source and DownloadTask.Target is being processed
 func (d *downloadWorker) shouldDownload(dt *DownloadTask) bool {
 	d.mux.Lock()
 	defer d.mux.Unlock()
-	if val, ok := d.downloadedResources[dt.Source]; ok {
-		// there is already a task for this source, so just append the current one
-		d.downloadedResources[dt.Source] = append(val, dt)
+	key := downloadKey{dt.Source, dt.Target}
+	if val, ok := d.downloadedResources[key]; ok {
+		// there is already a task for this source and target, so just append the current one
+		d.downloadedResources[key] = append(val, dt)
 		return false
 	}
 	// add the task and starts downloading
-	d.downloadedResources[dt.Source] = []*DownloadTask{dt}
+	d.downloadedResources[key] = []*DownloadTask{dt}
 	return true
 }
 
 func (d *downloadWorker) download(ctx context.Context, dt *DownloadTask) error {
 	klog.V(6).Infof("downloading %s as %s\n", dt.Source, dt.Target)
-	blob, err := d.reader.Read(ctx, dt.Source)
-	if err != nil {
-		return err
+	var err error
+	blob := dt.Content
+	if blob == nil {
+		if blob, err = d.reader.Read(ctx, dt.Source); err != nil {
+			return err
+		}
+	}
+	dir := path.Dir(dt.Target)
+	if dir == "." {
+		dir = ""
 	}
-	if err = d.writer.Write(dt.Target, "", blob, nil); err != nil {
+	if err = d.writer.Write(path.Base(dt.Target), dir, blob, nil); err != nil {
 		return err
 	}
 	return nil
@@ -118,7 +136,7 @@ func DownloadWorkFunc(reader Reader, writer writers.Writer) (jobs.WorkerFunc, er
 	dWorker := &downloadWorker{
 		reader:              reader,
 		writer:              writer,
-		downloadedResources: make(map[string][]*DownloadTask),
+		downloadedResources: make(map[downloadKey][]*DownloadTask),
 	}
 	return dWorker.Download, nil
 }
@@ -136,6 +136,21 @@ var _ = Describe("Downloader", func() {
 					Expect(err.Error()).To(ContainSubstring("fake_write_err"))
 				})
 			})
+			Context("content is already read", func() {
+				BeforeEach(func() {
+					task.(*reactor.DownloadTask).Target = "images/fake_target"
+					task.(*reactor.DownloadTask).Content = []byte("read content")
+				})
+				It("writes the content", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(reader.ReadCallCount()).To(Equal(0))
+					Expect(writer.WriteCallCount()).To(Equal(1))
+					name, path, content, _ := writer.WriteArgsForCall(0)
+					Expect(path).To(Equal("images"))
+					Expect(name).To(Equal("fake_target"))
+					Expect(string(content)).To(Equal("read content"))
+				})
+			})
 			When("source is already downloaded", func() {
 				JustBeforeEach(func() {
 					Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,315 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// dateLayouts are the supported layouts of front-matter dates compared by `$lt`, `$lte`, `$gt` and `$gte`
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// matchFrontMatterRule explores a parsed frontmatter object `data` to match rule
+// `value` at `path` pattern and return true on successful match or false
+// otherwise.
+// Path is an expression with a JSONPath-like simplified notation.
+// An object in path is modeled as dot (`.`). Paths start with the root object,
+// i.e. the most minimal path is `.`.
+// An object element value is referenced by its name (key) in the object map:
+// `.a.b.c` is path to element `c` in map `b` in map `a` in root object map.
+// Element values can be scalar, object maps or arrays.
+// An element in an array is referenced by its index: `.a.b[1]` references `b`
+// array element with index 1.
+// Paths can include wildcards: `*` models any object element, `[*]` any array
+// element and `**` any sequence of path nodes, including an empty one.
+// A `.a.**.c` models any path starting with `.a.` and ending with `.c`.
+//
+// A rule value that is a map of operators is evaluated on the elements matching
+// the path, otherwise the value must be equal to one of them. The operators are:
+// - `$eq`, `$ne`: equal or not equal to the operand
+// - `$in`, `$nin`: equal or not equal to any of the operand list items
+// - `$contains`: the array contains the operand, or the string contains the operand substring
+// - `$regex`: the string matches the operand regular expression
+// - `$exists`: an element matching the path exists (operand `true`) or not (operand `false`)
+// - `$lt`, `$lte`, `$gt`, `$gte`: compare numbers, dates (e.g. `2022-01-31`) or strings
+// - `$not`: the operand rule doesn't match
+// All operators in a map must match.
+func matchFrontMatterRule(path string, val interface{}, data interface{}) bool {
+	pattern := parsePath(path)
+	var values []interface{}
+	selectValues(pattern, nil, normalize(data), &values)
+	return matchValues(val, values)
+}
+
+// matchValues returns true if the rule matches the values selected by the path
+func matchValues(rule interface{}, values []interface{}) bool {
+	ops, ok := operators(rule)
+	if !ok {
+		rule = normalize(rule)
+		for _, v := range values {
+			if equal(rule, v) {
+				return true
+			}
+		}
+		return false
+	}
+	for op, operand := range ops {
+		var match bool
+		switch op {
+		case "$exists":
+			exists, _ := operand.(bool)
+			match = exists == (len(values) > 0)
+		case "$not":
+			match = !matchValues(operand, values)
+		default:
+			for _, v := range values {
+				if match = matchOperator(op, operand, v); match {
+					break
+				}
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOperator evaluates the operator on a front-matter value
+func matchOperator(op string, operand interface{}, v interface{}) bool {
+	operand = normalize(operand)
+	switch op {
+	case "$eq":
+		return equal(operand, v)
+	case "$ne":
+		return !equal(operand, v)
+	case "$in", "$nin":
+		l, ok := operand.([]interface{})
+		if !ok {
+			klog.Warningf("front-matter rule operator %s expects a list, got %v", op, operand)
+			return false
+		}
+		in := false
+		for _, o := range l {
+			if equal(o, v) {
+				in = true
+				break
+			}
+		}
+		return in == (op == "$in")
+	case "$contains":
+		switch dv := v.(type) {
+		case []interface{}:
+			for _, e := range dv {
+				if equal(operand, e) {
+					return true
+				}
+			}
+		case string:
+			return strings.Contains(dv, fmt.Sprint(operand))
+		}
+		return false
+	case "$regex":
+		re, err := regexp.Compile(fmt.Sprint(operand))
+		if err != nil {
+			klog.Warningf("invalid front-matter rule regular expression %v: %v", operand, err)
+			return false
+		}
+		s, ok := v.(string)
+		return ok && re.MatchString(s)
+	case "$lt", "$lte", "$gt", "$gte":
+		c, ok := compare(v, operand)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "$lt":
+			return c < 0
+		case "$lte":
+			return c <= 0
+		case "$gt":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	}
+	klog.Warningf("unknown front-matter rule operator %s", op)
+	return false
+}
+
+// operators returns the rule operators if the rule is a map of operators
+func operators(rule interface{}) (map[string]interface{}, bool) {
+	m, ok := normalize(rule).(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// parsePath splits path to segments, e.g. `.a.b[1]` to `a`, `b`, `[1]`
+func parsePath(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		for s != "" {
+			i := strings.Index(s[1:], "[")
+			if i < 0 {
+				segments = append(segments, s)
+				break
+			}
+			segments = append(segments, s[:i+1])
+			s = s[i+1:]
+		}
+	}
+	return segments
+}
+
+// selectValues collects the values at the paths matching the pattern
+func selectValues(pattern []string, path []string, data interface{}, values *[]interface{}) {
+	if matchSegments(pattern, path) {
+		*values = append(*values, data)
+	}
+	switch dt := data.(type) {
+	case []interface{}:
+		for i, u := range dt {
+			selectValues(pattern, append(path[:len(path):len(path)], fmt.Sprintf("[%d]", i)), u, values)
+		}
+	case map[string]interface{}:
+		for k, u := range dt {
+			selectValues(pattern, append(path[:len(path):len(path)], k), u, values)
+		}
+	}
+}
+
+// matchSegments returns true if the path segments match the pattern segments
+func matchSegments(pattern []string, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	p, s := pattern[0], path[0]
+	isIndex := strings.HasPrefix(s, "[")
+	if p == s || p == "*" && !isIndex || p == "[*]" && isIndex {
+		return matchSegments(pattern[1:], path[1:])
+	}
+	return false
+}
+
+// normalize converts the YAML maps with interface keys to maps with string keys
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[k] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, val := range t {
+			l[i] = normalize(val)
+		}
+		return l
+	}
+	return v
+}
+
+// equal compares the values, numbers are compared by value regardless of their type
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare compares numbers, dates or strings and returns -1, 0 or 1 if a is less, equal or greater than b
+func compare(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return sign(fa - fb), true
+		}
+		return 0, false
+	}
+	if ta, ok := toTime(a); ok {
+		if tb, ok := toTime(b); ok {
+			return sign(float64(ta.Sub(tb))), true
+		}
+		return 0, false
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+func sign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		for _, l := range dateLayouts {
+			if tt, err := time.Parse(l, t); err == nil {
+				return tt, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
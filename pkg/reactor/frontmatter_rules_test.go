@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_matchFrontMatterRule(t *testing.T) {
+	// nested maps are decoded with interface keys, as done by the front-matter parser
+	fm := map[string]interface{}{
+		"title":   "Getting Started with Gardener",
+		"tags":    []interface{}{"install", "guide"},
+		"weight":  10,
+		"lastmod": "2022-03-15",
+		"menu": map[interface{}]interface{}{
+			"main": map[interface{}]interface{}{"parent": "docs", "weight": 2.5},
+		},
+		"authors": []interface{}{
+			map[interface{}]interface{}{"name": "alice"},
+			map[interface{}]interface{}{"name": "bob"},
+		},
+	}
+	tests := []struct {
+		name string
+		path string
+		rule string
+		want bool
+	}{
+		{"equal", ".title", `Getting Started with Gardener`, true},
+		{"not equal", ".title", `Other`, false},
+		{"equal numbers of different types", ".menu.main.weight", `2.5`, true},
+		{"array element", ".tags[1]", `guide`, true},
+		{"wildcard", ".**.parent", `docs`, true},
+		{"wildcard on root", ".**", `10`, true},
+		{"multiple wildcards", ".**.main.**", `docs`, true},
+		{"element wildcard", ".menu.*.parent", `docs`, true},
+		{"element wildcard doesn't match arrays", ".tags.*", `guide`, false},
+		{"array wildcard", ".authors[*].name", `bob`, true},
+		{"$eq", ".weight", `{$eq: 10}`, true},
+		{"$ne", ".weight", `{$ne: 10}`, false},
+		{"$in", ".menu.main.parent", `{$in: [docs, blog]}`, true},
+		{"$in on array elements", ".tags[*]", `{$in: [guide, tutorial]}`, true},
+		{"$nin", ".menu.main.parent", `{$nin: [docs, blog]}`, false},
+		{"$contains array", ".tags", `{$contains: install}`, true},
+		{"$contains missing", ".tags", `{$contains: security}`, false},
+		{"$contains string", ".title", `{$contains: Gardener}`, true},
+		{"$regex", ".title", `{$regex: "^Getting"}`, true},
+		{"$regex no match", ".title", `{$regex: "^Gardener"}`, false},
+		{"$regex on non string", ".weight", `{$regex: "1"}`, false},
+		{"$exists", ".menu.main", `{$exists: true}`, true},
+		{"$exists missing", ".draft", `{$exists: true}`, false},
+		{"$exists false", ".draft", `{$exists: false}`, true},
+		{"$lt number", ".weight", `{$lt: 20}`, true},
+		{"$gte number", ".weight", `{$gte: 20}`, false},
+		{"$gt date", ".lastmod", `{$gt: 2022-01-01}`, true},
+		{"$lte date", ".lastmod", `{$lte: "2022-03-14"}`, false},
+		{"range", ".weight", `{$gt: 5, $lt: 15}`, true},
+		{"range no match", ".weight", `{$gt: 5, $lt: 8}`, false},
+		{"$not", ".tags", `{$not: {$contains: draft}}`, true},
+		{"$not equal", ".title", `{$not: Other}`, true},
+		{"unknown operator", ".title", `{$like: Getting}`, false},
+		{"plain map value", ".menu.main", `{parent: docs, weight: 2.5}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule interface{}
+			if err := yaml.Unmarshal([]byte(tt.rule), &rule); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, matchFrontMatterRule(tt.path, rule, fm))
+		})
+	}
+}
@@ -66,8 +66,8 @@ func (w *gitHubInfoWorker) GitHubInfoWork(ctx context.Context, task interface{})
 			sources = append(sources, node.Source)
 		}
 		// append multi content
-		for _, src := range node.MultiSource {
-			sources = append(sources, src)
+		for _, ms := range node.MultiSource {
+			sources = append(sources, ms.Source)
 		}
 		var (
 			b    bytes.Buffer
@@ -67,7 +67,7 @@ var _ = Describe("GithubInfo", func() {
 					Node: &api.Node{
 						Name:        "fake_name",
 						Source:      "fake_source",
-						MultiSource: []string{"fake_multi_source"},
+						MultiSource: []api.MultiSourceItem{{Source: "fake_multi_source"}},
 					},
 				}
 			})
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/markdown"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/yuin/goldmark/ast"
+	"k8s.io/klog/v2"
+)
+
+// includeDirectiveRgx matches `<!-- docforge:include <url>[#fragment] -->`
+var includeDirectiveRgx = regexp.MustCompile(`^\s*<!--\s*docforge:include\s+(\S+)\s*-->\s*$`)
+
+// includeContent replaces the `<!-- docforge:include <url>[#fragment] -->` directives in the document with the
+// referenced document or fragment of it. The included content is processed as the document content, i.e.
+// its code and include directives are resolved and its links are rewritten relative to its own source.
+// The front matter of the included document is dropped.
+// The includes is the chain of sources including the document, used to detect include cycles.
+func (c *nodeContentProcessor) includeContent(ctx context.Context, r Reader, n *api.Node, dc *docContent, includes []string) error {
+	blocks, directives, err := findDirectives(dc, includeDirectiveRgx)
+	if err != nil {
+		return err
+	}
+	for i, h := range blocks {
+		m := directives[i]
+		uri, fragment := splitFragment(m[1])
+		if uri, err = c.directiveURI(dc.docURI, uri, "include"); err != nil {
+			return err
+		}
+		if uri == "" {
+			continue
+		}
+		key := includeKey(uri, fragment)
+		for _, inc := range includes {
+			if inc == key {
+				return fmt.Errorf("include cycle %s -> %s", strings.Join(includes, " -> "), key)
+			}
+		}
+		source, err := r.Read(ctx, uri)
+		if err != nil {
+			if resourceNotFound, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+				klog.Warningf("reading include %s failed: %s\n", m[1], resourceNotFound)
+				continue
+			}
+			return fmt.Errorf("reading include %s failed: %w", m[1], err)
+		}
+		if len(source) == 0 {
+			klog.Warningf("no content read from include %s\n", m[1])
+			continue
+		}
+		inc, err := c.includedContent(ctx, r, n, source, uri, fragment, append(includes[:len(includes):len(includes)], key))
+		if err != nil {
+			return fmt.Errorf("fail to include %s: %w", m[1], err)
+		}
+		buf := &bytes.Buffer{}
+		if err = c.getRenderer(ctx, r, n, uri).Render(buf, inc.docCnt, inc.docAst); err != nil {
+			return fmt.Errorf("fail to include %s: %w", m[1], err)
+		}
+		var block *ast.HTMLBlock
+		block, dc.docCnt = markdown.NewRawBlock(dc.docCnt, buf.Bytes())
+		h.Parent().ReplaceChild(h.Parent(), h, block)
+	}
+	return nil
+}
+
+// includedContent parses the included source and resolves its directives
+func (c *nodeContentProcessor) includedContent(ctx context.Context, r Reader, n *api.Node, source []byte, uri string, fragment string, includes []string) (*docContent, error) {
+	if lang, ok := codeLanguage(uri); ok {
+		return codeContent(source, uri, fragment, lang)
+	}
+	dc := &docContent{docCnt: source, docURI: uri}
+	var err error
+	if dc.docAst, err = markdown.Parse(source); err != nil {
+		return nil, err
+	}
+	if d, ok := dc.docAst.(*ast.Document); ok {
+		d.SetMeta(nil)
+	}
+	if err = extractFragment(dc, fragment); err != nil {
+		return nil, err
+	}
+	if err = c.embedCode(ctx, r, dc); err != nil {
+		return nil, err
+	}
+	if err = c.includeContent(ctx, r, n, dc, includes); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// includeKey identifies an included source and fragment, e.g. `https://github.com/org/repo/blob/master/README.md#usage`
+func includeKey(uri string, fragment string) string {
+	if fragment == "" {
+		return uri
+	}
+	return uri + "#" + fragment
+}
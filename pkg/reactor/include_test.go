@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_processInclude(t *testing.T) {
+	docforge := "https://github.com/gardener/docforge/blob/master/docs/"
+	gardener := "https://github.com/gardener/gardener/blob/master/docs/"
+	r := fakeReader{
+		docforge + "install.md":  []byte("# Install\n\n<!-- docforge:include " + gardener + "snippets.md#prerequisites -->\n\nRun it.\n"),
+		docforge + "usage.md":    []byte("# Usage\n\n- Step:\n\n  <!-- docforge:include ./warning.md -->\n"),
+		docforge + "warning.md":  []byte("---\ntitle: Warning\n---\n> **Warning**: read the [notes](./notes.md).\n"),
+		gardener + "snippets.md": []byte("# Snippets\n\n## Prerequisites\n\nSee the [setup](./setup.md).\n\n<!-- docforge:include ../../../../docforge/blob/master/docs/warning.md -->\n\n## Other\n"),
+		docforge + "cycle.md":    []byte("# Cycle\n\n<!-- docforge:include ./loop.md -->\n"),
+		docforge + "loop.md":     []byte("<!-- docforge:include ./cycle.md -->\n"),
+	}
+	tests := []struct {
+		name    string
+		node    *api.Node
+		want    string
+		wantErr string
+	}{
+		{
+			name: "fragment from another repository",
+			node: &api.Node{Name: "install.md", Source: docforge + "install.md"},
+			want: "# Install\n\n## Prerequisites\n\nSee the [setup](" + gardener + "setup.md).\n\n> **Warning**: read the [notes](" + docforge + "notes.md).\n\nRun it.\n",
+		},
+		{
+			name: "include in list item",
+			node: &api.Node{Name: "usage.md", Source: docforge + "usage.md"},
+			want: "# Usage\n\n- Step:\n  \n  > **Warning**: read the [notes](" + docforge + "notes.md).\n",
+		},
+		{
+			name: "include in inline content",
+			node: &api.Node{Name: "warning.md", Content: "<!-- docforge:include " + docforge + "warning.md -->\n"},
+			want: "> **Warning**: read the [notes](" + docforge + "notes.md).\n",
+		},
+		{
+			name:    "include cycle",
+			node:    &api.Node{Name: "cycle.md", Source: docforge + "cycle.md"},
+			wantErr: "fail to resolve includes in source " + docforge + "cycle.md from node /cycle.md: fail to include ./loop.md: include cycle " + docforge + "cycle.md -> " + docforge + "loop.md -> " + docforge + "cycle.md",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := fakeLinkHandler()
+			c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+			c.Prepare([]*api.Node{tt.node})
+			var b bytes.Buffer
+			err := c.Process(context.TODO(), &b, r, tt.node)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func Test_processFilteredDocument(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	r := fakeReader{
+		docs + "guide.md":    []byte("---\naudience: user\n---\n# Guide\n\n<!-- docforge:include ./note.md -->\n\n<!-- docforge:code ./run.sh -->\n"),
+		docs + "internal.md": []byte("---\naudience: developer\n---\n# Internal\n"),
+		docs + "note.md":     []byte("> **Note**: read it.\n"),
+		docs + "run.sh":      []byte("echo run\n"),
+	}
+	h := fakeLinkHandler()
+	h.ReadStub = r.Read
+	guide := &api.Node{Name: "guide.md", Source: docs + "guide.md"}
+	container := &api.Node{Name: "docs", Nodes: []*api.Node{guide, {Name: "internal.md", Source: docs + "internal.md"}}}
+	container.SetParentsDownwards()
+	// the content of the documents of node selectors with front matter filters is cached
+	assert.NoError(t, filterDocuments(context.TODO(), h, container, nil, map[string]interface{}{".audience": "user"}))
+	assert.Equal(t, []*api.Node{guide}, container.Nodes)
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "/__resources"})
+	c.Prepare([]*api.Node{container})
+	var b bytes.Buffer
+	assert.NoError(t, c.Process(context.TODO(), &b, r, guide))
+	assert.Equal(t, "---\naudience: user\n---\n\n# Guide\n\n> **Note**: read it.\n\n```bash\necho run\n```\n", b.String())
+}
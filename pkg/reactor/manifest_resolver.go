@@ -8,7 +8,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/gardener/docforge/pkg/util"
-	"reflect"
+	"net/url"
+	"path"
 	"strings"
 
 	"github.com/gardener/docforge/pkg/api"
@@ -39,7 +40,7 @@ func (r *Reactor) ResolveManifest(ctx context.Context, manifest *api.Documentati
 			return err
 		}
 		root.NodeSelector = nil
-		if err = root.Union(node.Nodes); err != nil {
+		if err = root.UnionWithPrecedence(node.Nodes, r.Options.PropertiesPrecedence); err != nil {
 			return err
 		}
 	}
@@ -52,9 +53,7 @@ func (r *Reactor) ResolveManifest(ctx context.Context, manifest *api.Documentati
 		return err
 	}
 	// determine section files
-	if r.Options.Hugo.Enabled {
-		r.resolveSectionFiles(root)
-	}
+	r.resolveSectionFiles(root)
 	// set nil parent for root structure nodes
 	for _, n := range root.Nodes {
 		n.SetParent(nil)
@@ -76,7 +75,7 @@ func (r *Reactor) resolveStructure(ctx context.Context, nodes []*api.Node, visit
 				return err
 			}
 			node.NodeSelector = nil
-			if err = node.Union(selected.Nodes); err != nil {
+			if err = node.UnionWithPrecedence(selected.Nodes, r.Options.PropertiesPrecedence); err != nil {
 				return err
 			}
 		}
@@ -88,19 +87,32 @@ func (r *Reactor) resolveStructure(ctx context.Context, nodes []*api.Node, visit
 	return nil
 }
 
+// resolveNodeSelector resolves the node selector into a virtual node with the selected nodes,
+// patched by the node selector overlays
 func (r *Reactor) resolveNodeSelector(ctx context.Context, node *api.Node, visited []string) (*api.Node, error) {
+	result, err := r.selectNodes(ctx, node, visited)
+	if err != nil || len(node.NodeSelector.Overlays) == 0 {
+		return result, err
+	}
+	if err = result.ApplyOverlays(node.NodeSelector.Overlays); err != nil {
+		return nil, fmt.Errorf("failed to apply the overlays of node %s with path %s: %v", node.FullName("/"), node.NodeSelector.Path, err)
+	}
+	return result, nil
+}
+
+func (r *Reactor) selectNodes(ctx context.Context, node *api.Node, visited []string) (*api.Node, error) {
 	// get resource handler
 	rh := r.ResourceHandlers.Get(node.NodeSelector.Path)
 	if rh == nil {
 		return nil, fmt.Errorf("no suitable handler registered for path %s", node.NodeSelector.Path)
 	}
 
-	ri, err := util.BuildResourceInfo(node.NodeSelector.Path)
+	isManifest, err := IsManifestPath(node.NodeSelector.Path)
 	if err != nil {
 		return nil, err
 	}
 	// if path points to manifest then resolve documentation
-	if ri.Type == "blob" {
+	if isManifest {
 		var manifest *api.Documentation
 		manifest, err = rh.ResolveDocumentation(ctx, node.NodeSelector.Path)
 		if err != nil {
@@ -131,7 +143,7 @@ func (r *Reactor) resolveNodeSelector(ctx context.Context, node *api.Node, visit
 					return nil, err
 				}
 				result.NodeSelector = nil
-				if err = result.Union(selected.Nodes); err != nil {
+				if err = result.UnionWithPrecedence(selected.Nodes, r.Options.PropertiesPrecedence); err != nil {
 					return nil, err
 				}
 			}
@@ -197,7 +209,7 @@ func (r *Reactor) resolveNodeNames(nodes []*api.Node) error {
 				}
 			}
 			// check 'index=true'
-			if r.Options.Hugo.Enabled && len(node.Properties) > 0 {
+			if (r.Options.Hugo.Enabled || r.Options.MkDocs.enabled()) && len(node.Properties) > 0 {
 				if idxVal, found := node.Properties["index"]; found {
 					idx, ok := idxVal.(bool)
 					if ok && idx {
@@ -218,15 +230,17 @@ func (r *Reactor) resolveNodeNames(nodes []*api.Node) error {
 	return nil
 }
 
+// resolveSectionFiles determines the section files of the container nodes. In Hugo and MkDocs mode, the documents
+// named as one of the index file names are renamed to _index.md. The section files of the container nodes with
+// generateIndex are generated, if not found.
 func (r *Reactor) resolveSectionFiles(container *api.Node) {
-	var hasSectionFile bool
+	// descendants first, the generated section files list the sections of the child containers
 	for _, node := range container.Nodes {
-		if node.IsDocument() && node.Name == "_index.md" {
-			hasSectionFile = true
-			break
+		if !node.IsDocument() {
+			r.resolveSectionFiles(node)
 		}
 	}
-	if !hasSectionFile && len(r.Options.Hugo.IndexFileNames) > 0 {
+	if sectionFile(container) == nil && (r.Options.Hugo.Enabled || r.Options.MkDocs.enabled()) && len(r.Options.Hugo.IndexFileNames) > 0 {
 		// try to find one, priority is the IndexFileNames order
 		for _, ifn := range r.Options.Hugo.IndexFileNames {
 			for _, node := range container.Nodes {
@@ -238,11 +252,62 @@ func (r *Reactor) resolveSectionFiles(container *api.Node) {
 			}
 		}
 	}
+	if container.GenerateIndex && sectionFile(container) == nil {
+		klog.V(6).Infof("generating %s/_index.md\n", container.FullName("/"))
+		index := &api.Node{Name: "_index.md", Content: r.indexContent(container)}
+		index.SetParent(container)
+		container.Nodes = append([]*api.Node{index}, container.Nodes...)
+	}
+}
+
+// sectionFile returns the _index.md document node of a container, or nil if there is none
+func sectionFile(container *api.Node) *api.Node {
 	for _, node := range container.Nodes {
+		if node.IsDocument() && node.Name == "_index.md" {
+			return node
+		}
+	}
+	return nil
+}
+
+// indexContent renders the content of a generated section file, that lists the child nodes titles
+// with links to them. The links are relative to the section file in the node structure.
+func (r *Reactor) indexContent(container *api.Node) string {
+	var b strings.Builder
+	if !r.Options.Hugo.Enabled {
+		// in Hugo mode the title is set in the front matter
+		fmt.Fprintf(&b, "# %s\n\n", nodeTitle(container))
+	}
+	for _, node := range container.Nodes {
+		link := "./" + url.PathEscape(node.Name)
 		if !node.IsDocument() {
-			r.resolveSectionFiles(node)
+			if sectionFile(node) == nil {
+				// no page to link
+				continue
+			}
+			link += "/_index.md"
 		}
+		fmt.Fprintf(&b, "- [%s](%s)\n", nodeTitle(node), link)
 	}
+	return b.String()
+}
+
+// nodeTitle returns the front matter title of a node, or its normalized name
+func nodeTitle(node *api.Node) string {
+	if title := frontmatterTitle(node); title != "" {
+		return title
+	}
+	return nameToTitle(node.Name)
+}
+
+// frontmatterTitle returns the title in the front matter properties of a node, or empty string if not set
+func frontmatterTitle(node *api.Node) string {
+	if fm, ok := node.Properties["frontmatter"].(map[string]interface{}); ok {
+		if title, ok := fm["title"].(string); ok {
+			return title
+		}
+	}
+	return ""
 }
 
 // TODO: on err just continue ... ?? only warning messages or exclude nodes with errors ???
@@ -320,62 +385,16 @@ func filterDocuments(ctx context.Context, rh resourcehandlers.ResourceHandler, n
 	return errs
 }
 
-// matchFrontMatterRule explores a parsed frontmatter object `data` to matchRule
-// `value` at `path` pattern and return true on successfully matchRule or false
-// otherwise.
-// Path is an expression with a JSONPath-like simplified notation.
-// An object in path is modeled as dot (`.`). Paths start with the root object,
-// i.e. the most minimal path is `.`.
-// An object element value is referenced by its name (key) in the object map:
-// `.a.b.c` is path to element `c` in map `b` in map `a` in root object map.
-// Element values can be scalar, object maps or arrays.
-// An element in an array is referenced by its index: `.a.b[1]` references `b`
-// array element with index 1.
-// Paths can include up to one wildcard `**` symbol that models *any* path node.
-// A `.a.**.c` models any path starting with	`.a.` and ending with `.c`.
-func matchFrontMatterRule(path string, val interface{}, data interface{}) bool {
-	return matchRule(path, val, nil, data)
-}
-
-func matchRule(pathPattern string, val interface{}, path []string, data interface{}) bool {
-	if path == nil {
-		path = []string{"."}
-	}
-	p := strings.Join(path, "")
-	if matchPath(pathPattern, p) {
-		if reflect.DeepEqual(val, data) {
-			return true
-		}
-	}
-	switch dt := data.(type) {
-	case []interface{}:
-		for i, u := range dt {
-			_p := append(path, fmt.Sprintf("[%d]", i))
-			if ok := matchRule(pathPattern, val, _p, u); ok {
-				return true
-			}
-		}
-	case map[string]interface{}:
-		for k, u := range dt {
-			if path[(len(path))-1] != "." {
-				path = append(path, ".")
-			}
-			_p := append(path, k)
-			if ok := matchRule(pathPattern, val, _p, u); ok {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func matchPath(pathPattern, path string) bool {
-	if pathPattern == path {
-		return true
+// IsManifestPath returns true if the path points to a documentation manifest,
+// i.e. it is a GitHub 'blob' URL or a YAML file
+func IsManifestPath(p string) (bool, error) {
+	ri, err := util.BuildResourceInfo(p)
+	if err != nil {
+		return false, err
 	}
-	s := strings.Split(pathPattern, "**")
-	if len(s) == 2 {
-		return strings.HasPrefix(path, s[0]) && strings.HasSuffix(path, s[1])
+	if ri.Type == "blob" {
+		return true, nil
 	}
-	return false
+	ext := strings.ToLower(path.Ext(ri.URL.Path))
+	return ext == ".yaml" || ext == ".yml", nil
 }
@@ -311,6 +311,66 @@ func TestResolveManifest(t *testing.T) {
 					}}},
 			},
 		},
+		{
+			name:        "applies_overlays_to_imported_manifest",
+			description: "should rename, remove, reorder and add properties to the nodes of the imported manifest",
+			args: args{
+				ctx: defaultCtxWithTimeout,
+				resolveDocumentationFunc: func(ctx context.Context, uri string) (*api.Documentation, error) {
+					return &api.Documentation{Structure: []*api.Node{
+						{Name: "overview", Source: "overview.md"},
+						{Name: "guides", Nodes: []*api.Node{
+							{Name: "a.md", Source: "a.md"},
+							{Name: "b.md", Source: "b.md"},
+						}},
+						{Name: "internal.md", Source: "internal.md"},
+					}}, nil
+				},
+				testDocumentation: &api.Documentation{
+					Structure: []*api.Node{
+						{
+							Name: "component",
+							NodeSelector: &api.NodeSelector{
+								Path: "https://host.com/owner/repo/blob/branch/module.yaml",
+								Overlays: []*api.Overlay{
+									{Path: "internal.md", Remove: true},
+									{Path: "guides", Rename: "tutorials", Order: []string{"b.md"}, Properties: map[string]interface{}{"frontmatter": map[string]interface{}{"title": "Tutorials"}}},
+									{Path: "overview.md", Properties: map[string]interface{}{"weight": 1}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			expectedDocumentation: &api.Documentation{
+				Structure: []*api.Node{{Name: "component",
+					Nodes: []*api.Node{
+						{Name: "overview.md", Source: "overview.md", Properties: map[string]interface{}{"weight": 1}},
+						{Name: "tutorials", Properties: map[string]interface{}{"frontmatter": map[string]interface{}{"title": "Tutorials"}}, Nodes: []*api.Node{
+							{Name: "b.md", Source: "b.md"},
+							{Name: "a.md", Source: "a.md"},
+						}},
+					}}},
+			},
+		},
+		{
+			name:        "fails_on_unmatched_overlay",
+			description: "should fail when the overlay path doesn't match any node of the imported manifest",
+			args: args{
+				ctx: defaultCtxWithTimeout,
+				resolveDocumentationFunc: func(ctx context.Context, uri string) (*api.Documentation, error) {
+					return &api.Documentation{Structure: []*api.Node{{Name: "overview.md", Source: "overview.md"}}}, nil
+				},
+				testDocumentation: &api.Documentation{
+					NodeSelector: &api.NodeSelector{
+						Path:     "https://host.com/owner/repo/blob/branch/module.yaml",
+						Overlays: []*api.Overlay{{Path: "guides/missing.md", Remove: true}},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -656,3 +716,61 @@ func Test_resolveNodeName(t *testing.T) {
 		})
 	}
 }
+
+func Test_resolveSectionFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		hugo      *Hugo
+		mkdocs    *MkDocs
+		container *api.Node
+		want      string
+	}{
+		{
+			name: "generated index",
+			hugo: &Hugo{},
+			container: &api.Node{Name: "docs", GenerateIndex: true, Nodes: []*api.Node{
+				{Name: "getting-started.md", Source: "https://fake.host/getting-started.md"},
+				{Name: "faq.md", Source: "https://fake.host/faq.md", Properties: map[string]interface{}{"frontmatter": map[string]interface{}{"title": "FAQ"}}},
+				{Name: "guides", GenerateIndex: true, Nodes: []*api.Node{{Name: "install.md", Source: "https://fake.host/install.md"}}},
+				{Name: "internal", Nodes: []*api.Node{{Name: "notes.md", Source: "https://fake.host/notes.md"}}},
+			}},
+			want: "# Docs\n\n- [Getting Started](./getting-started.md)\n- [FAQ](./faq.md)\n- [Guides](./guides/_index.md)\n",
+		},
+		{
+			name: "generated index in Hugo mode links renamed section files",
+			hugo: &Hugo{Enabled: true, IndexFileNames: []string{"readme.md"}},
+			container: &api.Node{Name: "docs", GenerateIndex: true, Nodes: []*api.Node{
+				{Name: "usage", Nodes: []*api.Node{{Name: "README.md", Source: "https://fake.host/README.md"}}},
+			}},
+			want: "- [Usage](./usage/_index.md)\n",
+		},
+		{
+			name:   "generated index in MkDocs mode links renamed section files",
+			hugo:   &Hugo{IndexFileNames: []string{"readme.md", "index.md"}},
+			mkdocs: &MkDocs{Enabled: true},
+			container: &api.Node{Name: "docs", GenerateIndex: true, Nodes: []*api.Node{
+				{Name: "usage", Nodes: []*api.Node{{Name: "guide.md", Source: "https://fake.host/guide.md"}, {Name: "index.md", Source: "https://fake.host/index.md"}}},
+			}},
+			want: "# Docs\n\n- [Usage](./usage/_index.md)\n",
+		},
+		{
+			name: "existing section file is not replaced",
+			hugo: &Hugo{},
+			container: &api.Node{Name: "docs", GenerateIndex: true, Nodes: []*api.Node{
+				{Name: "_index.md", Content: "# Documentation"},
+			}},
+			want: "# Documentation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reactor{Options: &Options{Hugo: tt.hugo, MkDocs: tt.mkdocs}}
+			tt.container.SetParentsDownwards()
+			r.resolveSectionFiles(&api.Node{Nodes: []*api.Node{tt.container}})
+			index := tt.container.Nodes[0]
+			assert.Equal(t, "_index.md", index.Name)
+			assert.Equal(t, tt.container, index.Parent())
+			assert.Equal(t, tt.want, index.Content)
+		})
+	}
+}
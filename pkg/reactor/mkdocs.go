@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+
+	"github.com/gardener/docforge/pkg/api"
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MkDocsConfigFile is the name of the generated MkDocs configuration file in the destination
+	MkDocsConfigFile = "mkdocs.yml"
+	// MkDocsDocsDir is the folder of the documents in the destination
+	MkDocsDocsDir = "docs"
+)
+
+// MkDocs is the configuration options for building MkDocs documentation
+type MkDocs struct {
+	Enabled bool
+	// Config is the base MkDocs configuration the generated `nav` is set in,
+	// a minimal configuration is generated if empty
+	Config []byte
+}
+
+func (m *MkDocs) enabled() bool {
+	return m != nil && m.Enabled
+}
+
+// mkdocsName returns the name of a document written for MkDocs, i.e. the section files are `index.md`
+func mkdocsName(name string) string {
+	if name == "_index.md" {
+		return "index.md"
+	}
+	return name
+}
+
+// mkdocsPath returns the path relative to the docs folder of a document or of the section file of a container
+func mkdocsPath(node *api.Node) string {
+	if node.IsDocument() {
+		return path.Join(nodeDir(node), mkdocsName(node.Name))
+	}
+	return path.Join(nodeDir(node), node.Name, "index.md")
+}
+
+// mkdocsNav returns the MkDocs navigation of the nodes in the structure order. The section files are the
+// first entries of the sections. The documents are labeled by the titles in the node front matter, if any,
+// otherwise MkDocs uses the document titles. The sections are labeled by the container titles.
+func mkdocsNav(nodes []*api.Node) []interface{} {
+	var nav []interface{}
+	for _, node := range nodes {
+		if node.IsDocument() {
+			var entry interface{} = mkdocsPath(node)
+			if title := frontmatterTitle(node); title != "" {
+				entry = map[string]interface{}{title: entry}
+			}
+			if node.Name == "_index.md" {
+				nav = append([]interface{}{entry}, nav...)
+			} else {
+				nav = append(nav, entry)
+			}
+			continue
+		}
+		if entries := mkdocsNav(node.Nodes); len(entries) > 0 {
+			nav = append(nav, map[string]interface{}{nodeTitle(node): entries})
+		}
+	}
+	return nav
+}
+
+// mkdocsConfig returns the MkDocs configuration with the navigation of the documentation structure
+func mkdocsConfig(base []byte, structure []*api.Node) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(base, &doc); err != nil {
+		return nil, fmt.Errorf("invalid MkDocs configuration: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+		setMapping(doc.Content[0], "site_name", &yaml.Node{Kind: yaml.ScalarNode, Value: "Documentation"})
+	}
+	config := doc.Content[0]
+	if config.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid MkDocs configuration: mapping expected")
+	}
+	nav := &yaml.Node{}
+	if err := nav.Encode(mkdocsNav(structure)); err != nil {
+		return nil, err
+	}
+	setMapping(config, "nav", nav)
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// setMapping sets the value of a key in a YAML mapping node
+func setMapping(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// writeMkDocsConfig writes the MkDocs configuration of the documentation structure in the destination
+func (r *Reactor) writeMkDocsConfig(structure []*api.Node) error {
+	config, err := mkdocsConfig(r.Options.MkDocs.Config, structure)
+	if err != nil {
+		return err
+	}
+	if err = r.Options.ConfigWriter.Write(MkDocsConfigFile, "", config, nil); err != nil {
+		return fmt.Errorf("writing %s failed: %v", MkDocsConfigFile, err)
+	}
+	return nil
+}
+
+// rewriteMkDocsDestination rewrites the links to the documents as relative paths in the docs folder
+func (l *linkResolver) rewriteMkDocsDestination(link *linkInfo) {
+	if link.destinationNode == nil || link.isEmbeddable {
+		return
+	}
+	dest := relativePath(nodeDir(l.node), mkdocsPath(link.destinationNode))
+	if link.URL.ForceQuery || link.URL.RawQuery != "" {
+		dest = fmt.Sprintf("%s?%s", dest, link.URL.RawQuery)
+	}
+	if link.URL.Fragment != "" {
+		dest = fmt.Sprintf("%s#%s", dest, link.URL.Fragment)
+	}
+	if link.destination != dest {
+		klog.V(6).Infof("[%s] %s -> %s\n", l.source, link.destination, dest)
+		link.destination = dest
+	}
+}
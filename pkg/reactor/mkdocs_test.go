@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func mkdocsStructure() []*api.Node {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	root := &api.Node{Nodes: []*api.Node{
+		{Name: "_index.md", Content: "# Home\n"},
+		{Name: "docs", Nodes: []*api.Node{
+			{Name: "guide.md", Source: docs + "guide.md", Properties: map[string]interface{}{"frontmatter": map[string]interface{}{"title": "User Guide"}}},
+			{Name: "_index.md", Content: "See the [guide](./guide.md) and the [API](./api/_index.md#usage).\n"},
+			{Name: "api", Properties: map[string]interface{}{"frontmatter": map[string]interface{}{"title": "API"}}, Nodes: []*api.Node{
+				{Name: "_index.md", Source: docs + "reference.md"},
+				{Name: "types.md", Source: docs + "types.md"},
+			}},
+			{Name: "empty"},
+		}},
+	}}
+	root.SetParentsDownwards()
+	for _, n := range root.Nodes {
+		n.SetParent(nil)
+	}
+	return root.Nodes
+}
+
+func Test_mkdocsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "minimal configuration",
+			want: `site_name: Documentation
+nav:
+  - index.md
+  - Docs:
+      - docs/index.md
+      - User Guide: docs/guide.md
+      - API:
+          - docs/api/index.md
+          - docs/api/types.md
+`,
+		},
+		{
+			name: "base configuration",
+			base: "# site\nsite_name: Gardener\nnav:\n  - old.md\ntheme:\n  name: material\n",
+			want: `# site
+site_name: Gardener
+nav:
+  - index.md
+  - Docs:
+      - docs/index.md
+      - User Guide: docs/guide.md
+      - API:
+          - docs/api/index.md
+          - docs/api/types.md
+theme:
+  name: material
+`,
+		},
+		{
+			name:    "invalid configuration",
+			base:    "- site_name\n",
+			wantErr: "invalid MkDocs configuration: mapping expected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mkdocsConfig([]byte(tt.base), mkdocsStructure())
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_processMkDocsLinks(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	r := fakeReader{
+		docs + "guide.md":     []byte("# Guide\n\nSee the [reference](./reference.md?plain), the [types](./types.md#pod) and the [home](../README.md).\n"),
+		docs + "reference.md": []byte("# Reference\n\nBack to the [guide](./guide.md).\n"),
+	}
+	structure := mkdocsStructure()
+	tests := []struct {
+		name string
+		node *api.Node
+		want string
+	}{
+		{
+			name: "inline content",
+			node: structure[1].Nodes[1],
+			want: "See the [guide](guide.md) and the [API](api/index.md#usage).\n",
+		},
+		{
+			name: "document",
+			node: structure[1].Nodes[0],
+			want: "# Guide\n\nSee the [reference](api/index.md?plain), the [types](api/types.md#pod) and the [home](" + docs[:len(docs)-5] + "README.md).\n",
+		},
+		{
+			name: "section file",
+			node: structure[1].Nodes[2].Nodes[0],
+			want: "# Reference\n\nBack to the [guide](../guide.md).\n",
+		},
+	}
+	h := fakeLinkHandler()
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "__resources", MkDocs: &MkDocs{Enabled: true}})
+	c.Prepare(structure)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.NoError(t, c.Process(context.TODO(), &b, r, tt.node))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/markdown"
+	"k8s.io/klog/v2"
+)
+
+// bundleResources collects the pages linking the embeddable resources, the resources linked by a single
+// page are stored in its page bundle
+type bundleResources struct {
+	// nodes are the documentation structure nodes to scan
+	nodes []*api.Node
+	// pages are the pages linking a resource by its absolute URL without query & fragment
+	pages map[string]map[*api.Node]struct{}
+	// sources are the scanned node sources, reused when processing the nodes
+	sources map[bundleSource][]byte
+	// documents are the document nodes to scan, next is the index of the next one
+	documents []*api.Node
+	next      int
+	scanned   sync.WaitGroup
+	once      sync.Once
+	mux       sync.Mutex
+}
+
+// bundleSource identifies a scanned source of a node
+type bundleSource struct {
+	node *api.Node
+	uri  string
+}
+
+func newBundleResources() *bundleResources {
+	return &bundleResources{
+		pages:   make(map[string]map[*api.Node]struct{}),
+		sources: make(map[bundleSource][]byte),
+	}
+}
+
+// source returns the scanned source of the node once, if any
+func (b *bundleResources) source(node *api.Node, uri string) ([]byte, bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	key := bundleSource{node: node, uri: uri}
+	source, ok := b.sources[key]
+	delete(b.sources, key)
+	return source, ok
+}
+
+// nextDocument returns the next document node to scan, or nil if all are taken
+func (b *bundleResources) nextDocument() *api.Node {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.next == len(b.documents) {
+		return nil
+	}
+	b.next++
+	return b.documents[b.next-1]
+}
+
+// addPage registers the node as page linking the resource with the key
+func (b *bundleResources) addPage(key string, node *api.Node) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.pages[key] == nil {
+		b.pages[key] = make(map[*api.Node]struct{})
+	}
+	b.pages[key][node] = struct{}{}
+}
+
+// addSource stores the scanned source of the node
+func (b *bundleResources) addSource(node *api.Node, uri string, source []byte) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.sources[bundleSource{node: node, uri: uri}] = source
+}
+
+// pageBundles checks if the documents are written as Hugo page bundles
+func (c *nodeContentProcessor) pageBundles() bool {
+	return c.hugo != nil && c.hugo.Enabled && c.hugo.PageBundles
+}
+
+// documentDir returns the folder of a document node relative to the documentation root, i.e. the folder
+// of the page bundle `name/index.md` of the document `name.md` when writing page bundles
+func (c *nodeContentProcessor) documentDir(node *api.Node) string {
+	dir := nodeDir(node)
+	if c.pageBundles() && isLeafBundle(node) {
+		dir = path.Join(dir, strings.TrimSuffix(node.Name, ".md"))
+	}
+	return dir
+}
+
+// isLeafBundle checks if the document node is written as a leaf bundle, section files are branch bundles
+func isLeafBundle(node *api.Node) bool {
+	return node.IsDocument() && node.Name != "_index.md" && node.Name != "index.md"
+}
+
+// singlePage checks if the resource with the key is linked by the node only
+func (c *nodeContentProcessor) singlePage(key string, node *api.Node) bool {
+	pages := c.bundles.pages[key]
+	_, ok := pages[node]
+	return ok && len(pages) == 1
+}
+
+// scanBundleResources collects the pages linking the embeddable resources from the node sources and inline content.
+// The document nodes are scanned by the callers in parallel, all of them wait until the scan is completed.
+// The included content is not scanned, so the resources linked by it are not stored in page bundles.
+func (c *nodeContentProcessor) scanBundleResources(ctx context.Context, r Reader) {
+	c.bundles.once.Do(func() {
+		c.bundles.documents = documentNodes(c.bundles.nodes)
+		c.bundles.scanned.Add(len(c.bundles.documents))
+	})
+	for node := c.bundles.nextDocument(); node != nil; node = c.bundles.nextDocument() {
+		c.scanNodeResources(ctx, r, node)
+		c.bundles.scanned.Done()
+	}
+	c.bundles.scanned.Wait()
+}
+
+// documentNodes returns the document nodes in the node trees
+func documentNodes(nodes []*api.Node) []*api.Node {
+	var documents []*api.Node
+	for _, node := range nodes {
+		if node.IsDocument() {
+			documents = append(documents, node)
+		}
+		documents = append(documents, documentNodes(node.Nodes)...)
+	}
+	return documents
+}
+
+func (c *nodeContentProcessor) scanNodeResources(ctx context.Context, r Reader, node *api.Node) {
+	sources := make([]string, 0, len(node.MultiSource)+1)
+	if node.Source != "" {
+		sources = append(sources, node.Source)
+	}
+	for _, ms := range node.MultiSource {
+		sources = append(sources, ms.Source)
+	}
+	for _, src := range sources {
+		uri, _ := splitFragment(src)
+		if _, ok := codeLanguage(uri); ok {
+			continue
+		}
+		source, err := r.Read(ctx, uri)
+		if err != nil {
+			klog.Warningf("scanning resources of source %s from node %s failed: %v\n", src, node.FullName("/"), err)
+			continue
+		}
+		c.bundles.addSource(node, uri, source)
+		c.scanResources(node, uri, source)
+	}
+	if node.Content != "" {
+		c.scanResources(node, "", []byte(node.Content))
+	}
+}
+
+// scanResources registers the node as page linking the embeddable resources of the source content
+func (c *nodeContentProcessor) scanResources(node *api.Node, uri string, source []byte) {
+	doc, err := markdown.Parse(source)
+	if err != nil {
+		klog.Warningf("scanning resources of source %s from node %s failed: %v\n", uri, node.FullName("/"), err)
+		return
+	}
+	rnd := markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(func(dest string, isEmbeddable bool) (string, error) {
+		if isEmbeddable {
+			if key, ok := c.resourceKey(uri, dest); ok {
+				c.bundles.addPage(key, node)
+			}
+		}
+		return dest, nil
+	}))
+	if err = rnd.Render(io.Discard, source, doc); err != nil {
+		klog.Warningf("scanning resources of source %s from node %s failed: %v\n", uri, node.FullName("/"), err)
+	}
+}
+
+// resourceKey returns the absolute URL without query & fragment of a resource linked from the source
+func (c *nodeContentProcessor) resourceKey(source string, dest string) (string, bool) {
+	u, err := url.Parse(strings.TrimSuffix(dest, "/"))
+	if err != nil || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "mailto:") {
+		return "", false
+	}
+	absLink := dest
+	if !u.IsAbs() {
+		if source == "" {
+			return "", false
+		}
+		handler := c.resourceHandlers.Get(source)
+		if handler == nil {
+			return "", false
+		}
+		if absLink, err = handler.BuildAbsLink(source, dest); err != nil {
+			return "", false
+		}
+	}
+	absURL, err := url.Parse(absLink)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s://%s%s", absURL.Scheme, absURL.Host, absURL.Path), true
+}
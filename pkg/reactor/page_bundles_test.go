@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_processPageBundles(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	r := fakeReader{
+		docs + "index.md":        []byte("# Overview\n\n![logo](./images/logo.png)\n"),
+		docs + "guide.md":        []byte("# Guide\n\n![arch](./images/arch.png)\n![logo](./images/logo.png)\n\nSee [FAQ](./faq.md) and [overview](./index.md).\n"),
+		docs + "faq.md":          []byte("# FAQ\n"),
+		docs + "images/arch.png": []byte("arch"),
+		docs + "images/logo.png": []byte("logo"),
+	}
+	index := &api.Node{Name: "index.md", Source: docs + "index.md"}
+	guide := &api.Node{Name: "guide.md", Source: docs + "guide.md"}
+	faq := &api.Node{Name: "faq.md", Source: docs + "faq.md"}
+	root := &api.Node{Nodes: []*api.Node{{Name: "docs", Nodes: []*api.Node{index, guide, faq}}}}
+	root.SetParentsDownwards()
+	tests := []struct {
+		name string
+		node *api.Node
+		want string
+	}{
+		{
+			name: "page resources in the bundle",
+			node: guide,
+			want: "---\ntitle: Guide\n---\n\n# Guide\n\n![arch](/base/docs/guide/arch.png)\n![logo](/base/__resources/logo.png)\n\nSee [FAQ](/base/docs/faq/) and [overview](/base/docs/).\n",
+		},
+		{
+			name: "shared resources",
+			node: index,
+			want: "---\ntitle: Index\n---\n\n# Overview\n\n![logo](/base/__resources/logo.png)\n",
+		},
+	}
+	h := fakeLinkHandler()
+	hugo := &Hugo{Enabled: true, PrettyURLs: true, BaseURL: "/base", PageBundles: true}
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "__resources", Hugo: hugo, ResourcesLayout: &ResourcesLayout{Name: "$name$ext"}})
+	c.Prepare(root.Nodes)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.NoError(t, c.Process(context.TODO(), &b, r, tt.node))
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+	assert.Equal(t, map[string][]string{
+		docs + "images/arch.png": {"docs/guide/arch.png"},
+		docs + "images/logo.png": {"__resources/logo.png"},
+	}, c.ResourcesMapping())
+}
+
+func Test_scanBundleResources(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	r := &countingReader{content: map[string][]byte{
+		docs + "index.md": []byte("# Overview\n\n![logo](./images/logo.png)\n"),
+		docs + "guide.md": []byte("# Guide\n\n![arch](./images/arch.png)\n![logo](./images/logo.png)\n"),
+		docs + "faq.md":   []byte("# FAQ\n"),
+	}}
+	nodes := []*api.Node{
+		{Name: "index.md", Source: docs + "index.md"},
+		{Name: "guide.md", Source: docs + "guide.md"},
+		{Name: "faq.md", Source: docs + "faq.md"},
+	}
+	root := &api.Node{Nodes: []*api.Node{{Name: "docs", Nodes: nodes}}}
+	root.SetParentsDownwards()
+	h := fakeLinkHandler()
+	hugo := &Hugo{Enabled: true, PageBundles: true}
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(h), &ContentProcessorOptions{ResourcesRoot: "__resources", Hugo: hugo})
+	c.Prepare(root.Nodes)
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *api.Node) {
+			defer wg.Done()
+			var b bytes.Buffer
+			assert.NoError(t, c.Process(context.TODO(), &b, r, node))
+		}(node)
+	}
+	wg.Wait()
+	// each source is read once by the scan and reused by the processing, each resource is read once
+	assert.Equal(t, len(nodes)+2, r.reads)
+	p := c.(*nodeContentProcessor)
+	assert.True(t, p.singlePage(docs+"images/arch.png", nodes[1]))
+	assert.False(t, p.singlePage(docs+"images/logo.png", nodes[1]))
+}
@@ -38,6 +38,22 @@ type Options struct {
 	DryRunWriter                 writers.DryRunWriter
 	Resolve                      bool
 	Hugo                         *Hugo
+	// MkDocs builds the documentation for MkDocs, if enabled
+	MkDocs *MkDocs
+	// ConfigWriter writes the generated site configuration, i.e. the MkDocs configuration, in the destination
+	ConfigWriter writers.Writer
+	// DownloadPolicy decides which absolute links to embeddable resources are downloaded
+	DownloadPolicy *DownloadPolicy
+	// ResourcesLayout configures the naming and placement of the downloaded resources
+	ResourcesLayout *ResourcesLayout
+	// ResourcesMappingFile is the path relative to the destination of the report mapping the URLs
+	// of the downloaded resources to the stored files, not written if empty
+	ResourcesMappingFile string
+	// PropertiesPrecedence resolves the conflicting properties of merged container nodes,
+	// defaults to api.ExplicitPrecedence
+	PropertiesPrecedence api.Precedence
+	// ManifestResolved skips the manifest resolution by Run, e.g. for the versioned manifests resolved per version
+	ManifestResolved bool
 }
 
 // Hugo is the configuration options for creating HUGO implementations
@@ -46,6 +62,9 @@ type Hugo struct {
 	PrettyURLs     bool
 	BaseURL        string
 	IndexFileNames []string
+	// PageBundles writes the documents as page bundles, i.e. `name.md` as `name/index.md`. The resources
+	// linked by a single page are stored in its bundle.
+	PageBundles bool
 }
 
 // NewReactor creates a Reactor from Options
@@ -89,10 +108,16 @@ func NewReactor(o *Options) (*Reactor, error) {
 	}
 	v := NewValidator(validatorTasks)
 	worker := &DocumentWorker{
-		writer:               o.Writer,
-		reader:               &GenericReader{ResourceHandlers: rhRegistry},
-		NodeContentProcessor: NewNodeContentProcessor(o.ResourcesPath, dScheduler, v, rhRegistry, o.Hugo),
-		gitHubInfo:           ghInfo,
+		writer: o.Writer,
+		reader: &GenericReader{ResourceHandlers: rhRegistry},
+		NodeContentProcessor: NewNodeContentProcessor(dScheduler, v, rhRegistry, &ContentProcessorOptions{
+			ResourcesRoot:   o.ResourcesPath,
+			Hugo:            o.Hugo,
+			MkDocs:          o.MkDocs,
+			DownloadPolicy:  o.DownloadPolicy,
+			ResourcesLayout: o.ResourcesLayout,
+		}),
+		gitHubInfo: ghInfo,
 	}
 	docTasks, err := jobs.NewJobQueue("Document", o.DocumentWorkersCount, worker.Work, o.FailFast, reactorWG)
 	if err != nil {
@@ -141,8 +166,16 @@ func (r *Reactor) Run(ctx context.Context, manifest *api.Documentation, dryRun b
 		}
 	}()
 
-	if err := r.ResolveManifest(ctx, manifest); err != nil {
-		return fmt.Errorf("failed to resolve manifest: %s. %+v", r.Options.ManifestPath, err)
+	if !r.Options.ManifestResolved {
+		if err := r.ResolveManifest(ctx, manifest); err != nil {
+			return fmt.Errorf("failed to resolve manifest: %s. %+v", r.Options.ManifestPath, err)
+		}
+	}
+
+	if r.Options.MkDocs.enabled() {
+		if err := r.writeMkDocsConfig(manifest.Structure); err != nil {
+			return err
+		}
 	}
 
 	klog.V(4).Info("Building documentation structure\n\n")
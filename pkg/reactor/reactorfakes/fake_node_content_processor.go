@@ -14,6 +14,16 @@ import (
 )
 
 type FakeNodeContentProcessor struct {
+	BrokenAnchorsStub        func() map[string][]string
+	brokenAnchorsMutex       sync.RWMutex
+	brokenAnchorsArgsForCall []struct {
+	}
+	brokenAnchorsReturns struct {
+		result1 map[string][]string
+	}
+	brokenAnchorsReturnsOnCall map[int]struct {
+		result1 map[string][]string
+	}
 	PrepareStub        func([]*api.Node)
 	prepareMutex       sync.RWMutex
 	prepareArgsForCall []struct {
@@ -33,10 +43,73 @@ type FakeNodeContentProcessor struct {
 	processReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ResourcesMappingStub        func() map[string][]string
+	resourcesMappingMutex       sync.RWMutex
+	resourcesMappingArgsForCall []struct {
+	}
+	resourcesMappingReturns struct {
+		result1 map[string][]string
+	}
+	resourcesMappingReturnsOnCall map[int]struct {
+		result1 map[string][]string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeNodeContentProcessor) BrokenAnchors() map[string][]string {
+	fake.brokenAnchorsMutex.Lock()
+	ret, specificReturn := fake.brokenAnchorsReturnsOnCall[len(fake.brokenAnchorsArgsForCall)]
+	fake.brokenAnchorsArgsForCall = append(fake.brokenAnchorsArgsForCall, struct {
+	}{})
+	stub := fake.BrokenAnchorsStub
+	fakeReturns := fake.brokenAnchorsReturns
+	fake.recordInvocation("BrokenAnchors", []interface{}{})
+	fake.brokenAnchorsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNodeContentProcessor) BrokenAnchorsCallCount() int {
+	fake.brokenAnchorsMutex.RLock()
+	defer fake.brokenAnchorsMutex.RUnlock()
+	return len(fake.brokenAnchorsArgsForCall)
+}
+
+func (fake *FakeNodeContentProcessor) BrokenAnchorsCalls(stub func() map[string][]string) {
+	fake.brokenAnchorsMutex.Lock()
+	defer fake.brokenAnchorsMutex.Unlock()
+	fake.BrokenAnchorsStub = stub
+}
+
+func (fake *FakeNodeContentProcessor) BrokenAnchorsReturns(result1 map[string][]string) {
+	fake.brokenAnchorsMutex.Lock()
+	defer fake.brokenAnchorsMutex.Unlock()
+	fake.BrokenAnchorsStub = nil
+	fake.brokenAnchorsReturns = struct {
+		result1 map[string][]string
+	}{result1}
+}
+
+func (fake *FakeNodeContentProcessor) BrokenAnchorsReturnsOnCall(i int, result1 map[string][]string) {
+	fake.brokenAnchorsMutex.Lock()
+	defer fake.brokenAnchorsMutex.Unlock()
+	fake.BrokenAnchorsStub = nil
+	if fake.brokenAnchorsReturnsOnCall == nil {
+		fake.brokenAnchorsReturnsOnCall = make(map[int]struct {
+			result1 map[string][]string
+		})
+	}
+	fake.brokenAnchorsReturnsOnCall[i] = struct {
+		result1 map[string][]string
+	}{result1}
+}
+
 func (fake *FakeNodeContentProcessor) Prepare(arg1 []*api.Node) {
 	var arg1Copy []*api.Node
 	if arg1 != nil {
@@ -138,13 +211,70 @@ func (fake *FakeNodeContentProcessor) ProcessReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeNodeContentProcessor) ResourcesMapping() map[string][]string {
+	fake.resourcesMappingMutex.Lock()
+	ret, specificReturn := fake.resourcesMappingReturnsOnCall[len(fake.resourcesMappingArgsForCall)]
+	fake.resourcesMappingArgsForCall = append(fake.resourcesMappingArgsForCall, struct {
+	}{})
+	stub := fake.ResourcesMappingStub
+	fakeReturns := fake.resourcesMappingReturns
+	fake.recordInvocation("ResourcesMapping", []interface{}{})
+	fake.resourcesMappingMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNodeContentProcessor) ResourcesMappingCallCount() int {
+	fake.resourcesMappingMutex.RLock()
+	defer fake.resourcesMappingMutex.RUnlock()
+	return len(fake.resourcesMappingArgsForCall)
+}
+
+func (fake *FakeNodeContentProcessor) ResourcesMappingCalls(stub func() map[string][]string) {
+	fake.resourcesMappingMutex.Lock()
+	defer fake.resourcesMappingMutex.Unlock()
+	fake.ResourcesMappingStub = stub
+}
+
+func (fake *FakeNodeContentProcessor) ResourcesMappingReturns(result1 map[string][]string) {
+	fake.resourcesMappingMutex.Lock()
+	defer fake.resourcesMappingMutex.Unlock()
+	fake.ResourcesMappingStub = nil
+	fake.resourcesMappingReturns = struct {
+		result1 map[string][]string
+	}{result1}
+}
+
+func (fake *FakeNodeContentProcessor) ResourcesMappingReturnsOnCall(i int, result1 map[string][]string) {
+	fake.resourcesMappingMutex.Lock()
+	defer fake.resourcesMappingMutex.Unlock()
+	fake.ResourcesMappingStub = nil
+	if fake.resourcesMappingReturnsOnCall == nil {
+		fake.resourcesMappingReturnsOnCall = make(map[int]struct {
+			result1 map[string][]string
+		})
+	}
+	fake.resourcesMappingReturnsOnCall[i] = struct {
+		result1 map[string][]string
+	}{result1}
+}
+
 func (fake *FakeNodeContentProcessor) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.brokenAnchorsMutex.RLock()
+	defer fake.brokenAnchorsMutex.RUnlock()
 	fake.prepareMutex.RLock()
 	defer fake.prepareMutex.RUnlock()
 	fake.processMutex.RLock()
 	defer fake.processMutex.RUnlock()
+	fake.resourcesMappingMutex.RLock()
+	defer fake.resourcesMappingMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+)
+
+// Placements of the downloaded resources
+const (
+	// SharedPlacement stores the downloaded resources in the resources folder of the documentation root
+	SharedPlacement = "shared"
+	// DocumentPlacement stores the downloaded resources next to the referencing documents
+	DocumentPlacement = "document"
+	// SectionPlacement stores the downloaded resources in the resources folder of the referencing documents section
+	SectionPlacement = "section"
+)
+
+// DefaultResourceName is the default naming template of the downloaded resources
+const DefaultResourceName = "$name_$hash$ext"
+
+// ResourcesLayout configures the naming and placement of the downloaded resources
+type ResourcesLayout struct {
+	// Placement is one of SharedPlacement (default), DocumentPlacement or SectionPlacement
+	Placement string
+	// Name is the naming template of the downloaded resources, DefaultResourceName if empty.
+	// The template variables are:
+	// - `$name`: the resource name without extension
+	// - `$ext`: the resource extension, including the dot
+	// - `$hash`: a hash of the resource URL
+	// - `$contenthash`: a hash of the resource content
+	// The name can contain `/` to store the resources in sub-folders, e.g. `$contenthash$ext`.
+	Name string
+}
+
+// resourceName returns the name of the resource downloaded from the absolute URL by the naming template,
+// the hash identifies the resource, see resourceHash
+func (l *linkResolver) resourceName(absURL *url.URL, hash string) string {
+	name := l.resourceNameTemplate()
+	name = strings.ReplaceAll(name, "$contenthash", hash[:16])
+	key := fmt.Sprintf("%s://%s%s", absURL.Scheme, absURL.Host, absURL.Path)
+	urlHash := md5.Sum([]byte(key)) // hash based on absolute link, but without query & fragment to avoid duplications
+	ext := path.Ext(absURL.Path)
+	name = strings.ReplaceAll(name, "$name", strings.TrimSuffix(path.Base(absURL.Path), ext))
+	name = strings.ReplaceAll(name, "$hash", hex.EncodeToString(urlHash[:])[:6])
+	name = strings.ReplaceAll(name, "$ext", ext)
+	return name
+}
+
+// resourceNameTemplate returns the naming template of the downloaded resources
+func (l *linkResolver) resourceNameTemplate() string {
+	if l.resourcesLayout != nil && l.resourcesLayout.Name != "" {
+		return l.resourcesLayout.Name
+	}
+	return DefaultResourceName
+}
+
+// resourcePath returns the path of a downloaded resource relative to the documentation root
+func (l *linkResolver) resourcePath(name string, placement string) string {
+	root := strings.TrimPrefix(l.resourcesRoot, "/")
+	switch placement {
+	case DocumentPlacement:
+		return path.Join(l.documentDir(l.node), name)
+	case SectionPlacement:
+		return path.Join(nodeDir(l.node), root, name)
+	}
+	return path.Join(root, name)
+}
+
+// resourceScope returns the folder sharing the downloaded resources of the node documents
+func (l *linkResolver) resourceScope(placement string) string {
+	switch placement {
+	case DocumentPlacement:
+		return l.documentDir(l.node)
+	case SectionPlacement:
+		return nodeDir(l.node)
+	}
+	return ""
+}
+
+// placement returns the placement of the resource with the key, i.e. its absolute URL without query & fragment.
+// When writing Hugo page bundles, the resources linked by a single page are stored in its bundle.
+func (l *linkResolver) placement(key string) string {
+	if l.pageBundles() && l.singlePage(key, l.node) {
+		return DocumentPlacement
+	}
+	if l.resourcesLayout != nil && l.resourcesLayout.Placement != "" {
+		return l.resourcesLayout.Placement
+	}
+	return SharedPlacement
+}
+
+// resourceLink returns the link from the node document to a downloaded resource. The link is
+// root-relative if the resources root is, e.g. `/__resources`, and relative otherwise.
+func (l *linkResolver) resourceLink(resourcePath string) string {
+	if strings.HasPrefix(l.resourcesRoot, "/") {
+		return "/" + resourcePath
+	}
+	return relativePath(l.documentDir(l.node), resourcePath)
+}
+
+// nodeDir returns the folder of a node relative to the documentation root
+func nodeDir(node *api.Node) string {
+	return strings.TrimPrefix(node.Path("/"), "/")
+}
+
+// relativePath returns the relative path from a folder to a file, both relative to the same root
+func relativePath(dir string, file string) string {
+	var from, to []string
+	if dir != "" {
+		from = strings.Split(dir, "/")
+	}
+	to = strings.Split(file, "/")
+	i := 0
+	for i < len(from) && i < len(to)-1 && from[i] == to[i] {
+		i++
+	}
+	return strings.Repeat("../", len(from)-i) + strings.Join(to[i:], "/")
+}
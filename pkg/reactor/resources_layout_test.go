@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkResolver_resources(t *testing.T) {
+	image := "https://github.com/gardener/docforge/raw/master/docs/images/logo.png"
+	content := []byte("png")
+	hash := sha256.Sum256(content)
+	contentHash := hex.EncodeToString(hash[:])[:16]
+	doc := &api.Node{Name: "doc.md"}
+	guide := &api.Node{Name: "guide.md"}
+	docs := &api.Node{Name: "docs", Nodes: []*api.Node{{Name: "usage", Nodes: []*api.Node{guide}}}}
+	root := &api.Node{Nodes: []*api.Node{doc, docs}}
+	root.SetParentsDownwards()
+	tests := []struct {
+		name          string
+		resourcesRoot string
+		layout        *ResourcesLayout
+		node          *api.Node
+		wantPath      string
+		wantLink      string
+	}{
+		{
+			name:          "default layout",
+			resourcesRoot: "/__resources",
+			node:          guide,
+			wantPath:      "__resources/logo_455b79.png",
+			wantLink:      "/__resources/logo_455b79.png",
+		},
+		{
+			name:          "shared placement with relative links",
+			resourcesRoot: "__resources",
+			layout:        &ResourcesLayout{Placement: SharedPlacement, Name: "$name$ext"},
+			node:          guide,
+			wantPath:      "__resources/logo.png",
+			wantLink:      "../../__resources/logo.png",
+		},
+		{
+			name:          "shared placement of top-level document",
+			resourcesRoot: "__resources",
+			layout:        &ResourcesLayout{Name: "$name$ext"},
+			node:          doc,
+			wantPath:      "__resources/logo.png",
+			wantLink:      "__resources/logo.png",
+		},
+		{
+			name:          "document placement",
+			resourcesRoot: "__resources",
+			layout:        &ResourcesLayout{Placement: DocumentPlacement, Name: "$name$ext"},
+			node:          guide,
+			wantPath:      "docs/usage/logo.png",
+			wantLink:      "logo.png",
+		},
+		{
+			name:          "document placement with absolute links",
+			resourcesRoot: "/__resources",
+			layout:        &ResourcesLayout{Placement: DocumentPlacement, Name: "images/$name$ext"},
+			node:          guide,
+			wantPath:      "docs/usage/images/logo.png",
+			wantLink:      "/docs/usage/images/logo.png",
+		},
+		{
+			name:          "section placement",
+			resourcesRoot: "__resources",
+			layout:        &ResourcesLayout{Placement: SectionPlacement, Name: "$name$ext"},
+			node:          guide,
+			wantPath:      "docs/usage/__resources/logo.png",
+			wantLink:      "__resources/logo.png",
+		},
+		{
+			name:          "content addressed",
+			resourcesRoot: "__resources",
+			layout:        &ResourcesLayout{Name: "$contenthash$ext"},
+			node:          guide,
+			wantPath:      "__resources/" + contentHash + ".png",
+			wantLink:      "../../__resources/" + contentHash + ".png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &nodeContentProcessor{resourcesRoot: tt.resourcesRoot, resourcesLayout: tt.layout}
+			l := c.newLinkResolver(context.TODO(), fakeReader{}, tt.node, "")
+			u, err := url.Parse(image)
+			assert.NoError(t, err)
+			gotPath := l.resourcePath(l.resourceName(u, resourceHash(image, content)), l.placement(""))
+			assert.Equal(t, tt.wantPath, gotPath)
+			assert.Equal(t, tt.wantLink, l.resourceLink(gotPath))
+		})
+	}
+}
+
+func Test_relativePath(t *testing.T) {
+	tests := []struct {
+		dir  string
+		file string
+		want string
+	}{
+		{"", "__resources/a.png", "__resources/a.png"},
+		{"docs", "docs/a.png", "a.png"},
+		{"docs/usage", "__resources/a.png", "../../__resources/a.png"},
+		{"docs/usage", "docs/images/a.png", "../images/a.png"},
+		{"docs", "docs/usage/a.png", "usage/a.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir+" "+tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.want, relativePath(tt.dir, tt.file))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// resourceKey identifies a downloaded resource in a placement scope
+type resourceKey struct {
+	// scope is the folder sharing the downloaded resources, i.e. the documentation root
+	// for SharedPlacement and the document folder for DocumentPlacement and SectionPlacement
+	scope string
+	hash  string
+}
+
+// resourcesRegistry deduplicates the downloaded resources by content hash and maps
+// the URLs of the downloaded resources to the stored copies
+type resourcesRegistry struct {
+	// stored maps the resources hashes to the path of the stored copy
+	stored map[resourceKey]string
+	// owners maps the paths of the stored copies to the resources hashes
+	owners map[string]string
+	// mapping maps the resources URLs to the paths of the stored copies
+	mapping map[string][]string
+	mux     sync.Mutex
+}
+
+func newResourcesRegistry() *resourcesRegistry {
+	return &resourcesRegistry{
+		stored:  make(map[resourceKey]string),
+		owners:  make(map[string]string),
+		mapping: make(map[string][]string),
+	}
+}
+
+// resourceHash returns the hash identifying a resource, i.e. the hash of its content if it is read,
+// otherwise the hash of its key, the absolute URL without query & fragment.
+// Resources with empty content are identified by their key too.
+func resourceHash(key string, content []byte) string {
+	if len(content) == 0 {
+		content = []byte(key)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// store registers the resource with the hash downloaded from the URL as resourcePath and returns the path of
+// the stored copy with the same hash in the scope, and whether the resource has to be downloaded.
+// If resourcePath is taken by another resource, it is suffixed by the hash.
+func (r *resourcesRegistry) store(url string, scope string, hash string, resourcePath string) (string, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	key := resourceKey{scope: scope, hash: hash}
+	if stored, ok := r.stored[key]; ok {
+		r.mapping[url] = append(r.mapping[url], stored)
+		return stored, false
+	}
+	if owner, ok := r.owners[resourcePath]; ok && owner != hash {
+		resourcePath = r.uniquePath(resourcePath, hash)
+	}
+	r.owners[resourcePath] = hash
+	r.stored[key] = resourcePath
+	r.mapping[url] = append(r.mapping[url], resourcePath)
+	return resourcePath, true
+}
+
+// uniquePath suffixes the resource path by the shortest prefix of the hash, that is not taken by another resource
+func (r *resourcesRegistry) uniquePath(resourcePath string, hash string) string {
+	ext := path.Ext(resourcePath)
+	base := strings.TrimSuffix(resourcePath, ext)
+	for n := 6; n < len(hash); n += 2 {
+		p := fmt.Sprintf("%s_%s%s", base, hash[:n], ext)
+		if owner, ok := r.owners[p]; !ok || owner == hash {
+			return p
+		}
+	}
+	return fmt.Sprintf("%s_%s%s", base, hash, ext)
+}
+
+// resourcesMapping returns the sorted paths of the stored copies per resource URL
+func (r *resourcesRegistry) resourcesMapping() map[string][]string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	res := make(map[string][]string, len(r.mapping))
+	for url, paths := range r.mapping {
+		sorted := append([]string{}, paths...)
+		sort.Strings(sorted)
+		res[url] = uniqueStrings(sorted)
+	}
+	return res
+}
+
+// resourceContents reads the content of the linked resources once per absolute URL
+type resourceContents struct {
+	contents sync.Map
+}
+
+type resourceContent struct {
+	once    sync.Once
+	content []byte
+	err     error
+}
+
+// read returns the content of the resource with the absolute link, it is read by the first call
+func (c *resourceContents) read(ctx context.Context, r Reader, absLink string) ([]byte, error) {
+	v, _ := c.contents.LoadOrStore(absLink, &resourceContent{})
+	rc := v.(*resourceContent)
+	rc.once.Do(func() {
+		rc.content, rc.err = r.Read(ctx, absLink)
+	})
+	return rc.content, rc.err
+}
@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package reactor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourcesRegistry_store(t *testing.T) {
+	type stored struct {
+		path     string
+		download bool
+	}
+	r := newResourcesRegistry()
+	tests := []struct {
+		name         string
+		url          string
+		scope        string
+		content      string
+		resourcePath string
+		want         stored
+	}{
+		{
+			name:         "first copy",
+			url:          "https://raw.githubusercontent.com/gardener/gardener/master/logo.png",
+			content:      "logo",
+			resourcePath: "__resources/logo_8a1b2c.png",
+			want:         stored{"__resources/logo_8a1b2c.png", true},
+		},
+		{
+			name:         "same content from another URL",
+			url:          "https://github.com/gardener/docforge/blob/master/logo.png?raw=true",
+			content:      "logo",
+			resourcePath: "__resources/logo_3d4e5f.png",
+			want:         stored{"__resources/logo_8a1b2c.png", false},
+		},
+		{
+			name:         "same URL",
+			url:          "https://raw.githubusercontent.com/gardener/gardener/master/logo.png",
+			content:      "logo",
+			resourcePath: "__resources/logo_8a1b2c.png",
+			want:         stored{"__resources/logo_8a1b2c.png", false},
+		},
+		{
+			name:         "same content in another scope",
+			url:          "https://github.com/gardener/docforge/blob/master/logo.png?raw=true",
+			scope:        "docs",
+			content:      "logo",
+			resourcePath: "docs/logo_3d4e5f.png",
+			want:         stored{"docs/logo_3d4e5f.png", true},
+		},
+		{
+			name:         "other content",
+			url:          "https://github.com/gardener/docforge/blob/master/arch.png?raw=true",
+			content:      "arch",
+			resourcePath: "__resources/arch_6a7b8c.png",
+			want:         stored{"__resources/arch_6a7b8c.png", true},
+		},
+		{
+			name:         "other content with a taken name",
+			url:          "https://github.com/gardener/dashboard/blob/master/logo.png?raw=true",
+			content:      "other logo",
+			resourcePath: "__resources/logo_8a1b2c.png",
+			want:         stored{"__resources/logo_8a1b2c_419cc9.png", true},
+		},
+		{
+			name:         "same other content with a taken name",
+			url:          "https://raw.githubusercontent.com/gardener/dashboard/master/logo.png",
+			content:      "other logo",
+			resourcePath: "__resources/logo_8a1b2c.png",
+			want:         stored{"__resources/logo_8a1b2c_419cc9.png", false},
+		},
+		{
+			name:         "empty content",
+			url:          "https://github.com/gardener/docforge/blob/master/empty.png?raw=true",
+			resourcePath: "__resources/empty_9d0e1f.png",
+			want:         stored{"__resources/empty_9d0e1f.png", true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, download := r.store(tt.url, tt.scope, resourceHash(tt.url, []byte(tt.content)), tt.resourcePath)
+			assert.Equal(t, tt.want, stored{path, download})
+		})
+	}
+	assert.Equal(t, map[string][]string{
+		"https://raw.githubusercontent.com/gardener/gardener/master/logo.png":  {"__resources/logo_8a1b2c.png"},
+		"https://github.com/gardener/docforge/blob/master/logo.png?raw=true":   {"__resources/logo_8a1b2c.png", "docs/logo_3d4e5f.png"},
+		"https://github.com/gardener/docforge/blob/master/arch.png?raw=true":   {"__resources/arch_6a7b8c.png"},
+		"https://github.com/gardener/dashboard/blob/master/logo.png?raw=true":  {"__resources/logo_8a1b2c_419cc9.png"},
+		"https://raw.githubusercontent.com/gardener/dashboard/master/logo.png": {"__resources/logo_8a1b2c_419cc9.png"},
+		"https://github.com/gardener/docforge/blob/master/empty.png?raw=true":  {"__resources/empty_9d0e1f.png"},
+	}, r.resourcesMapping())
+}
+
+type countingReader struct {
+	content map[string][]byte
+	err     error
+	reads   int
+	mux     sync.Mutex
+}
+
+func (r *countingReader) Read(_ context.Context, source string) ([]byte, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reads++
+	return r.content[source], r.err
+}
+
+func TestLinkResolver_readResource(t *testing.T) {
+	logo := "https://github.com/gardener/docforge/raw/master/logo.png"
+	tests := []struct {
+		name      string
+		layout    *ResourcesLayout
+		err       error
+		wantHash  string
+		wantCnt   string
+		wantErr   bool
+		wantReads int
+	}{
+		{
+			name:      "resources are read once",
+			wantHash:  resourceHash(logo, []byte("logo")),
+			wantCnt:   "logo",
+			wantReads: 1,
+		},
+		{
+			name:      "content addressed resources are read once",
+			layout:    &ResourcesLayout{Name: "$contenthash$ext"},
+			wantHash:  resourceHash(logo, []byte("logo")),
+			wantCnt:   "logo",
+			wantReads: 1,
+		},
+		{
+			name:      "resources failing to read are identified by URL",
+			err:       errors.New("connection reset"),
+			wantHash:  resourceHash(logo, nil),
+			wantReads: 1,
+		},
+		{
+			name:      "missing resources are not downloaded",
+			err:       resourcehandlers.ErrResourceNotFound(logo),
+			wantErr:   true,
+			wantReads: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &countingReader{content: map[string][]byte{logo: []byte("logo")}, err: tt.err}
+			c := &nodeContentProcessor{resourcesLayout: tt.layout, contents: &resourceContents{}}
+			for _, node := range []*api.Node{{Name: "a.md"}, {Name: "b.md"}} {
+				l := c.newLinkResolver(context.TODO(), r, node, "")
+				hash, content, err := l.readResource(logo, logo)
+				if tt.wantErr {
+					assert.Error(t, err)
+					continue
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantHash, hash)
+				assert.Equal(t, tt.wantCnt, string(content))
+			}
+			assert.Equal(t, tt.wantReads, r.reads)
+		})
+	}
+}
+
+func Test_processDuplicateResources(t *testing.T) {
+	docs := "https://github.com/gardener/docforge/blob/master/docs/"
+	logo := "https://raw.githubusercontent.com/gardener/dashboard/master/logo.png"
+	r := fakeReader{
+		docs + "index.md":        []byte("![logo](./images/logo.png)\n"),
+		docs + "guide.md":        []byte("![logo](" + logo + ")\n"),
+		docs + "images/logo.png": []byte("logo"),
+		logo:                     []byte("logo"),
+	}
+	index := &api.Node{Name: "index.md", Source: docs + "index.md"}
+	guide := &api.Node{Name: "guide.md", Source: docs + "guide.md"}
+	root := &api.Node{Nodes: []*api.Node{index, guide}}
+	root.SetParentsDownwards()
+	c := NewNodeContentProcessor(&fakeDownload{}, &fakeValidator{}, resourcehandlers.NewRegistry(fakeLinkHandler()), &ContentProcessorOptions{ResourcesRoot: "__resources"})
+	c.Prepare(root.Nodes)
+	var got []string
+	for _, node := range []*api.Node{index, guide} {
+		var b bytes.Buffer
+		assert.NoError(t, c.Process(context.TODO(), &b, r, node))
+		got = append(got, b.String())
+	}
+	// the resources with the same content are stored once with the default naming template
+	assert.Equal(t, got[0], got[1])
+	mapping := c.ResourcesMapping()
+	assert.Len(t, mapping, 2)
+	assert.Equal(t, mapping[docs+"images/logo.png"], mapping[logo])
+}
@@ -0,0 +1,407 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+)
+
+// Archive implements resourcehandlers.ResourceHandler interface for the content of a repository ref,
+// served from a local .tar.gz, .tgz, .tar or .zip archive instead of the remote repository.
+// It accepts the GitHub (and GitLab) style URLs: <repository>/(blob|tree|raw)/<ref>/<path>
+type Archive struct {
+	repoURL     string
+	ref         string
+	archivePath string
+	flagVars    map[string]string
+	hugoEnabled bool
+
+	once  sync.Once
+	files map[string][]byte
+	dirs  map[string]bool
+	err   error
+}
+
+// resourceInfo is a parsed URL of a resource in the archive
+type resourceInfo struct {
+	// sep is "/-" for GitLab style URLs
+	sep  string
+	Type string
+	Path string
+}
+
+// NewArchive creates new Archive resource handler, that serves the content of repoURL at ref from archivePath.
+// The archive is read on first use. Archives with a single top-level directory, like the release archives
+// of GitHub and GitLab, are unwrapped.
+func NewArchive(repoURL string, ref string, archivePath string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &Archive{
+		repoURL:     strings.TrimSuffix(repoURL, "/"),
+		ref:         ref,
+		archivePath: archivePath,
+		flagVars:    flagVars,
+		hugoEnabled: hugoEnabled,
+	}
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (a *Archive) Accept(uri string) bool {
+	return a.parse(uri) != nil
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (a *Archive) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	cnt, err := a.Read(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, a.ref, a.flagVars, a.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, uri, func(link string) (string, error) { return a.BuildAbsLink(uri, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (a *Archive) ResolveNodeSelector(_ context.Context, node *api.Node) ([]*api.Node, error) {
+	r := a.parse(node.NodeSelector.Path)
+	if r == nil {
+		return nil, fmt.Errorf("not an archive url: %s", node.NodeSelector.Path)
+	}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	if !a.dirs[r.Path] {
+		if _, ok := a.files[r.Path]; ok {
+			return nil, fmt.Errorf("nodeSelector path %s for node %s is not a directory", node.NodeSelector.Path, node.Path("/"))
+		}
+		return nil, resourcehandlers.ErrResourceNotFound(node.NodeSelector.Path)
+	}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = a.link(r.sep, "tree", r.Path)
+	bPrefix := a.link(r.sep, "blob", r.Path)
+	names := make([]string, 0, len(a.files))
+	for name := range a.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rPath := name
+		if r.Path != "" {
+			if !strings.HasPrefix(name, r.Path+"/") {
+				continue
+			}
+			rPath = strings.TrimPrefix(name, r.Path+"/")
+		}
+		if !strings.HasSuffix(strings.ToLower(rPath), ".md") || resourcehandlers.FilterPath(node, pfs, rPath) {
+			continue
+		}
+		resourcehandlers.BuildNode(vr, bPrefix, rPath)
+	}
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	for _, cn := range vr.Nodes {
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (a *Archive) Read(_ context.Context, uri string) ([]byte, error) {
+	r := a.parse(uri)
+	if r == nil {
+		return nil, fmt.Errorf("not an archive url: %s", uri)
+	}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	if cnt, ok := a.files[r.Path]; ok {
+		return cnt, nil
+	}
+	if a.dirs[r.Path] {
+		return nil, fmt.Errorf("not a file url: %s", uri)
+	}
+	return nil, resourcehandlers.ErrResourceNotFound(uri)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+// Git info is not available, as archives don't contain the repository history
+func (a *Archive) ReadGitInfo(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (a *Archive) ResourceName(link string) (string, string) {
+	r := a.parse(link)
+	if r == nil {
+		return "", ""
+	}
+	ext := path.Ext(r.Path)
+	name := strings.TrimSuffix(path.Base(r.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+// Links starting with '/' are relative to the repository root. Links pointing outside
+// the repository are resolved as URL references of the source and are not verified.
+func (a *Archive) BuildAbsLink(source, link string) (string, error) {
+	l, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	if l.IsAbs() {
+		return link, nil // already absolute
+	}
+	r := a.parse(source)
+	if r == nil {
+		return "", fmt.Errorf("not an archive url: %s", source)
+	}
+	if err = a.load(); err != nil {
+		return "", err
+	}
+	p := r.Path
+	if strings.HasPrefix(l.Path, "/") {
+		p = path.Clean(l.Path)
+	} else if l.Path != "" {
+		base := r.Path
+		if _, ok := a.files[r.Path]; ok {
+			base = path.Dir(r.Path)
+		}
+		p = path.Join(base, l.Path)
+	}
+	p = strings.TrimPrefix(p, "/")
+	if p == "." {
+		p = ""
+	}
+	if p == ".." || strings.HasPrefix(p, "../") {
+		s, err := url.Parse(source)
+		if err != nil {
+			return "", err
+		}
+		return s.ResolveReference(l).String(), nil
+	}
+	var res string
+	if _, ok := a.files[p]; ok {
+		res = a.link(r.sep, "blob", p)
+	} else {
+		res = a.link(r.sep, "tree", p)
+	}
+	u, err := url.Parse(res)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = l.RawQuery
+	u.Fragment = l.Fragment
+	res = u.String()
+	if _, ok := a.files[p]; !ok && !a.dirs[p] {
+		return res, resourcehandlers.ErrResourceNotFound(res)
+	}
+	return res, nil
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+// The content is served from the archive, hence the link is not changed
+func (a *Archive) GetRawFormatLink(absLink string) (string, error) {
+	return absLink, nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (a *Archive) GetClient() httpclient.Client {
+	return nil
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+func (a *Archive) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return -1, -1, time.Now(), nil
+}
+
+// parse returns the resource info of uri, or nil if uri is not a resource of the archived repository ref
+func (a *Archive) parse(uri string) *resourceInfo {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.ForceQuery = false
+	s := u.String()
+	if len(s) <= len(a.repoURL) || !strings.EqualFold(s[:len(a.repoURL)], a.repoURL) || s[len(a.repoURL)] != '/' {
+		return nil
+	}
+	r := &resourceInfo{}
+	rest := s[len(a.repoURL)+1:]
+	if strings.HasPrefix(rest, "-/") {
+		r.sep = "/-"
+		rest = strings.TrimPrefix(rest, "-/")
+	}
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) < 2 || (parts[0] != "blob" && parts[0] != "tree" && parts[0] != "raw") {
+		return nil
+	}
+	r.Type = parts[0]
+	rest = parts[1]
+	if rest != a.ref && !strings.HasPrefix(rest, a.ref+"/") {
+		return nil
+	}
+	r.Path = strings.Trim(strings.TrimPrefix(rest, a.ref), "/")
+	return r
+}
+
+// link builds the URL of a resource with the given type and path
+func (a *Archive) link(sep string, resourceType string, p string) string {
+	l := fmt.Sprintf("%s%s/%s/%s", a.repoURL, sep, resourceType, a.ref)
+	if p != "" {
+		l = fmt.Sprintf("%s/%s", l, p)
+	}
+	return l
+}
+
+// load reads the archive content once
+func (a *Archive) load() error {
+	a.once.Do(func() {
+		a.files, a.dirs, a.err = readArchive(a.archivePath)
+	})
+	return a.err
+}
+
+// readArchive reads the files of .tar.gz, .tgz, .tar or .zip archive and
+// returns them with the set of directories, relative to the archive root
+func readArchive(archivePath string) (map[string][]byte, map[string]bool, error) {
+	files := map[string][]byte{}
+	add := func(name string, r io.Reader) error {
+		cnt, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("reading %s from archive %s fails: %v", name, archivePath, err)
+		}
+		if name = cleanName(name); name != "" {
+			files[name] = cnt
+		}
+		return nil
+	}
+	lName := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lName, ".zip"):
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening archive %s fails: %v", archivePath, err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading %s from archive %s fails: %v", f.Name, archivePath, err)
+			}
+			err = add(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	case strings.HasSuffix(lName, ".tar.gz"), strings.HasSuffix(lName, ".tgz"), strings.HasSuffix(lName, ".tar"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening archive %s fails: %v", archivePath, err)
+		}
+		defer f.Close()
+		var r io.Reader = f
+		if !strings.HasSuffix(lName, ".tar") {
+			gr, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, nil, fmt.Errorf("opening archive %s fails: %v", archivePath, err)
+			}
+			defer gr.Close()
+			r = gr
+		}
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading archive %s fails: %v", archivePath, err)
+			}
+			if h.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err = add(h.Name, tr); err != nil {
+				return nil, nil, err
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported archive format %s, expected .tar.gz, .tgz, .tar or .zip", archivePath)
+	}
+	files = unwrap(files)
+	dirs := map[string]bool{"": true}
+	for name := range files {
+		for d := path.Dir(name); d != "."; d = path.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	return files, dirs, nil
+}
+
+// cleanName returns the slash separated relative path of an archive entry
+func cleanName(name string) string {
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return ""
+	}
+	return name
+}
+
+// unwrap removes the top-level directory, if all files are located in it
+func unwrap(files map[string][]byte) map[string][]byte {
+	var top string
+	for name := range files {
+		i := strings.Index(name, "/")
+		if i < 0 || (top != "" && name[:i] != top) {
+			return files
+		}
+		top = name[:i]
+	}
+	if top == "" {
+		return files
+	}
+	unwrapped := make(map[string][]byte, len(files))
+	for name, cnt := range files {
+		unwrapped[strings.TrimPrefix(name, top+"/")] = cnt
+	}
+	return unwrapped
+}
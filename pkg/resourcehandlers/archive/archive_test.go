@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var files = map[string]string{
+	"docforge-1.0.0/manifest.yaml":            "structure:\n- name: readme\n  source: ./README.md\n- name: docs\n  nodesSelector:\n    path: docs\n",
+	"docforge-1.0.0/README.md":                "# Docforge",
+	"docforge-1.0.0/docs/overview.md":         "# Overview",
+	"docforge-1.0.0/docs/guides/install.md":   "# Install",
+	"docforge-1.0.0/docs/images/logo.png":     "png",
+	"docforge-1.0.0/docs/guides/internal.txt": "txt",
+}
+
+func sortedNames() []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeTarGz(fn string) {
+	f, err := os.Create(fn)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, name := range sortedNames() {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err = tw.Write([]byte(files[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gw.Close()).To(Succeed())
+}
+
+func writeZip(fn string) {
+	f, err := os.Create(fn)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, name := range sortedNames() {
+		w, err := zw.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte(files[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(zw.Close()).To(Succeed())
+}
+
+var _ = Describe("Archive", func() {
+	var (
+		dir string
+		rh  resourcehandlers.ResourceHandler
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "docforge-archive")
+		Expect(err).NotTo(HaveOccurred())
+		fn := filepath.Join(dir, "docforge-1.0.0.tar.gz")
+		writeTarGz(fn)
+		rh = archive.NewArchive("https://github.com/gardener/docforge", "v1.0.0", fn, map[string]string{}, false)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Describe("Accept", func() {
+		It("accepts the mapped repository ref", func() {
+			Expect(rh.Accept("https://github.com/gardener/docforge/blob/v1.0.0/README.md")).To(BeTrue())
+			Expect(rh.Accept("https://github.com/gardener/docforge/tree/v1.0.0")).To(BeTrue())
+			Expect(rh.Accept("https://github.com/Gardener/Docforge/raw/v1.0.0/docs/images/logo.png?raw=true")).To(BeTrue())
+		})
+		It("rejects other repositories and refs", func() {
+			Expect(rh.Accept("https://github.com/gardener/docforge/blob/master/README.md")).To(BeFalse())
+			Expect(rh.Accept("https://github.com/gardener/docforge/blob/v1.0.0.1/README.md")).To(BeFalse())
+			Expect(rh.Accept("https://github.com/gardener/docforge-other/blob/v1.0.0/README.md")).To(BeFalse())
+			Expect(rh.Accept("https://github.com/gardener/docforge/pulls")).To(BeFalse())
+		})
+	})
+
+	Describe("Read", func() {
+		It("reads file content", func() {
+			cnt, err := rh.Read(ctx, "https://github.com/gardener/docforge/blob/v1.0.0/docs/guides/install.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cnt)).To(Equal("# Install"))
+		})
+		It("reads file content from zip archive", func() {
+			fn := filepath.Join(dir, "docforge.zip")
+			writeZip(fn)
+			rh = archive.NewArchive("https://gitlab.com/gardener/docforge/", "v1.0.0", fn, map[string]string{}, false)
+			cnt, err := rh.Read(ctx, "https://gitlab.com/gardener/docforge/-/blob/v1.0.0/docs/overview.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cnt)).To(Equal("# Overview"))
+		})
+		It("returns resource not found for missing file", func() {
+			_, err := rh.Read(ctx, "https://github.com/gardener/docforge/blob/v1.0.0/missing.md")
+			Expect(err).To(Equal(resourcehandlers.ErrResourceNotFound("https://github.com/gardener/docforge/blob/v1.0.0/missing.md")))
+		})
+		It("fails for missing archive", func() {
+			rh = archive.NewArchive("https://github.com/gardener/docforge", "v1.0.0", filepath.Join(dir, "missing.tgz"), map[string]string{}, false)
+			_, err := rh.Read(ctx, "https://github.com/gardener/docforge/blob/v1.0.0/README.md")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("missing.tgz"))
+		})
+	})
+
+	Describe("BuildAbsLink", func() {
+		source := "https://github.com/gardener/docforge/blob/v1.0.0/docs/overview.md"
+		It("resolves relative links", func() {
+			Expect(rh.BuildAbsLink(source, "guides/install.md#steps")).To(Equal("https://github.com/gardener/docforge/blob/v1.0.0/docs/guides/install.md#steps"))
+			Expect(rh.BuildAbsLink(source, "../README.md")).To(Equal("https://github.com/gardener/docforge/blob/v1.0.0/README.md"))
+			Expect(rh.BuildAbsLink(source, "./guides")).To(Equal("https://github.com/gardener/docforge/tree/v1.0.0/docs/guides"))
+			Expect(rh.BuildAbsLink(source, "/docs/images/logo.png")).To(Equal("https://github.com/gardener/docforge/blob/v1.0.0/docs/images/logo.png"))
+		})
+		It("resolves links relative to a directory", func() {
+			Expect(rh.BuildAbsLink("https://github.com/gardener/docforge/tree/v1.0.0/docs", "overview.md")).To(Equal("https://github.com/gardener/docforge/blob/v1.0.0/docs/overview.md"))
+		})
+		It("keeps absolute links and links outside the repository", func() {
+			Expect(rh.BuildAbsLink(source, "https://gardener.cloud")).To(Equal("https://gardener.cloud"))
+			Expect(rh.BuildAbsLink(source, "../../../../issues")).To(Equal("https://github.com/gardener/issues"))
+		})
+		It("returns resource not found for missing target", func() {
+			link, err := rh.BuildAbsLink(source, "missing.md")
+			Expect(link).To(Equal("https://github.com/gardener/docforge/tree/v1.0.0/docs/missing.md"))
+			Expect(err).To(Equal(resourcehandlers.ErrResourceNotFound(link)))
+		})
+	})
+
+	Describe("ResolveNodeSelector", func() {
+		It("builds the markdown hierarchy", func() {
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/gardener/docforge/tree/v1.0.0/docs"}}
+			nodes, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			install := &api.Node{Name: "install.md", Source: "https://github.com/gardener/docforge/blob/v1.0.0/docs/guides/install.md"}
+			guides := &api.Node{Name: "guides", Nodes: []*api.Node{install}, Properties: map[string]interface{}{api.ContainerNodeSourceLocation: "https://github.com/gardener/docforge/tree/v1.0.0/docs/guides"}}
+			install.SetParent(guides)
+			overview := &api.Node{Name: "overview.md", Source: "https://github.com/gardener/docforge/blob/v1.0.0/docs/overview.md"}
+			Expect(nodes).To(Equal([]*api.Node{guides, overview}))
+		})
+		It("applies the depth filter", func() {
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/gardener/docforge/tree/v1.0.0/docs", Depth: 1}}
+			nodes, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(HaveLen(1))
+			Expect(nodes[0].Name).To(Equal("overview.md"))
+		})
+		It("returns resource not found for missing directory", func() {
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/gardener/docforge/tree/v1.0.0/missing"}}
+			_, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).To(Equal(resourcehandlers.ErrResourceNotFound("https://github.com/gardener/docforge/tree/v1.0.0/missing")))
+		})
+	})
+
+	Describe("ResolveDocumentation", func() {
+		It("resolves the relative manifest paths", func() {
+			doc, err := rh.ResolveDocumentation(ctx, "https://github.com/gardener/docforge/blob/v1.0.0/manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Structure).To(HaveLen(2))
+			Expect(doc.Structure[0].Source).To(Equal("https://github.com/gardener/docforge/blob/v1.0.0/README.md"))
+			Expect(doc.Structure[1].NodeSelector.Path).To(Equal("https://github.com/gardener/docforge/tree/v1.0.0/docs"))
+		})
+	})
+
+	Describe("ResourceName", func() {
+		It("returns name and extension", func() {
+			name, ext := rh.ResourceName("https://github.com/gardener/docforge/blob/v1.0.0/docs/overview.md")
+			Expect(name).To(Equal("overview"))
+			Expect(ext).To(Equal(".md"))
+		})
+	})
+})
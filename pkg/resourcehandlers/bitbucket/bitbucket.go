@@ -0,0 +1,593 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitinfo"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// apiPath is the path of Bitbucket Server REST API 1.0
+	apiPath = "/rest/api/1.0"
+	// perPage is the page size used for paginated API calls
+	perPage = 500
+)
+
+// Bitbucket implements resourcehandlers.ResourceHandler interface using Bitbucket Server REST API 1.0
+type Bitbucket struct {
+	client        httpclient.Client
+	username      string
+	token         string
+	acceptedHosts []string
+	flagVars      map[string]string
+	hugoEnabled   bool
+	defBranches   map[string]string
+	muxDefBr      sync.Mutex
+}
+
+// NewBitbucket creates new Bitbucket resource handler.
+// The token, if not empty, is sent as bearer token on each API call, or as password with basic authentication when username is set.
+func NewBitbucket(client httpclient.Client, username string, token string, acceptedHosts []string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &Bitbucket{
+		client:        client,
+		username:      username,
+		token:         token,
+		acceptedHosts: acceptedHosts,
+		flagVars:      flagVars,
+		hugoEnabled:   hugoEnabled,
+		defBranches:   make(map[string]string),
+	}
+}
+
+// ResourceInfo describes a Bitbucket Server resource URL like
+// https://bitbucket.example.com/(projects|users)/<key>/repos/<slug>/(browse|raw)/path?at=ref
+// Bitbucket Server uses the same URL for files and directories, and the ref is optional.
+type ResourceInfo struct {
+	URL     *url.URL
+	Owner   string
+	Project string
+	Repo    string
+	Type    string
+	Ref     string
+	Path    string
+}
+
+// BuildResourceInfo parses Bitbucket Server resource URL
+func BuildResourceInfo(uri string) (*ResourceInfo, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.SplitN(strings.Trim(u.Path, "/"), "/", 6)
+	if len(segments) < 5 || (segments[0] != "projects" && segments[0] != "users") || segments[2] != "repos" {
+		return nil, fmt.Errorf("unsupported Bitbucket URL: %s. Need /(projects|users)/<key>/repos/<slug>/(browse|raw)/<path>", uri)
+	}
+	r := &ResourceInfo{
+		URL:     u,
+		Owner:   segments[0],
+		Project: segments[1],
+		Repo:    segments[3],
+		Type:    segments[4],
+		Ref:     u.Query().Get("at"),
+	}
+	if r.Type != "browse" && r.Type != "raw" {
+		return nil, fmt.Errorf("unsupported Bitbucket URL type %s: %s", r.Type, uri)
+	}
+	if len(segments) > 5 {
+		r.Path = strings.Trim(segments[5], "/")
+	}
+	return r, nil
+}
+
+// String returns the Bitbucket resource URL
+func (r *ResourceInfo) String() string {
+	return r.build(r.Type, r.Path)
+}
+
+// build returns the Bitbucket URL for the resource type and path in the same repository and ref
+func (r *ResourceInfo) build(tp string, p string) string {
+	uri := r.prefix(tp, p)
+	if r.Ref != "" {
+		uri = fmt.Sprintf("%s?%s", uri, url.Values{"at": []string{r.Ref}}.Encode())
+	}
+	return uri
+}
+
+// prefix returns the Bitbucket URL for the resource type and path without the ref
+func (r *ResourceInfo) prefix(tp string, p string) string {
+	uri := fmt.Sprintf("%s://%s/%s/%s/repos/%s/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Project, r.Repo, tp)
+	if p != "" {
+		uri = fmt.Sprintf("%s/%s", uri, p)
+	}
+	return uri
+}
+
+// apiProject returns the project key used by the API, personal repositories are referenced by '~' prefixed user name
+func (r *ResourceInfo) apiProject() string {
+	if r.Owner == "users" {
+		return "~" + r.Project
+	}
+	return r.Project
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (b *Bitbucket) Accept(uri string) bool {
+	r, err := BuildResourceInfo(uri)
+	if err != nil || (r.URL.Scheme != "https" && r.URL.Scheme != "http") {
+		return false
+	}
+	for _, h := range b.acceptedHosts {
+		if h == r.URL.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (b *Bitbucket) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	r, err := b.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := b.readFile(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, r.Ref, b.flagVars, b.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, uri, func(link string) (string, error) { return b.buildAbsLink(ctx, r, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (b *Bitbucket) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.Node, error) {
+	r, err := b.getResolvedResourceInfo(ctx, node.NodeSelector.Path)
+	if err != nil {
+		return nil, err
+	}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	files, err := b.getFiles(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	// the ref query is appended after the node hierarchy is built
+	prefix := r.prefix("browse", r.Path)
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = prefix
+	for _, f := range files {
+		// skip node if it is not a markdown file
+		if !strings.HasSuffix(strings.ToLower(f), ".md") {
+			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, f)
+			continue
+		}
+		if resourcehandlers.FilterPath(node, pfs, f) {
+			continue
+		}
+		resourcehandlers.BuildNode(vr, prefix, f)
+	}
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	query := "?" + url.Values{"at": []string{r.Ref}}.Encode()
+	for _, cn := range vr.Nodes {
+		appendQuery(cn, query)
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (b *Bitbucket) Read(ctx context.Context, uri string) ([]byte, error) {
+	r, err := b.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return b.readFile(ctx, r)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+func (b *Bitbucket) ReadGitInfo(ctx context.Context, uri string) ([]byte, error) {
+	r, err := b.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("until", r.Ref)
+	q.Set("path", r.Path)
+	var commits []*gitinfo.Commit
+	if err = b.getAll(ctx, r, "/commits", q, uri, func(values json.RawMessage) error {
+		var cs []*commit
+		if err := json.Unmarshal(values, &cs); err != nil {
+			return err
+		}
+		for _, c := range cs {
+			commits = append(commits, &gitinfo.Commit{
+				SHA:            c.ID,
+				Message:        c.Message,
+				AuthorName:     c.Author.Name,
+				AuthorEmail:    c.Author.EmailAddress,
+				CommitterName:  c.Committer.Name,
+				CommitterEmail: c.Committer.EmailAddress,
+				Date:           time.UnixMilli(c.CommitterTimestamp).UTC(),
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	gitInfo := gitinfo.Transform(commits)
+	if gitInfo == nil {
+		return nil, nil
+	}
+	if len(r.Ref) > 0 {
+		gitInfo.SHAAlias = &r.Ref
+	}
+	if len(r.Path) > 0 {
+		gitInfo.Path = &r.Path
+	}
+	webURL := fmt.Sprintf("%s://%s/%s/%s/repos/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Project, r.Repo)
+	gitInfo.WebURL = &webURL
+	return gitinfo.Marshal(gitInfo)
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (b *Bitbucket) ResourceName(link string) (string, string) {
+	r, err := BuildResourceInfo(link)
+	if err != nil {
+		return "", ""
+	}
+	ext := path.Ext(r.Path)
+	name := strings.TrimSuffix(path.Base(r.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+func (b *Bitbucket) BuildAbsLink(source, link string) (string, error) {
+	r, err := BuildResourceInfo(source)
+	if err != nil {
+		return "", err
+	}
+	return b.buildAbsLink(context.Background(), r, link)
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (b *Bitbucket) GetRawFormatLink(absLink string) (string, error) {
+	r, err := BuildResourceInfo(absLink)
+	if err != nil {
+		return "", err
+	}
+	return r.build("raw", r.Path), nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (b *Bitbucket) GetClient() httpclient.Client {
+	return b.client
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+// Bitbucket Server doesn't report rate limits
+func (b *Bitbucket) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return -1, -1, time.Now(), nil
+}
+
+// SourceKey implements the resourcehandlers.SourceKeyer#SourceKey
+// The key keeps the ref in the `at` query parameter, the other query parameters and the fragment are dropped.
+func (b *Bitbucket) SourceKey(uri string) string {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return uri
+	}
+	return r.String()
+}
+
+//========================= resourcehandlers.RefResolver =======================================================
+
+// ResourceRef implements the resourcehandlers.RefResolver#ResourceRef
+// URLs without ref reference the default branch
+func (b *Bitbucket) ResourceRef(uri string) (string, string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", "", err
+	}
+	ref := r.Ref
+	if ref == "" {
+		ref = "DEFAULT_BRANCH"
+	}
+	return fmt.Sprintf("%s://%s/%s/%s/repos/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Project, r.Repo), ref, nil
+}
+
+// ResolveRef implements the resourcehandlers.RefResolver#ResolveRef
+func (b *Bitbucket) ResolveRef(ctx context.Context, uri string) (string, error) {
+	r, err := b.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("until", r.Ref)
+	q.Set("limit", "1")
+	body, err := b.call(ctx, r, "/commits", q, uri)
+	if err != nil {
+		return "", err
+	}
+	p := &page{}
+	var cs []*commit
+	if err = json.Unmarshal(body, p); err == nil {
+		err = json.Unmarshal(p.Values, &cs)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unexpected commits response for %s: %v", uri, err)
+	}
+	if len(cs) == 0 {
+		return "", resourcehandlers.ErrResourceNotFound(uri)
+	}
+	return cs[0].ID, nil
+}
+
+// PinRef implements the resourcehandlers.RefResolver#PinRef
+func (b *Bitbucket) PinRef(uri string, sha string) (string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", err
+	}
+	u := *r.URL
+	q := u.Query()
+	q.Set("at", sha)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+//==============================================================================================================
+
+// page is Bitbucket Server paged API response
+type page struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+// user is Bitbucket Server commit author or committer
+type user struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// commit is Bitbucket Server repository commit
+type commit struct {
+	ID                 string `json:"id"`
+	Message            string `json:"message"`
+	Author             user   `json:"author"`
+	Committer          user   `json:"committer"`
+	CommitterTimestamp int64  `json:"committerTimestamp"`
+}
+
+// ref is Bitbucket Server branch or tag
+type ref struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+}
+
+// pathType is the response of the Bitbucket Server browse API with type query
+type pathType struct {
+	Type string `json:"type"`
+}
+
+// readFile reads a raw file from Bitbucket Server
+func (b *Bitbucket) readFile(ctx context.Context, r *ResourceInfo) ([]byte, error) {
+	q := url.Values{}
+	q.Set("at", r.Ref)
+	return b.call(ctx, r, "/raw/"+escape(r.Path), q, r.String())
+}
+
+// getFiles lists recursively the files under the resource path
+func (b *Bitbucket) getFiles(ctx context.Context, r *ResourceInfo) ([]string, error) {
+	q := url.Values{}
+	q.Set("at", r.Ref)
+	var files []string
+	endpoint := "/files"
+	if r.Path != "" {
+		endpoint = endpoint + "/" + escape(r.Path)
+	}
+	if err := b.getAll(ctx, r, endpoint, q, r.String(), func(values json.RawMessage) error {
+		var fs []string
+		if err := json.Unmarshal(values, &fs); err != nil {
+			return err
+		}
+		files = append(files, fs...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// getAll calls paged repository API endpoint until the last page is read
+func (b *Bitbucket) getAll(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string, collect func(values json.RawMessage) error) error {
+	q.Set("limit", strconv.Itoa(perPage))
+	start := 0
+	for {
+		q.Set("start", strconv.Itoa(start))
+		body, err := b.call(ctx, r, endpoint, q, uri)
+		if err != nil {
+			return err
+		}
+		p := &page{}
+		if err = json.Unmarshal(body, p); err != nil {
+			return fmt.Errorf("unexpected response for %s: %v", uri, err)
+		}
+		if err = collect(p.Values); err != nil {
+			return fmt.Errorf("unexpected response for %s: %v", uri, err)
+		}
+		if p.IsLastPage || p.NextPageStart <= start {
+			return nil
+		}
+		start = p.NextPageStart
+	}
+}
+
+// call calls repository API endpoint and returns the response body
+func (b *Bitbucket) call(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s://%s%s/projects/%s/repos/%s%s", r.URL.Scheme, r.URL.Host, apiPath, url.PathEscape(r.apiProject()), url.PathEscape(r.Repo), endpoint)
+	if len(q) > 0 {
+		apiURL = apiURL + "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if b.token != "" {
+		if b.username != "" {
+			req.SetBasicAuth(b.username, b.token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+b.token)
+		}
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resourcehandlers.ErrResourceNotFound(uri)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("reading %s fails with HTTP status: %d", uri, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// buildAbsLink builds absolute link if <link> is relative using <source> as a base
+// resourcehandlers.ErrResourceNotFound if target resource doesn't exist
+func (b *Bitbucket) buildAbsLink(ctx context.Context, source *ResourceInfo, link string) (string, error) {
+	l, err := url.Parse(strings.TrimSuffix(link, "/"))
+	if err != nil {
+		return "", err
+	}
+	if l.IsAbs() {
+		return link, nil // already absolute
+	}
+	// build URL based on source path
+	var u *url.URL
+	if u, err = url.Parse("/" + source.Path); err != nil {
+		return "", err
+	}
+	if u, err = u.Parse(l.Path); err != nil {
+		return "", err
+	}
+	relPath := strings.TrimPrefix(u.Path, "/")
+	res, _ := url.Parse(source.build("browse", relPath))
+	// keep the ref query and append the link query
+	if l.RawQuery != "" {
+		res.RawQuery = res.RawQuery + "&" + l.RawQuery
+	}
+	res.Fragment = l.Fragment
+	absLink := res.String()
+	// check if the resource exists
+	q := url.Values{}
+	q.Set("at", source.Ref)
+	q.Set("type", "true")
+	endpoint := "/browse"
+	if relPath != "" {
+		endpoint = endpoint + "/" + escape(relPath)
+	}
+	var body []byte
+	if body, err = b.call(ctx, source, endpoint, q, absLink); err != nil {
+		if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+			return absLink, err
+		}
+		return "", fmt.Errorf("cannot determine resource for path %s and source %s: %v", relPath, source.String(), err)
+	}
+	pt := &pathType{}
+	if err = json.Unmarshal(body, pt); err != nil || pt.Type == "" {
+		return "", fmt.Errorf("cannot determine resource type for path %s and source %s", relPath, source.String())
+	}
+	return absLink, nil
+}
+
+// getResolvedResourceInfo builds ResourceInfo and resolves missing ref or 'DEFAULT_BRANCH' to repository default branch
+func (b *Bitbucket) getResolvedResourceInfo(ctx context.Context, uri string) (*ResourceInfo, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return nil, err
+	}
+	if r.Ref == "" || r.Ref == "DEFAULT_BRANCH" {
+		if r.Ref, err = b.getDefaultBranch(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// getDefaultBranch gets the default branch for given repository
+func (b *Bitbucket) getDefaultBranch(ctx context.Context, r *ResourceInfo) (string, error) {
+	b.muxDefBr.Lock()
+	defer b.muxDefBr.Unlock()
+	key := fmt.Sprintf("%s/%s/%s", r.URL.Host, r.apiProject(), r.Repo)
+	if def, ok := b.defBranches[key]; ok {
+		return def, nil
+	}
+	body, err := b.call(ctx, r, "/branches/default", nil, r.String())
+	if err != nil {
+		return "", err
+	}
+	def := &ref{}
+	if err = json.Unmarshal(body, def); err != nil {
+		return "", fmt.Errorf("unexpected default branch response for %s: %v", r.String(), err)
+	}
+	b.defBranches[key] = def.DisplayID
+	return def.DisplayID, nil
+}
+
+// appendQuery appends the query to the node sources and container source locations in the node hierarchy
+func appendQuery(node *api.Node, query string) {
+	if node.Source != "" {
+		node.Source = node.Source + query
+	}
+	if sl, ok := node.Properties[api.ContainerNodeSourceLocation].(string); ok {
+		node.Properties[api.ContainerNodeSourceLocation] = sl + query
+	}
+	for _, n := range node.Nodes {
+		appendQuery(n, query)
+	}
+}
+
+// escape encodes a file path preserving the '/' separators
+func escape(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bitbucket_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/bitbucket"
+	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const repoAPI = "/rest/api/1.0/projects/PRJ/repos/repo"
+
+var _ = Describe("Bitbucket", func() {
+	var (
+		server *httptest.Server
+		mux    *http.ServeMux
+		rh     resourcehandlers.ResourceHandler
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		u, _ := url.Parse(server.URL)
+		rh = bitbucket.NewBitbucket(server.Client(), "", "token", []string{u.Host}, map[string]string{}, false)
+		ctx = context.TODO()
+		mux.HandleFunc(repoAPI+"/branches/default", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, `{"id": "refs/heads/main", "displayId": "main"}`)
+		})
+		mux.HandleFunc(repoAPI+"/raw/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer token"))
+			Expect(r.URL.Query().Get("at")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, "# README")
+		})
+		mux.HandleFunc(repoAPI+"/raw/manifest.yaml", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, "structure:\n- source: docs/README.md\n")
+		})
+		mux.HandleFunc(repoAPI+"/browse/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("type")).To(Equal("true"))
+			_, _ = fmt.Fprint(w, `{"type": "FILE"}`)
+		})
+		mux.HandleFunc(repoAPI+"/files/docs", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("start") == "0" {
+				_, _ = fmt.Fprint(w, `{"values": ["README.md", "guides/setup.md"], "isLastPage": false, "nextPageStart": 2}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"values": ["guides/logo.png"], "isLastPage": true}`)
+		})
+		mux.HandleFunc(repoAPI+"/commits", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("limit") == "1" {
+				Expect(r.URL.Query().Get("until")).To(Equal("main"))
+				_, _ = fmt.Fprint(w, `{"isLastPage": true, "values": [{"id": "c2"}]}`)
+				return
+			}
+			Expect(r.URL.Query().Get("path")).To(Equal("docs/README.md"))
+			Expect(r.URL.Query().Get("until")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, `{"isLastPage": true, "values": [
+  {"id": "c2", "message": "update", "author": {"name": "Jane", "emailAddress": "jane@example.com"}, "committer": {"emailAddress": "jane@example.com"}, "committerTimestamp": 1643709600000},
+  {"id": "c1", "message": "initial", "author": {"name": "John", "emailAddress": "john@example.com"}, "committer": {"emailAddress": "john@example.com"}, "committerTimestamp": 1641031200000}
+]}`)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	browse := func(p string) string {
+		return fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/%s?at=main", server.URL, p)
+	}
+
+	It("accepts Bitbucket URLs of the configured hosts", func() {
+		Expect(rh.Accept(browse("docs/README.md"))).To(BeTrue())
+		Expect(rh.Accept(fmt.Sprintf("%s/owner/repo/blob/main/README.md", server.URL))).To(BeFalse())
+	})
+
+	It("reads files", func() {
+		got, err := rh.Read(ctx, fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/docs/README.md", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("# README"))
+		_, err = rh.Read(ctx, browse("missing.md"))
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+	})
+
+	It("resolves node selector", func() {
+		node := &api.Node{NodeSelector: &api.NodeSelector{Path: browse("docs")}}
+		got, err := rh.ResolveNodeSelector(ctx, node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(got)).To(Equal(2))
+		Expect(got[0].Source).To(Equal(browse("docs/README.md")))
+		Expect(got[1].Name).To(Equal("guides"))
+		Expect(got[1].Properties[api.ContainerNodeSourceLocation]).To(Equal(browse("docs/guides")))
+		Expect(got[1].Nodes[0].Source).To(Equal(browse("docs/guides/setup.md")))
+	})
+
+	It("resolves documentation", func() {
+		doc, err := rh.ResolveDocumentation(ctx, browse("manifest.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Structure[0].Source).To(Equal(browse("docs/README.md")))
+	})
+
+	It("builds absolute links", func() {
+		got, err := rh.BuildAbsLink(browse("docs/guides/setup.md"), "../README.md#top")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(browse("docs/README.md") + "#top"))
+		got, err = rh.BuildAbsLink(browse("docs/README.md"), "missing.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		Expect(got).To(Equal(browse("docs/missing.md")))
+	})
+
+	It("keeps the ref in the source keys", func() {
+		sk, ok := rh.(resourcehandlers.SourceKeyer)
+		Expect(ok).To(BeTrue())
+		Expect(sk.SourceKey(browse("docs/README.md") + "&until=c1#top")).To(Equal(browse("docs/README.md")))
+		Expect(sk.SourceKey(fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/docs/README.md#top", server.URL))).To(Equal(fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/docs/README.md", server.URL)))
+	})
+
+	It("returns raw links", func() {
+		got, err := rh.GetRawFormatLink(browse("docs/logo.png"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(fmt.Sprintf("%s/projects/PRJ/repos/repo/raw/docs/logo.png?at=main", server.URL)))
+	})
+
+	It("reads git info", func() {
+		got, err := rh.ReadGitInfo(ctx, browse("docs/README.md"))
+		Expect(err).NotTo(HaveOccurred())
+		info := &pg.GitInfo{}
+		Expect(json.Unmarshal(got, info)).To(Succeed())
+		Expect(*info.LastModifiedDate).To(Equal("2022-02-01 10:00:00"))
+		Expect(*info.PublishDate).To(Equal("2022-01-01 10:00:00"))
+		Expect(info.Author.GetEmail()).To(Equal("john@example.com"))
+	})
+
+	It("resolves and pins refs", func() {
+		rr, ok := rh.(resourcehandlers.RefResolver)
+		Expect(ok).To(BeTrue())
+		noRef := fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/docs/README.md", server.URL)
+		repo, ref, err := rr.ResourceRef(noRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo).To(Equal(server.URL + "/projects/PRJ/repos/repo"))
+		Expect(ref).To(Equal("DEFAULT_BRANCH"))
+		sha, err := rr.ResolveRef(ctx, noRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sha).To(Equal("c2"))
+		got, err := rr.PinRef(browse("docs/README.md"), "c2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(fmt.Sprintf("%s/projects/PRJ/repos/repo/browse/docs/README.md?at=c2", server.URL)))
+	})
+})
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package git_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/git"
+	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitinterface"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// localRemoteGit redirects the remote repository URLs to local bare repositories
+type localRemoteGit struct {
+	gitinterface.Git
+	remotes map[string]string
+}
+
+func (l *localRemoteGit) PlainCloneContext(ctx context.Context, path string, isBare bool, o *gogit.CloneOptions) (gitinterface.Repository, error) {
+	o.URL = l.remotes[o.URL]
+	return l.Git.PlainCloneContext(ctx, path, isBare, o)
+}
+
+func (l *localRemoteGit) ListRemote(url string, o *gogit.ListOptions) ([]*plumbing.Reference, error) {
+	return l.Git.ListRemote(l.remotes[url], o)
+}
+
+var _ = Describe("Git with local bare repository", func() {
+	var (
+		tmpDir  string
+		ctx     context.Context
+		gh      resourcehandlers.ResourceHandler
+		g       gitinterface.Git
+		workDir string
+		w       *gogit.Worktree
+		first   plumbing.Hash
+		head    plumbing.Hash
+	)
+
+	commitFiles := func(w *gogit.Worktree, dir string, files map[string]string, author string, when time.Time) plumbing.Hash {
+		for name, content := range files {
+			fn := filepath.Join(dir, filepath.FromSlash(name))
+			Expect(os.MkdirAll(filepath.Dir(fn), 0755)).To(Succeed())
+			Expect(os.WriteFile(fn, []byte(content), 0644)).To(Succeed())
+			_, err := w.Add(name)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		sig := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+		h, err := w.Commit("Update docs", &gogit.CommitOptions{Author: sig, Committer: sig})
+		Expect(err).NotTo(HaveOccurred())
+		return h
+	}
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		tmpDir, err = os.MkdirTemp("", "docforge-git-test")
+		Expect(err).NotTo(HaveOccurred())
+		// prepare the origin repository
+		workDir = filepath.Join(tmpDir, "work")
+		work, err := gogit.PlainInit(workDir, false)
+		Expect(err).NotTo(HaveOccurred())
+		w, err = work.Worktree()
+		Expect(err).NotTo(HaveOccurred())
+		first = commitFiles(w, workDir, map[string]string{
+			"manifest.yaml":    "structure:\n- name: overview\n  source: ./docs/overview.md\n- name: docs\n  nodesSelector:\n    path: ./docs\n",
+			"docs/overview.md": "# Overview\n",
+		}, "alice", time.Date(2022, 1, 10, 10, 0, 0, 0, time.UTC))
+		head = commitFiles(w, workDir, map[string]string{
+			"docs/guides/install.md": "# Install\n",
+		}, "bob", time.Date(2022, 2, 20, 10, 0, 0, 0, time.UTC))
+		// expose it as bare repository with default branch main
+		bareDir := filepath.Join(tmpDir, "bare.git")
+		bare, err := gogit.PlainClone(bareDir, true, &gogit.CloneOptions{URL: workDir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bare.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), head))).To(Succeed())
+		Expect(bare.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")))).To(Succeed())
+		Expect(bare.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.24.0"), first))).To(Succeed())
+		Expect(bare.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.25.0"), head))).To(Succeed())
+		Expect(bare.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.26.0-rc.1"), head))).To(Succeed())
+
+		g = &localRemoteGit{
+			Git:     gitinterface.NewGit(),
+			remotes: map[string]string{"https://github.com/org/repo": bareDir},
+		}
+		gh = git.NewResourceHandlerTest(filepath.Join(tmpDir, git.CacheDir), nil, "", nil, nil, []string{"github.com"}, nil, g, nil, nil, nil)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	It("resolves documentation on the default branch", func() {
+		doc, err := gh.ResolveDocumentation(ctx, "https://github.com/org/repo/blob/DEFAULT_BRANCH/manifest.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Structure).To(HaveLen(2))
+		Expect(doc.Structure[0].Source).To(Equal("https://github.com/org/repo/blob/main/docs/overview.md"))
+		Expect(doc.Structure[1].NodeSelector.Path).To(Equal("https://github.com/org/repo/tree/main/docs"))
+	})
+
+	It("resolves node selector", func() {
+		nodes, err := gh.ResolveNodeSelector(ctx, &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/org/repo/tree/DEFAULT_BRANCH/docs"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(2))
+		Expect(nodes[0].Name).To(Equal("guides"))
+		Expect(nodes[0].Nodes).To(HaveLen(1))
+		Expect(nodes[0].Nodes[0].Source).To(Equal("https://github.com/org/repo/blob/main/docs/guides/install.md"))
+		Expect(nodes[1].Source).To(Equal("https://github.com/org/repo/blob/main/docs/overview.md"))
+	})
+
+	It("filters node selector paths relative to its path", func() {
+		nodes, err := gh.ResolveNodeSelector(ctx, &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/org/repo/tree/main/docs", ExcludePaths: []string{"^guides/"}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(1))
+		Expect(nodes[0].Source).To(Equal("https://github.com/org/repo/blob/main/docs/overview.md"))
+		_, err = gh.ResolveNodeSelector(ctx, &api.Node{NodeSelector: &api.NodeSelector{Path: "https://github.com/org/repo/tree/main/docs", ExcludePaths: []string{"("}}})
+		Expect(err).To(MatchError(ContainSubstring("invalid path exclude expression (")))
+	})
+
+	It("reads files", func() {
+		cnt, err := gh.Read(ctx, "https://github.com/org/repo/blob/main/docs/guides/install.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(cnt)).To(Equal("# Install\n"))
+		_, err = gh.Read(ctx, "https://github.com/org/repo/blob/main/docs/missing.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+	})
+
+	It("reads git info from the local history", func() {
+		blob, err := gh.ReadGitInfo(ctx, "https://github.com/org/repo/tree/main/docs")
+		Expect(err).NotTo(HaveOccurred())
+		var info map[string]interface{}
+		Expect(json.Unmarshal(blob, &info)).To(Succeed())
+		Expect(info["publishdate"]).To(Equal("2022-01-10 10:00:00"))
+		Expect(info["lastmod"]).To(Equal("2022-02-20 10:00:00"))
+		Expect(info["author"]).To(HaveKeyWithValue("name", "alice"))
+		Expect(info["contributors"]).To(ConsistOf(HaveKeyWithValue("name", "bob")))
+		Expect(info["shaalias"]).To(Equal("main"))
+		Expect(info["path"]).To(Equal("docs"))
+		Expect(info["weburl"]).To(Equal("https://github.com/org/repo"))
+	})
+
+	It("reads git info of mapped resources from the local repository", func() {
+		commitFiles(w, workDir, map[string]string{
+			"docs/guides/upgrade.md": "# Upgrade\n",
+		}, "carol", time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC))
+		gh = git.NewResourceHandlerTest(filepath.Join(tmpDir, git.CacheDir), nil, "", nil, nil, []string{"github.com"}, map[string]string{"https://github.com/org/repo": workDir}, g, nil, nil, nil)
+		blob, err := gh.ReadGitInfo(ctx, "https://github.com/org/repo/tree/main/docs/guides")
+		Expect(err).NotTo(HaveOccurred())
+		var info map[string]interface{}
+		Expect(json.Unmarshal(blob, &info)).To(Succeed())
+		Expect(info["publishdate"]).To(Equal("2022-02-20 10:00:00"))
+		Expect(info["lastmod"]).To(Equal("2022-03-01 10:00:00"))
+		Expect(info["author"]).To(HaveKeyWithValue("name", "bob"))
+		Expect(info["contributors"]).To(ConsistOf(HaveKeyWithValue("name", "carol")))
+		Expect(info["path"]).To(Equal("docs/guides"))
+		// the repository is not cloned
+		Expect(filepath.Join(tmpDir, git.CacheDir)).NotTo(BeADirectory())
+	})
+
+	It("resolves and pins refs", func() {
+		rr := gh.(resourcehandlers.RefResolver)
+		repo, ref, err := rr.ResourceRef("https://github.com/org/repo/blob/DEFAULT_BRANCH/docs/overview.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo).To(Equal("https://github.com/org/repo"))
+		Expect(ref).To(Equal("DEFAULT_BRANCH"))
+		sha, err := rr.ResolveRef(ctx, "https://github.com/org/repo/blob/DEFAULT_BRANCH/docs/overview.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sha).To(Equal(head.String()))
+		pinned, err := rr.PinRef("https://github.com/org/repo/tree/main/docs/guides", first.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pinned).To(Equal("https://github.com/org/repo/tree/" + first.String() + "/docs/guides"))
+		// the pinned commit doesn't contain the files added later
+		_, err = gh.Read(ctx, "https://github.com/org/repo/blob/"+first.String()+"/docs/guides/install.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		cnt, err := gh.Read(ctx, "https://github.com/org/repo/blob/"+first.String()+"/docs/overview.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(cnt)).To(Equal("# Overview\n"))
+	})
+
+	It("resolves ref expressions to tags", func() {
+		_, err := gh.Read(ctx, "https://github.com/org/repo/blob/~1.24/docs/guides/install.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		cnt, err := gh.Read(ctx, "https://github.com/org/repo/blob/~1.24/docs/overview.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(cnt)).To(Equal("# Overview\n"))
+		cnt, err = gh.Read(ctx, "https://github.com/org/repo/blob/LATEST_TAG/docs/guides/install.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(cnt)).To(Equal("# Install\n"))
+		sha, err := gh.(resourcehandlers.RefResolver).ResolveRef(ctx, "https://github.com/org/repo/blob/=v1.24.x/docs/overview.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sha).To(Equal(first.String()))
+		_, err = gh.Read(ctx, "https://github.com/org/repo/blob/~2.0/docs/overview.md")
+		Expect(err).To(MatchError(ContainSubstring("no tag matches ~2.0")))
+	})
+})
@@ -15,22 +15,26 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/hashicorp/go-multierror"
-
 	"github.com/gardener/docforge/pkg/api"
 	"github.com/gardener/docforge/pkg/resourcehandlers"
 	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitinterface"
 	"github.com/gardener/docforge/pkg/resourcehandlers/github"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitinfo"
+	"github.com/gardener/docforge/pkg/util"
 	"github.com/gardener/docforge/pkg/util/httpclient"
 	"github.com/gardener/docforge/pkg/util/urls"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	ghclient "github.com/google/go-github/v43/github"
+	"k8s.io/klog/v2"
 )
 
 // CacheDir is the name of repository cache directory
@@ -74,11 +78,16 @@ type Git struct {
 	fileReader FileReader
 	walker     func(root string, walkerFunc filepath.WalkFunc) error
 
-	branchesMap map[string]string
+	defBranches    map[string]string
+	refExprs       map[string]string
+	muxDefBranches sync.Mutex
+
 	flagVars    map[string]string
+	hugoEnabled bool
 }
 
 // NewResourceHandlerTest creates new GitHub ResourceHandler objects given more arguments. Used when testing
+// If fileR or walkerF are nil, the local file system is used.
 func NewResourceHandlerTest(gitRepositoriesAbsPath string, user *string, oauthToken string, githubOAuthClient *ghclient.Client, httpClient *nethttp.Client, acceptedHosts []string, localMappings map[string]string, gitArg gitinterface.Git, prepRepos map[string]*Repository, fileR FileReader, walkerF func(root string, walkerFunc filepath.WalkFunc) error) resourcehandlers.ResourceHandler {
 	out := &Git{
 		client:                 githubOAuthClient,
@@ -91,14 +100,21 @@ func NewResourceHandlerTest(gitRepositoriesAbsPath string, user *string, oauthTo
 		preparedRepos:          prepRepos,
 		fileReader:             fileR,
 		walker:                 walkerF,
-		branchesMap:            map[string]string{},
+		defBranches:            map[string]string{},
+		refExprs:               map[string]string{},
 		flagVars:               map[string]string{},
 	}
+	if out.fileReader == nil {
+		out.fileReader = &osReader{}
+	}
+	if out.walker == nil {
+		out.walker = filepath.Walk
+	}
 	return out
 }
 
 // NewResourceHandler creates new GitHub ResourceHandler objects
-func NewResourceHandler(gitRepositoriesAbsPath string, user *string, oauthToken string, githubOAuthClient *ghclient.Client, httpClient *nethttp.Client, acceptedHosts []string, localMappings map[string]string, branchesMap map[string]string, flagVars map[string]string) resourcehandlers.ResourceHandler {
+func NewResourceHandler(gitRepositoriesAbsPath string, user *string, oauthToken string, githubOAuthClient *ghclient.Client, httpClient *nethttp.Client, acceptedHosts []string, localMappings map[string]string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
 	out := &Git{
 		client:                 githubOAuthClient,
 		httpClient:             httpClient,
@@ -109,8 +125,10 @@ func NewResourceHandler(gitRepositoriesAbsPath string, user *string, oauthToken
 		git:                    gitinterface.NewGit(),
 		fileReader:             &osReader{},
 		walker:                 filepath.Walk,
-		branchesMap:            branchesMap,
+		defBranches:            map[string]string{},
+		refExprs:               map[string]string{},
 		flagVars:               flagVars,
+		hugoEnabled:            hugoEnabled,
 	}
 
 	return out
@@ -165,6 +183,9 @@ func (g *Git) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.N
 	if err != nil {
 		return nil, err
 	}
+	if err = g.resolveRefAlias(rl); err != nil {
+		return nil, err
+	}
 	repositoryPath := g.repositoryPathFromResourceLocator(rl)
 	if err := g.prepareGitRepository(ctx, rl); err != nil {
 		return nil, err
@@ -181,91 +202,43 @@ func (g *Git) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.N
 	if !fileInfo.IsDir() && filepath.Ext(fileInfo.Name()) == ".yaml" {
 		return nil, fmt.Errorf("nodeSelector path is neither directory or module")
 	}
-	_node := &api.Node{
-		Nodes:        []*api.Node{},
-		NodeSelector: node.NodeSelector,
-	}
-	nb := &nodeBuilder{
-		rootNodePath:    nodesSelectorLocalPath,
-		depth:           int(node.NodeSelector.Depth),
-		excludePaths:    node.NodeSelector.ExcludePaths,
-		someMap:         make(map[string]*api.Node),
-		resourceLocator: rl,
-	}
-	g.walker(nodesSelectorLocalPath, nb.build)
-	for path, n := range nb.someMap {
-		parentPath := filepath.Dir(path)
-		parent, exists := nb.someMap[parentPath]
-		if !exists { // If a parent does not exist, this is the root.
-			_node = n
-		} else {
-			n.SetParent(parent)
-			n.Parent().Nodes = append(n.Parent().Nodes, n)
-		}
-	}
-	//removing child parent
-	for _, child := range _node.Nodes {
-		child.SetParent(nil)
-	}
-	// finally, cleanup folder entries from contentSelectors
-	_node.Cleanup()
-	if len(_node.Nodes) > 0 {
-		sort.Slice(_node.Nodes, func(i, j int) bool {
-			return _node.Nodes[i].Name < _node.Nodes[j].Name
-		})
-		return _node.Nodes, nil
-	}
-	return []*api.Node{}, nil
-}
-
-type nodeBuilder struct {
-	rootNodePath    string
-	depth           int
-	excludePaths    []string
-	someMap         map[string]*api.Node
-	resourceLocator *github.ResourceLocator
-}
-
-func (nb *nodeBuilder) build(path string, info os.FileInfo, err error) error {
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
 	if err != nil {
-		return err
-	}
-	newNode := &api.Node{
-		Name: info.Name(),
+		return nil, err
 	}
-	source := filepath.ToSlash(strings.TrimPrefix(path, nb.rootNodePath))
-	for _, excludePath := range nb.excludePaths {
-		regex, err := regexp.Compile(excludePath)
+	bPrefix := getProperResourceLocator(rl, github.Blob).String()
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = getProperResourceLocator(rl, github.Tree).String()
+	err = g.walker(nodesSelectorLocalPath, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
-			return fmt.Errorf("invalid path exclude expression %s: %w", excludePath, err)
+			return err
 		}
-		urlString := source
-		if regex.Match([]byte(urlString)) {
+		lPath := filepath.ToSlash(strings.TrimPrefix(p, nodesSelectorLocalPath))
+		lPath = strings.TrimPrefix(lPath, "/")
+		// skip entry if it is not a markdown
+		if info.IsDir() || !strings.HasSuffix(strings.ToLower(lPath), ".md") {
+			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, lPath)
 			return nil
 		}
-	}
-
-	childPathSegmentsCount := len(strings.Split(source, "/")) - 1
-	if nb.depth != 0 && childPathSegmentsCount > nb.depth {
-		return nil
-	}
-	if info.IsDir() {
-		newNode.Nodes = []*api.Node{}
-		if newNode.Properties == nil {
-			newNode.Properties = make(map[string]interface{})
-			rl := getProperResourceLocator(nb.resourceLocator, github.Tree)
-			newNode.Properties[api.ContainerNodeSourceLocation] = rl.String() + source
-		}
-	} else {
-		if filepath.Ext(info.Name()) != ".md" {
+		if resourcehandlers.FilterPath(node, pfs, lPath) {
 			return nil
 		}
-		rl := getProperResourceLocator(nb.resourceLocator, github.Blob)
-		newNode.Source = rl.String() + source
+		resourcehandlers.BuildNode(vr, bPrefix, lPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking nodeSelector path %s for node %s: %v", nodesSelectorLocalPath, node.FullName("/"), err)
 	}
-
-	nb.someMap[path] = newNode
-	return nil
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	if len(vr.Nodes) == 0 {
+		return []*api.Node{}, nil
+	}
+	for _, cn := range vr.Nodes {
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
 }
 
 func getProperResourceLocator(rl *github.ResourceLocator, desiredType github.ResourceType) *github.ResourceLocator {
@@ -341,38 +314,138 @@ func (g *Git) getGitFilePath(ctx context.Context, uri string, initRepo bool) (st
 	if err != nil {
 		return "", fmt.Errorf("unable to parse file uri %s: %v", uri, err)
 	}
+	if err = g.resolveRefAlias(rl); err != nil {
+		return "", err
+	}
 	// first check for provided repository mapping
+	if localPath, ok, err := g.localMapping(uri, rl); ok || err != nil {
+		return localPath, err
+	}
+	// use git cache folder
+	repositoryPath := g.repositoryPathFromResourceLocator(rl)
+	uri = filepath.Join(repositoryPath, rl.Path)
+	if initRepo {
+		if err := g.prepareGitRepository(ctx, rl); err != nil {
+			return "", err
+		}
+	}
+	return uri, nil
+}
+
+// localMapping returns the local path of the resource at uri, if uri is mapped to a local path
+func (g *Git) localMapping(uri string, rl *github.ResourceLocator) (string, bool, error) {
 	for k, v := range g.localMappings {
 		if strings.HasPrefix(uri, k) {
 			fileInfo, err := g.fileReader.Stat(v)
 			if err != nil {
-				return "", fmt.Errorf("failed to use mapping %s because local path is invalid: %v", k, err)
+				return "", false, fmt.Errorf("failed to use mapping %s because local path is invalid: %v", k, err)
 			}
 			if fileInfo.IsDir() {
 				mappingResourceLocator, err := github.Parse(k)
 				if err != nil {
-					return "", err
+					return "", false, err
 				}
 				mappingPath := strings.TrimPrefix(rl.Path, mappingResourceLocator.Path)
 				v = filepath.Join(v, mappingPath)
 			}
-			return v, nil
+			return v, true, nil
 		}
 	}
-	// use git cache folder
-	repositoryPath := g.repositoryPathFromResourceLocator(rl)
-	uri = filepath.Join(repositoryPath, rl.Path)
-	if initRepo {
-		if err := g.prepareGitRepository(ctx, rl); err != nil {
-			return "", err
+	return "", false, nil
+}
+
+// openLocalRepository opens the local repository containing localPath and returns
+// the slash separated path of localPath in it
+func (g *Git) openLocalRepository(localPath string) (gitinterface.Repository, string, error) {
+	for dir := localPath; ; dir = filepath.Dir(dir) {
+		if repository, err := g.git.PlainOpen(dir); err == nil {
+			p, err := filepath.Rel(dir, localPath)
+			if err != nil {
+				return nil, "", err
+			}
+			return repository, strings.TrimPrefix(filepath.ToSlash(p), "."), nil
+		}
+		if dir == filepath.Dir(dir) {
+			return nil, "", fmt.Errorf("no git repository found for local path %s", localPath)
 		}
 	}
-	return uri, nil
 }
 
 // ReadGitInfo implements resourcehandlers/ResourceHandler#ReadGitInfo
+// The git info is built from the history of the locally cloned repository,
+// or of the local repository of the mapped resources
 func (g *Git) ReadGitInfo(ctx context.Context, uri string) ([]byte, error) {
-	return github.ReadGitInfo(ctx, uri, g.client)
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse file uri %s: %v", uri, err)
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.ForceQuery = false
+	rl, err := github.Parse(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse file uri %s: %v", uri, err)
+	}
+	if err = g.resolveRefAlias(rl); err != nil {
+		return nil, err
+	}
+	var (
+		repository gitinterface.Repository
+		p          = strings.Trim(rl.Path, "/")
+	)
+	localPath, mapped, err := g.localMapping(u.String(), rl)
+	if err != nil {
+		return nil, err
+	}
+	if mapped {
+		if repository, p, err = g.openLocalRepository(localPath); err != nil {
+			return nil, fmt.Errorf("failed to open repository for %s: %v", uri, err)
+		}
+	} else {
+		if err = g.prepareGitRepository(ctx, rl); err != nil {
+			return nil, err
+		}
+		if repository, err = g.git.PlainOpen(g.repositoryPathFromResourceLocator(rl)); err != nil {
+			return nil, fmt.Errorf("failed to open repository for %s: %v", uri, err)
+		}
+	}
+	log, err := repository.Log(&gogit.LogOptions{
+		PathFilter: func(f string) bool {
+			return p == "" || f == p || strings.HasPrefix(f, p+"/")
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log for %s: %v", uri, err)
+	}
+	gitInfo := gitinfo.Transform(toGitInfoCommits(log))
+	if gitInfo == nil {
+		return nil, nil
+	}
+	if len(rl.SHAAlias) > 0 {
+		gitInfo.SHAAlias = &rl.SHAAlias
+	}
+	if len(p) > 0 {
+		gitInfo.Path = &p
+	}
+	webURL := fmt.Sprintf("%s://%s/%s/%s", rl.Scheme, repositoryHost(rl), rl.Owner, rl.Repo)
+	gitInfo.WebURL = &webURL
+	return gitinfo.Marshal(gitInfo)
+}
+
+func toGitInfoCommits(log []*object.Commit) []*gitinfo.Commit {
+	commits := make([]*gitinfo.Commit, 0, len(log))
+	for _, c := range log {
+		commits = append(commits, &gitinfo.Commit{
+			SHA:            c.Hash.String(),
+			Message:        c.Message,
+			AuthorName:     c.Author.Name,
+			AuthorEmail:    c.Author.Email,
+			CommitterName:  c.Committer.Name,
+			CommitterEmail: c.Committer.Email,
+			Date:           c.Committer.When,
+		})
+	}
+	return commits
 }
 
 // ResourceName returns a breakdown of a resource name in the link, consisting
@@ -478,23 +551,9 @@ func (g *Git) ResolveDocumentation(ctx context.Context, uri string) (*api.Docume
 	if err != nil {
 		return nil, err
 	}
-	if rl.SHAAlias == "DEFAULT_BRANCH" {
-		if rl.SHAAlias, err = github.GetDefaultBranch(ctx, g.client, rl); err != nil {
-			return nil, err
-		}
-	}
-	var (
-		targetBranch string
-		ok           bool
-	)
-	//choosing default branch
-	if targetBranch, ok = g.branchesMap[uri]; !ok {
-		if targetBranch, ok = g.branchesMap["default"]; !ok {
-			targetBranch = rl.SHAAlias
-		}
+	if err = g.resolveRefAlias(rl); err != nil {
+		return nil, err
 	}
-	rl.SHAAlias = targetBranch
-
 	if err := g.prepareGitRepository(ctx, rl); err != nil {
 		return nil, err
 	}
@@ -508,61 +567,131 @@ func (g *Git) ResolveDocumentation(ctx context.Context, uri string) (*api.Docume
 	if blob == nil {
 		return nil, nil
 	}
-	//DEPRECATED!!! if to be returned should get hugo par
-	doc, err := api.ParseWithMetadata(blob, rl.SHAAlias, g.flagVars, true)
+	doc, err := api.ParseWithMetadata(blob, rl.SHAAlias, g.flagVars, g.hugoEnabled)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
 	}
-	if err = g.resolveDocumentationRelativePaths(&api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}, rl.String()); err != nil {
+	manifest := rl.String()
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, manifest, func(link string) (string, error) { return g.BuildAbsLink(manifest, link) }); err != nil {
 		return nil, err
 	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
 	return doc, nil
 }
 
-// resolveDocumentationRelativePaths traverses api.Node#Nodes and resolve node Source, MultiSource and api.NodeSelector relative paths to absolute URLs
-func (g *Git) resolveDocumentationRelativePaths(node *api.Node, moduleDocumentationPath string) error {
-	var errs error
-	if node.Source != "" {
-		u, err := url.Parse(node.Source)
+// resolveRefAlias replaces the DEFAULT_BRANCH alias in the resource locator with
+// the repository default branch, resolved from the remote HEAD reference, and the
+// ref expressions (e.g. LATEST_TAG or ~1.24) with the matching repository tag
+func (g *Git) resolveRefAlias(rl *github.ResourceLocator) error {
+	if resourcehandlers.IsRefExpression(rl.SHAAlias) {
+		return g.resolveRefExpression(rl)
+	}
+	if rl.SHAAlias != "DEFAULT_BRANCH" {
+		return nil
+	}
+	remoteURL := g.remoteURL(rl)
+	g.muxDefBranches.Lock()
+	defer g.muxDefBranches.Unlock()
+	if g.defBranches == nil {
+		g.defBranches = map[string]string{}
+	}
+	if branch, ok := g.defBranches[remoteURL]; ok {
+		rl.SHAAlias = branch
+		return nil
+	}
+	refs, err := g.git.ListRemote(remoteURL, &gogit.ListOptions{Auth: g.gitAuth})
+	if err != nil {
+		if err == transport.ErrRepositoryNotFound {
+			return resourcehandlers.ErrResourceNotFound(remoteURL)
+		}
+		return fmt.Errorf("failed to list references of repository %s: %v", remoteURL, err)
+	}
+	branch := defaultBranch(refs)
+	if branch == "" {
+		return fmt.Errorf("failed to resolve default branch of repository %s", remoteURL)
+	}
+	g.defBranches[remoteURL] = branch
+	rl.SHAAlias = branch
+	return nil
+}
+
+// resolveRefExpression replaces the ref expression in the resource locator with the matching tag.
+// The tags are listed from the remote references, the latest release is resolved with the GitHub API.
+func (g *Git) resolveRefExpression(rl *github.ResourceLocator) error {
+	remoteURL := g.remoteURL(rl)
+	key := remoteURL + "@" + rl.SHAAlias
+	g.muxDefBranches.Lock()
+	defer g.muxDefBranches.Unlock()
+	if g.refExprs == nil {
+		g.refExprs = map[string]string{}
+	}
+	if ref, ok := g.refExprs[key]; ok {
+		rl.SHAAlias = ref
+		return nil
+	}
+	var ref string
+	if rl.SHAAlias == resourcehandlers.LatestRelease {
+		if g.client == nil {
+			return fmt.Errorf("failed to resolve latest release of repository %s: no GitHub client", remoteURL)
+		}
+		release, _, err := g.client.Repositories.GetLatestRelease(context.Background(), rl.Owner, rl.Repo)
 		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s source: %s", moduleDocumentationPath, node.FullName("/"), node.Source))
-		} else if !u.IsAbs() {
-			// resolve relative path
-			if node.Source, err = g.BuildAbsLink(moduleDocumentationPath, node.Source); err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("cannot resolve source relative path %s in node %s and manifest %s", node.Source, node.FullName("/"), moduleDocumentationPath))
+			return fmt.Errorf("failed to resolve latest release of repository %s: %v", remoteURL, err)
+		}
+		ref = release.GetTagName()
+	} else {
+		refs, err := g.git.ListRemote(remoteURL, &gogit.ListOptions{Auth: g.gitAuth})
+		if err != nil {
+			if err == transport.ErrRepositoryNotFound {
+				return resourcehandlers.ErrResourceNotFound(remoteURL)
 			}
+			return fmt.Errorf("failed to list references of repository %s: %v", remoteURL, err)
 		}
-	}
-	if len(node.MultiSource) > 0 {
-		for idx, src := range node.MultiSource {
-			u, err := url.Parse(src)
-			if err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s multiSource[%d]: %s", moduleDocumentationPath, node.FullName("/"), idx, node.MultiSource[idx]))
-			} else if !u.IsAbs() {
-				// resolve relative path
-				if node.Source, err = g.BuildAbsLink(moduleDocumentationPath, src); err != nil {
-					errs = multierror.Append(errs, fmt.Errorf("cannot resolve multiSource[%d] relative path %s in node %s and manifest %s", idx, node.MultiSource[idx], node.FullName("/"), moduleDocumentationPath))
-				}
+		var tags []string
+		for _, r := range refs {
+			if r.Name().IsTag() {
+				tags = append(tags, r.Name().Short())
 			}
 		}
+		if ref, err = resourcehandlers.MatchTag(rl.SHAAlias, tags); err != nil {
+			return fmt.Errorf("failed to resolve ref of repository %s: %v", remoteURL, err)
+		}
 	}
-	if node.NodeSelector != nil {
-		u, err := url.Parse(node.NodeSelector.Path)
-		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid nodeSelector path %s in node %s", moduleDocumentationPath, node.NodeSelector.Path, node.FullName("/")))
-		} else if !u.IsAbs() {
-			// resolve relative path
-			if node.NodeSelector.Path, err = g.BuildAbsLink(moduleDocumentationPath, node.NodeSelector.Path); err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("cannot resolve nodeSelector relative path %s in node %s and manifest %s", node.NodeSelector.Path, node.FullName("/"), moduleDocumentationPath))
-			}
+	g.refExprs[key] = ref
+	rl.SHAAlias = ref
+	return nil
+}
+
+// defaultBranch returns the branch referenced by HEAD, or empty string if it cannot be determined
+func defaultBranch(refs []*plumbing.Reference) string {
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
 		}
 	}
-	for _, n := range node.Nodes {
-		if err := g.resolveDocumentationRelativePaths(n, moduleDocumentationPath); err != nil {
-			errs = multierror.Append(errs, err)
+	if head == nil {
+		return ""
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short()
+	}
+	// remote does not advertise HEAD as symbolic reference, match branches by hash
+	var branches []string
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			branches = append(branches, ref.Name().Short())
 		}
 	}
-	return errs
+	if len(branches) == 0 {
+		return ""
+	}
+	sort.Strings(branches)
+	return branches[0]
 }
 
 // GetClient implements resourcehandlers.ResourceHandler#GetClient
@@ -571,6 +700,15 @@ func (g *Git) GetClient() httpclient.Client {
 }
 
 func (g *Git) repositoryPathFromResourceLocator(rl *github.ResourceLocator) string {
+	return filepath.Join(g.gitRepositoriesAbsPath, repositoryHost(rl), rl.Owner, rl.Repo, rl.SHAAlias)
+}
+
+func (g *Git) remoteURL(rl *github.ResourceLocator) string {
+	return "https://" + repositoryHost(rl) + "/" + rl.Owner + "/" + rl.Repo
+}
+
+// repositoryHost returns the git host of a resource locator, which may point to raw or API host
+func repositoryHost(rl *github.ResourceLocator) string {
 	host := rl.Host
 	if strings.HasPrefix(rl.Host, "raw.") {
 		if rl.Host == "raw.githubusercontent.com" {
@@ -581,7 +719,7 @@ func (g *Git) repositoryPathFromResourceLocator(rl *github.ResourceLocator) stri
 	} else if host == "api.github.com" {
 		host = "github.com"
 	}
-	return filepath.Join(g.gitRepositoriesAbsPath, host, rl.Owner, rl.Repo, rl.SHAAlias)
+	return host
 }
 
 // getOrInitRepository serves as a sync point to avoid more complicated logic for synchronization between workers working on the same repository. In case it returns false no one began working on
@@ -599,7 +737,7 @@ func (g *Git) getOrInitRepository(repositoryPath string, rl *github.ResourceLoca
 		Git:           g.git,
 		Auth:          g.gitAuth,
 		LocalPath:     repositoryPath,
-		RemoteURL:     "https://" + rl.Host + "/" + rl.Owner + "/" + rl.Repo,
+		RemoteURL:     g.remoteURL(rl),
 		PreviousError: nil,
 		mutex:         sync.RWMutex{},
 	}
@@ -610,9 +748,54 @@ func (g *Git) getOrInitRepository(repositoryPath string, rl *github.ResourceLoca
 
 // GetRateLimit implements resourcehandlers.ResourceHandler#GetRateLimit
 func (g *Git) GetRateLimit(ctx context.Context) (int, int, time.Time, error) {
+	if g.client == nil {
+		return -1, -1, time.Now(), nil
+	}
 	r, _, err := g.client.RateLimits(ctx)
 	if err != nil {
 		return -1, -1, time.Now(), err
 	}
 	return r.Core.Limit, r.Core.Remaining, r.Core.Reset.Time, nil
 }
+
+// ResourceRef implements resourcehandlers.RefResolver#ResourceRef
+func (g *Git) ResourceRef(uri string) (string, string, error) {
+	rl, err := github.Parse(uri)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", rl.Scheme, repositoryHost(rl), rl.Owner, rl.Repo), rl.SHAAlias, nil
+}
+
+// ResolveRef implements resourcehandlers.RefResolver#ResolveRef
+// The ref is resolved to the commit checked out in the repository cache
+func (g *Git) ResolveRef(ctx context.Context, uri string) (string, error) {
+	rl, err := github.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if err = g.resolveRefAlias(rl); err != nil {
+		return "", err
+	}
+	if err = g.prepareGitRepository(ctx, rl); err != nil {
+		return "", err
+	}
+	repository, err := g.git.PlainOpen(g.repositoryPathFromResourceLocator(rl))
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository for %s: %v", uri, err)
+	}
+	head, err := repository.Reference(plumbing.HEAD, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD of repository for %s: %v", uri, err)
+	}
+	return head.Hash().String(), nil
+}
+
+// PinRef implements resourcehandlers.RefResolver#PinRef
+func (g *Git) PinRef(uri string, sha string) (string, error) {
+	r, err := util.BuildResourceInfo(uri)
+	if err != nil {
+		return "", err
+	}
+	return r.GetRefURL(sha), nil
+}
@@ -22,8 +22,9 @@ import (
 	"github.com/gardener/docforge/pkg/resourcehandlers/git"
 	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitfakes"
 	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitinterface/gitinterfacefakes"
-	ghub "github.com/gardener/docforge/pkg/resourcehandlers/github"
 
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-github/v43/github"
 )
 
@@ -89,109 +90,6 @@ var (
 		  }
 		`)))
 		})
-		mux.HandleFunc("/repos/testOrg/testRepo", func(w http.ResponseWriter, r *http.Request) {
-			w.Write([]byte(fmt.Sprintf(`
-			{	
-				"default_branch": "testMainBranch"
-			}
-		`)))
-		})
-		mux.HandleFunc("/repos/gardener/docforge/commits?path=testRes&sha=master", func(w http.ResponseWriter, r *http.Request) {
-			fmt.Print("yay")
-
-			w.Write([]byte(fmt.Sprintf(`
-			{	
-				"default_branch": "testMainBranch"
-			}
-		`)))
-		})
-
-		mux.HandleFunc("/repos/testOrg2/testRepo2/commits", func(w http.ResponseWriter, r *http.Request) {
-
-			w.Write([]byte(fmt.Sprintf(`
-			[
-  {
-    "sha": "6bea6bee790673592da2d1af784b79339bb8c2c6",
-    "node_id": "C_kwDOEI7t4toAKDZiZWE2YmVlNzkwNjczNTkyZGEyZDFhZjc4NGI3OTMzOWJiOGMyYzY",
-    "commit": {
-      "author": {
-        "name": "userx usery",
-        "email": "userx.usery@gmail.com",
-        "date": "2021-12-08T15:09:39Z"
-      },
-      "committer": {
-        "name": "userx usery",
-        "email": "userx.usery@gmail.com",
-        "date": "2021-12-20T13:11:24Z"
-      },
-      "message": "Integrate Goldmark Markdown parser",
-      "tree": {
-        "sha": "85c0520f6a222319ae5b29e06afff6b2f16beb8f",
-        "url": "https://api.github.com/repos/gardener/docforge/git/trees/85c0520f6a222319ae5b29e06afff6b2f16beb8f"
-      },
-      "url": "https://api.github.com/repos/gardener/docforge/git/commits/6bea6bee790673592da2d1af784b79339bb8c2c6",
-      "comment_count": 0,
-      "verification": {
-        "verified": false,
-        "reason": "unsigned",
-        "signature": null,
-        "payload": null
-      }
-    },
-    "url": "https://api.github.com/repos/gardener/docforge/commits/6bea6bee790673592da2d1af784b79339bb8c2c6",
-    "html_url": "https://github.com/gardener/docforge/commit/6bea6bee790673592da2d1af784b79339bb8c2c6",
-    "comments_url": "https://api.github.com/repos/gardener/docforge/commits/6bea6bee790673592da2d1af784b79339bb8c2c6/comments",
-    "author": {
-      "login": "userx-usery",
-      "id": 51451517,
-      "node_id": "MDQ6VXNlcjUxNDUxNTE3",
-      "avatar_url": "https://avatars.githubusercontent.com/u/51451517?v=4",
-      "gravatar_id": "",
-      "url": "https://api.github.com/users/userx-usery",
-      "html_url": "https://github.com/userx-usery",
-      "followers_url": "https://api.github.com/users/userx-usery/followers",
-      "following_url": "https://api.github.com/users/userx-usery/following{/other_user}",
-      "gists_url": "https://api.github.com/users/userx-usery/gists{/gist_id}",
-      "starred_url": "https://api.github.com/users/userx-usery/starred{/owner}{/repo}",
-      "subscriptions_url": "https://api.github.com/users/userx-usery/subscriptions",
-      "organizations_url": "https://api.github.com/users/userx-usery/orgs",
-      "repos_url": "https://api.github.com/users/userx-usery/repos",
-      "events_url": "https://api.github.com/users/userx-usery/events{/privacy}",
-      "received_events_url": "https://api.github.com/users/userx-usery/received_events",
-      "type": "User",
-      "site_admin": false
-    },
-    "committer": {
-      "login": "userx-usery",
-      "id": 51451517,
-      "node_id": "MDQ6VXNlcjUxNDUxNTE3",
-      "avatar_url": "https://avatars.githubusercontent.com/u/51451517?v=4",
-      "gravatar_id": "",
-      "url": "https://api.github.com/users/userx-usery",
-      "html_url": "https://github.com/userx-usery",
-      "followers_url": "https://api.github.com/users/userx-usery/followers",
-      "following_url": "https://api.github.com/users/userx-usery/following{/other_user}",
-      "gists_url": "https://api.github.com/users/userx-usery/gists{/gist_id}",
-      "starred_url": "https://api.github.com/users/userx-usery/starred{/owner}{/repo}",
-      "subscriptions_url": "https://api.github.com/users/userx-usery/subscriptions",
-      "organizations_url": "https://api.github.com/users/userx-usery/orgs",
-      "repos_url": "https://api.github.com/users/userx-usery/repos",
-      "events_url": "https://api.github.com/users/userx-usery/events{/privacy}",
-      "received_events_url": "https://api.github.com/users/userx-usery/received_events",
-      "type": "User",
-      "site_admin": false
-    },
-    "parents": [
-      {
-        "sha": "c3a04a0827224f4c78f4f07349587c270b5c00d6",
-        "url": "https://api.github.com/repos/gardener/docforge/commits/c3a04a0827224f4c78f4f07349587c270b5c00d6",
-        "html_url": "https://github.com/gardener/docforge/commit/c3a04a0827224f4c78f4f07349587c270b5c00d6"
-      }
-    ]
-  }
-]
-		`)))
-		})
 	}
 )
 
@@ -380,54 +278,75 @@ var _ = Describe("Git", func() {
 		var (
 			url string
 
+			fakeRepository gitinterfacefakes.FakeRepository
+
 			got  []byte
 			want []byte
 			err  error
 		)
 
-		JustBeforeEach(func() {
-			got, err = gh.ReadGitInfo(ctx, url)
-		})
-
-		Describe("the usual use case", func() {
-			BeforeEach(func() {
-				url = "https://github.com/testOrg2/testRepo2/blob/master/testRes"
-				want = []byte(fmt.Sprintf(`{
+		BeforeEach(func() {
+			repositoryPath = "github.com/testOrg2/testRepo2/master"
+			url = "https://github.com/testOrg2/testRepo2/blob/master/testRes"
+			fakeRepository = gitinterfacefakes.FakeRepository{}
+			fakeRepository.LogReturns([]*object.Commit{
+				{
+					Hash:      plumbing.NewHash("b9fc7f01e4a2a1eb5dbd5e1a4a56cbcb7a47f5fa"),
+					Message:   "Update testRes",
+					Author:    object.Signature{Name: "userx usery", Email: "userx.usery@gmail.com", When: time.Date(2021, 12, 20, 13, 11, 24, 0, time.UTC)},
+					Committer: object.Signature{Name: "userx usery", Email: "userx.usery@gmail.com", When: time.Date(2021, 12, 20, 13, 11, 24, 0, time.UTC)},
+				},
+				{
+					Hash:      plumbing.NewHash("0c3f2d3a1e58a9b2f6c0e6a3c2d5a4b1e8f7d6c5"),
+					Message:   "[int] internal commit",
+					Author:    object.Signature{Name: "gardener-robot", Email: "gardener.ci.robot@gmail.com", When: time.Date(2021, 12, 21, 9, 0, 0, 0, time.UTC)},
+					Committer: object.Signature{Name: "gardener-robot", Email: "gardener.ci.robot@gmail.com", When: time.Date(2021, 12, 21, 9, 0, 0, 0, time.UTC)},
+				},
+			}, nil)
+			fakeGit.PlainOpenReturns(&fakeRepository, nil)
+			want = []byte(`{
   "lastmod": "2021-12-20 13:11:24",
   "publishdate": "2021-12-20 13:11:24",
   "author": {
-    "login": "userx-usery",
-    "id": 51451517,
-    "node_id": "MDQ6VXNlcjUxNDUxNTE3",
-    "avatar_url": "https://avatars.githubusercontent.com/u/51451517?v=4",
-    "html_url": "https://github.com/userx-usery",
-    "gravatar_id": "",
     "name": "userx usery",
-    "email": "userx.usery@gmail.com",
-    "type": "User",
-    "site_admin": false,
-    "url": "https://api.github.com/users/userx-usery",
-    "events_url": "https://api.github.com/users/userx-usery/events{/privacy}",
-    "following_url": "https://api.github.com/users/userx-usery/following{/other_user}",
-    "followers_url": "https://api.github.com/users/userx-usery/followers",
-    "gists_url": "https://api.github.com/users/userx-usery/gists{/gist_id}",
-    "organizations_url": "https://api.github.com/users/userx-usery/orgs",
-    "received_events_url": "https://api.github.com/users/userx-usery/received_events",
-    "repos_url": "https://api.github.com/users/userx-usery/repos",
-    "starred_url": "https://api.github.com/users/userx-usery/starred{/owner}{/repo}",
-    "subscriptions_url": "https://api.github.com/users/userx-usery/subscriptions"
+    "email": "userx.usery@gmail.com"
   },
-  "weburl": "https://github.com/gardener/docforge",
+  "weburl": "https://github.com/testOrg2/testRepo2",
+  "sha": "b9fc7f01e4a2a1eb5dbd5e1a4a56cbcb7a47f5fa",
   "shaalias": "master",
   "path": "testRes"
-}`))
-			})
+}`)
+		})
 
-			It("should process it correctly", func() {
+		JustBeforeEach(func() {
+			got, err = gh.ReadGitInfo(ctx, url)
+		})
 
+		Describe("the usual use case", func() {
+			It("should process it correctly", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(got).Should(Equal(want))
 			})
+
+			It("should filter the log by path", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeRepository.LogCallCount()).To(Equal(1))
+				opts := fakeRepository.LogArgsForCall(0)
+				Expect(opts.PathFilter("testRes")).To(BeTrue())
+				Expect(opts.PathFilter("testRes/nested.md")).To(BeTrue())
+				Expect(opts.PathFilter("testResOther")).To(BeFalse())
+			})
+		})
+
+		Describe("no commits", func() {
+			BeforeEach(func() {
+				fakeRepository.LogReturns(nil, nil)
+			})
+
+			It("should return no git info", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(got).To(BeNil())
+			})
 		})
 	})
 
@@ -518,7 +437,7 @@ var _ = Describe("Git", func() {
 		)
 
 		JustBeforeEach(func() {
-			gh = git.NewResourceHandler("", nil, "", nil, nil, acceptedHosts, nil, map[string]string{}, map[string]string{})
+			gh = git.NewResourceHandler("", nil, "", nil, nil, acceptedHosts, nil, map[string]string{}, false)
 			got = gh.Accept(url)
 		})
 
@@ -871,6 +790,10 @@ structure:
 				fakeWorktree   gitinterfacefakes.FakeRepositoryWorktree
 			)
 
+			fakeGit.ListRemoteReturns([]*plumbing.Reference{
+				plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("testMainBranch")),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("testMainBranch"), plumbing.ZeroHash),
+			}, nil)
 			fakeGit.PlainOpenReturns(&fakeRepository, nil)
 			fakeRepository.TagsReturns(tags, nil)
 			fakeRepository.WorktreeReturns(&fakeWorktree, nil)
@@ -878,11 +801,6 @@ structure:
 			got, err = gh.ResolveDocumentation(ctx, uri)
 		})
 
-		JustAfterEach(func() {
-			//clear default branch cache
-			ghub.ClearDefaultBranchesCache()
-		})
-
 		Context("given the general use case", func() {
 			BeforeEach(func() {
 				repositoryPath = "github.com/testOrg/testRepo/testMainBranch"
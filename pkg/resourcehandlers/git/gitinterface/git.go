@@ -10,7 +10,10 @@ import (
 	"context"
 
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // Git interface defines gogit git API
@@ -18,6 +21,7 @@ import (
 type Git interface {
 	PlainOpen(path string) (Repository, error)
 	PlainCloneContext(ctx context.Context, path string, isBare bool, o *gogit.CloneOptions) (Repository, error)
+	ListRemote(url string, o *gogit.ListOptions) ([]*plumbing.Reference, error)
 }
 
 // Repository interface defines gogit repository API
@@ -27,6 +31,7 @@ type Repository interface {
 	Worktree() (RepositoryWorktree, error)
 	Reference(name plumbing.ReferenceName, resolved bool) (*plumbing.Reference, error)
 	Tags() ([]string, error)
+	Log(o *gogit.LogOptions) ([]*object.Commit, error)
 }
 
 // RepositoryWorktree interface defines gogit worktree API
@@ -56,6 +61,15 @@ func (g *git) PlainCloneContext(ctx context.Context, path string, isBare bool, o
 	return &git{repository: repository}, err
 }
 
+// ListRemote lists the references of a remote repository without cloning it
+func (g *git) ListRemote(url string, o *gogit.ListOptions) ([]*plumbing.Reference, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: gogit.DefaultRemoteName,
+		URLs: []string{url},
+	})
+	return remote.List(o)
+}
+
 // FetchContext calls git repository API FetchContext
 func (g *git) FetchContext(ctx context.Context, o *gogit.FetchOptions) error {
 	return g.repository.FetchContext(ctx, o)
@@ -86,3 +100,19 @@ func (g *git) Tags() ([]string, error) {
 	}
 	return tags, nil
 }
+
+// Log gets the commit history from the corresponding repository
+func (g *git) Log(o *gogit.LogOptions) ([]*object.Commit, error) {
+	iter, err := g.repository.Log(o)
+	if err != nil {
+		return nil, err
+	}
+	var commits []*object.Commit
+	if err = iter.ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
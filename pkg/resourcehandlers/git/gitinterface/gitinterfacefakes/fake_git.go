@@ -10,9 +10,24 @@ import (
 
 	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitinterface"
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 type FakeGit struct {
+	ListRemoteStub        func(string, *git.ListOptions) ([]*plumbing.Reference, error)
+	listRemoteMutex       sync.RWMutex
+	listRemoteArgsForCall []struct {
+		arg1 string
+		arg2 *git.ListOptions
+	}
+	listRemoteReturns struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}
+	listRemoteReturnsOnCall map[int]struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}
 	PlainCloneContextStub        func(context.Context, string, bool, *git.CloneOptions) (gitinterface.Repository, error)
 	plainCloneContextMutex       sync.RWMutex
 	plainCloneContextArgsForCall []struct {
@@ -46,6 +61,71 @@ type FakeGit struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeGit) ListRemote(arg1 string, arg2 *git.ListOptions) ([]*plumbing.Reference, error) {
+	fake.listRemoteMutex.Lock()
+	ret, specificReturn := fake.listRemoteReturnsOnCall[len(fake.listRemoteArgsForCall)]
+	fake.listRemoteArgsForCall = append(fake.listRemoteArgsForCall, struct {
+		arg1 string
+		arg2 *git.ListOptions
+	}{arg1, arg2})
+	stub := fake.ListRemoteStub
+	fakeReturns := fake.listRemoteReturns
+	fake.recordInvocation("ListRemote", []interface{}{arg1, arg2})
+	fake.listRemoteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGit) ListRemoteCallCount() int {
+	fake.listRemoteMutex.RLock()
+	defer fake.listRemoteMutex.RUnlock()
+	return len(fake.listRemoteArgsForCall)
+}
+
+func (fake *FakeGit) ListRemoteCalls(stub func(string, *git.ListOptions) ([]*plumbing.Reference, error)) {
+	fake.listRemoteMutex.Lock()
+	defer fake.listRemoteMutex.Unlock()
+	fake.ListRemoteStub = stub
+}
+
+func (fake *FakeGit) ListRemoteArgsForCall(i int) (string, *git.ListOptions) {
+	fake.listRemoteMutex.RLock()
+	defer fake.listRemoteMutex.RUnlock()
+	argsForCall := fake.listRemoteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGit) ListRemoteReturns(result1 []*plumbing.Reference, result2 error) {
+	fake.listRemoteMutex.Lock()
+	defer fake.listRemoteMutex.Unlock()
+	fake.ListRemoteStub = nil
+	fake.listRemoteReturns = struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) ListRemoteReturnsOnCall(i int, result1 []*plumbing.Reference, result2 error) {
+	fake.listRemoteMutex.Lock()
+	defer fake.listRemoteMutex.Unlock()
+	fake.ListRemoteStub = nil
+	if fake.listRemoteReturnsOnCall == nil {
+		fake.listRemoteReturnsOnCall = make(map[int]struct {
+			result1 []*plumbing.Reference
+			result2 error
+		})
+	}
+	fake.listRemoteReturnsOnCall[i] = struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGit) PlainCloneContext(arg1 context.Context, arg2 string, arg3 bool, arg4 *git.CloneOptions) (gitinterface.Repository, error) {
 	fake.plainCloneContextMutex.Lock()
 	ret, specificReturn := fake.plainCloneContextReturnsOnCall[len(fake.plainCloneContextArgsForCall)]
@@ -180,6 +260,8 @@ func (fake *FakeGit) PlainOpenReturnsOnCall(i int, result1 gitinterface.Reposito
 func (fake *FakeGit) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.listRemoteMutex.RLock()
+	defer fake.listRemoteMutex.RUnlock()
 	fake.plainCloneContextMutex.RLock()
 	defer fake.plainCloneContextMutex.RUnlock()
 	fake.plainOpenMutex.RLock()
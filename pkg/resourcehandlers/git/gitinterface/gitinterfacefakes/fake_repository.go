@@ -11,6 +11,7 @@ import (
 	"github.com/gardener/docforge/pkg/resourcehandlers/git/gitinterface"
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 type FakeRepository struct {
@@ -26,6 +27,19 @@ type FakeRepository struct {
 	fetchContextReturnsOnCall map[int]struct {
 		result1 error
 	}
+	LogStub        func(*git.LogOptions) ([]*object.Commit, error)
+	logMutex       sync.RWMutex
+	logArgsForCall []struct {
+		arg1 *git.LogOptions
+	}
+	logReturns struct {
+		result1 []*object.Commit
+		result2 error
+	}
+	logReturnsOnCall map[int]struct {
+		result1 []*object.Commit
+		result2 error
+	}
 	ReferenceStub        func(plumbing.ReferenceName, bool) (*plumbing.Reference, error)
 	referenceMutex       sync.RWMutex
 	referenceArgsForCall []struct {
@@ -130,6 +144,70 @@ func (fake *FakeRepository) FetchContextReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeRepository) Log(arg1 *git.LogOptions) ([]*object.Commit, error) {
+	fake.logMutex.Lock()
+	ret, specificReturn := fake.logReturnsOnCall[len(fake.logArgsForCall)]
+	fake.logArgsForCall = append(fake.logArgsForCall, struct {
+		arg1 *git.LogOptions
+	}{arg1})
+	stub := fake.LogStub
+	fakeReturns := fake.logReturns
+	fake.recordInvocation("Log", []interface{}{arg1})
+	fake.logMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRepository) LogCallCount() int {
+	fake.logMutex.RLock()
+	defer fake.logMutex.RUnlock()
+	return len(fake.logArgsForCall)
+}
+
+func (fake *FakeRepository) LogCalls(stub func(*git.LogOptions) ([]*object.Commit, error)) {
+	fake.logMutex.Lock()
+	defer fake.logMutex.Unlock()
+	fake.LogStub = stub
+}
+
+func (fake *FakeRepository) LogArgsForCall(i int) *git.LogOptions {
+	fake.logMutex.RLock()
+	defer fake.logMutex.RUnlock()
+	argsForCall := fake.logArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRepository) LogReturns(result1 []*object.Commit, result2 error) {
+	fake.logMutex.Lock()
+	defer fake.logMutex.Unlock()
+	fake.LogStub = nil
+	fake.logReturns = struct {
+		result1 []*object.Commit
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRepository) LogReturnsOnCall(i int, result1 []*object.Commit, result2 error) {
+	fake.logMutex.Lock()
+	defer fake.logMutex.Unlock()
+	fake.LogStub = nil
+	if fake.logReturnsOnCall == nil {
+		fake.logReturnsOnCall = make(map[int]struct {
+			result1 []*object.Commit
+			result2 error
+		})
+	}
+	fake.logReturnsOnCall[i] = struct {
+		result1 []*object.Commit
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeRepository) Reference(arg1 plumbing.ReferenceName, arg2 bool) (*plumbing.Reference, error) {
 	fake.referenceMutex.Lock()
 	ret, specificReturn := fake.referenceReturnsOnCall[len(fake.referenceArgsForCall)]
@@ -312,6 +390,8 @@ func (fake *FakeRepository) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.fetchContextMutex.RLock()
 	defer fake.fetchContextMutex.RUnlock()
+	fake.logMutex.RLock()
+	defer fake.logMutex.RUnlock()
 	fake.referenceMutex.RLock()
 	defer fake.referenceMutex.RUnlock()
 	fake.tagsMutex.RLock()
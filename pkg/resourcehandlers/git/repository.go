@@ -86,10 +86,15 @@ func (r *Repository) prepare(ctx context.Context, version string) error {
 		return err
 	}
 
-	if err := w.Checkout(&gogit.CheckoutOptions{
+	opts := &gogit.CheckoutOptions{
 		Branch: getCheckoutReferenceName(repository, version),
 		Force:  true,
-	}); err != nil {
+	}
+	if opts.Branch == "" && plumbing.IsHash(version) {
+		// pinned commit
+		opts.Hash = plumbing.NewHash(version)
+	}
+	if err := w.Checkout(opts); err != nil {
 		return fmt.Errorf("couldn't checkout version %s for repository %s: %v", version, r.LocalPath, err)
 	}
 	return nil
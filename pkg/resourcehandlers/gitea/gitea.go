@@ -0,0 +1,539 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitinfo"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// apiPath is the path of Gitea REST API v1
+	apiPath = "/api/v1"
+	// perPage is the page size used for paginated API calls
+	perPage = 100
+)
+
+// commitSHA matches full commit SHAs, which are referenced as 'commit' kind
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Gitea implements resourcehandlers.ResourceHandler interface using Gitea (or Forgejo) REST API v1
+type Gitea struct {
+	client        httpclient.Client
+	token         string
+	acceptedHosts []string
+	flagVars      map[string]string
+	hugoEnabled   bool
+	defBranches   map[string]string
+	muxDefBr      sync.Mutex
+}
+
+// NewGitea creates new Gitea resource handler.
+// The token, if not empty, is sent as `Authorization: token` header on each API call.
+func NewGitea(client httpclient.Client, token string, acceptedHosts []string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &Gitea{
+		client:        client,
+		token:         token,
+		acceptedHosts: acceptedHosts,
+		flagVars:      flagVars,
+		hugoEnabled:   hugoEnabled,
+		defBranches:   make(map[string]string),
+	}
+}
+
+// ResourceInfo describes a Gitea resource URL like
+// https://gitea.com/owner/repo/(src|raw)/(branch|tag|commit)/ref/path
+// Gitea uses the same URL for files and directories.
+type ResourceInfo struct {
+	URL   *url.URL
+	Owner string
+	Repo  string
+	Type  string
+	Kind  string
+	Ref   string
+	Path  string
+}
+
+// BuildResourceInfo parses Gitea resource URL
+func BuildResourceInfo(uri string) (*ResourceInfo, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	segments := strings.SplitN(strings.Trim(u.Path, "/"), "/", 6)
+	if len(segments) < 5 {
+		return nil, fmt.Errorf("unsupported Gitea URL: %s. Need /<owner>/<repo>/(src|raw)/(branch|tag|commit)/<ref>/<path>", uri)
+	}
+	r := &ResourceInfo{
+		URL:   u,
+		Owner: segments[0],
+		Repo:  segments[1],
+		Type:  segments[2],
+		Kind:  segments[3],
+		Ref:   segments[4],
+	}
+	if r.Type != "src" && r.Type != "raw" {
+		return nil, fmt.Errorf("unsupported Gitea URL type %s: %s", r.Type, uri)
+	}
+	if r.Kind != "branch" && r.Kind != "tag" && r.Kind != "commit" {
+		return nil, fmt.Errorf("unsupported Gitea URL ref kind %s: %s", r.Kind, uri)
+	}
+	if len(segments) > 5 {
+		r.Path = strings.Trim(segments[5], "/")
+	}
+	return r, nil
+}
+
+// String returns the Gitea resource URL
+func (r *ResourceInfo) String() string {
+	return r.build(r.Type, r.Path)
+}
+
+// build returns the Gitea URL for the resource type and path in the same repository and ref
+func (r *ResourceInfo) build(tp string, p string) string {
+	uri := fmt.Sprintf("%s://%s/%s/%s/%s/%s/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Repo, tp, r.Kind, r.Ref)
+	if p != "" {
+		uri = fmt.Sprintf("%s/%s", uri, p)
+	}
+	return uri
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (g *Gitea) Accept(uri string) bool {
+	r, err := BuildResourceInfo(uri)
+	if err != nil || (r.URL.Scheme != "https" && r.URL.Scheme != "http") {
+		return false
+	}
+	for _, h := range g.acceptedHosts {
+		if h == r.URL.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (g *Gitea) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	cnt, err := g.readFile(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, r.Ref, g.flagVars, g.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, uri, func(link string) (string, error) { return g.buildAbsLink(ctx, r, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (g *Gitea) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.Node, error) {
+	r, err := g.getResolvedResourceInfo(ctx, node.NodeSelector.Path)
+	if err != nil {
+		return nil, err
+	}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := g.getTree(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.build("src", r.Path)
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = prefix
+	for _, e := range entries {
+		ePath := e.Path
+		if r.Path != "" {
+			if !strings.HasPrefix(ePath, r.Path+"/") {
+				continue // not in the node selector path
+			}
+			ePath = strings.TrimPrefix(ePath, r.Path+"/")
+		}
+		// skip node if it is not a markdown file
+		if e.Type != "blob" || !strings.HasSuffix(strings.ToLower(ePath), ".md") {
+			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, ePath)
+			continue
+		}
+		if resourcehandlers.FilterPath(node, pfs, ePath) {
+			continue
+		}
+		resourcehandlers.BuildNode(vr, prefix, ePath)
+	}
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	for _, cn := range vr.Nodes {
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (g *Gitea) Read(ctx context.Context, uri string) ([]byte, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return g.readFile(ctx, r)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+func (g *Gitea) ReadGitInfo(ctx context.Context, uri string) ([]byte, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("sha", r.Ref)
+	q.Set("path", r.Path)
+	q.Set("limit", strconv.Itoa(perPage))
+	var commits []*gitinfo.Commit
+	for page := 1; page > 0; {
+		q.Set("page", strconv.Itoa(page))
+		body, h, err := g.call(ctx, r, "/commits", q, uri)
+		if err != nil {
+			return nil, err
+		}
+		var cs []*commit
+		if err = json.Unmarshal(body, &cs); err != nil {
+			return nil, fmt.Errorf("unexpected commits response for %s: %v", uri, err)
+		}
+		for _, c := range cs {
+			commits = append(commits, &gitinfo.Commit{
+				SHA:            c.SHA,
+				Message:        c.Commit.Message,
+				AuthorName:     c.Commit.Author.Name,
+				AuthorEmail:    c.Commit.Author.Email,
+				CommitterName:  c.Commit.Committer.Name,
+				CommitterEmail: c.Commit.Committer.Email,
+				Date:           c.Commit.Committer.Date,
+			})
+		}
+		page++
+		if h.Get("X-HasMore") != "true" {
+			page = 0
+		}
+	}
+	gitInfo := gitinfo.Transform(commits)
+	if gitInfo == nil {
+		return nil, nil
+	}
+	if len(r.Ref) > 0 {
+		gitInfo.SHAAlias = &r.Ref
+	}
+	if len(r.Path) > 0 {
+		gitInfo.Path = &r.Path
+	}
+	webURL := fmt.Sprintf("%s://%s/%s/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Repo)
+	gitInfo.WebURL = &webURL
+	return gitinfo.Marshal(gitInfo)
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (g *Gitea) ResourceName(link string) (string, string) {
+	r, err := BuildResourceInfo(link)
+	if err != nil {
+		return "", ""
+	}
+	ext := path.Ext(r.Path)
+	name := strings.TrimSuffix(path.Base(r.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+func (g *Gitea) BuildAbsLink(source, link string) (string, error) {
+	r, err := BuildResourceInfo(source)
+	if err != nil {
+		return "", err
+	}
+	return g.buildAbsLink(context.Background(), r, link)
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (g *Gitea) GetRawFormatLink(absLink string) (string, error) {
+	r, err := BuildResourceInfo(absLink)
+	if err != nil {
+		return "", err
+	}
+	return r.build("raw", r.Path), nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (g *Gitea) GetClient() httpclient.Client {
+	return g.client
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+// Gitea API is not rate limited
+func (g *Gitea) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return -1, -1, time.Now(), nil
+}
+
+//========================= resourcehandlers.RefResolver =======================================================
+
+// ResourceRef implements the resourcehandlers.RefResolver#ResourceRef
+func (g *Gitea) ResourceRef(uri string) (string, string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Repo), r.Ref, nil
+}
+
+// ResolveRef implements the resourcehandlers.RefResolver#ResolveRef
+func (g *Gitea) ResolveRef(ctx context.Context, uri string) (string, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("sha", r.Ref)
+	q.Set("limit", "1")
+	body, _, err := g.call(ctx, r, "/commits", q, uri)
+	if err != nil {
+		return "", err
+	}
+	var cs []*commit
+	if err = json.Unmarshal(body, &cs); err != nil {
+		return "", fmt.Errorf("unexpected commits response for %s: %v", uri, err)
+	}
+	if len(cs) == 0 {
+		return "", resourcehandlers.ErrResourceNotFound(uri)
+	}
+	return cs[0].SHA, nil
+}
+
+// PinRef implements the resourcehandlers.RefResolver#PinRef
+func (g *Gitea) PinRef(uri string, sha string) (string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", err
+	}
+	if commitSHA.MatchString(sha) {
+		r.Kind = "commit"
+	}
+	r.Ref = sha
+	u, err := url.Parse(r.String())
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = r.URL.RawQuery
+	u.Fragment = r.URL.Fragment
+	return u.String(), nil
+}
+
+//==============================================================================================================
+
+// treeEntry is Gitea git tree entry
+type treeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+// tree is Gitea git tree
+type tree struct {
+	Entries    []*treeEntry `json:"tree"`
+	Page       int          `json:"page"`
+	TotalCount int          `json:"total_count"`
+}
+
+// commit is Gitea repository commit
+type commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// repository is Gitea repository
+type repository struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+// readFile reads a raw file from Gitea
+func (g *Gitea) readFile(ctx context.Context, r *ResourceInfo) ([]byte, error) {
+	q := url.Values{}
+	q.Set("ref", r.Ref)
+	body, _, err := g.call(ctx, r, "/raw/"+escape(r.Path), q, r.String())
+	return body, err
+}
+
+// getTree lists recursively the repository tree
+func (g *Gitea) getTree(ctx context.Context, r *ResourceInfo) ([]*treeEntry, error) {
+	q := url.Values{}
+	q.Set("recursive", "true")
+	q.Set("per_page", strconv.Itoa(perPage))
+	var entries []*treeEntry
+	for page := 1; page > 0; {
+		q.Set("page", strconv.Itoa(page))
+		body, _, err := g.call(ctx, r, "/git/trees/"+url.PathEscape(r.Ref), q, r.String())
+		if err != nil {
+			return nil, err
+		}
+		t := &tree{}
+		if err = json.Unmarshal(body, t); err != nil {
+			return nil, fmt.Errorf("unexpected tree response for %s: %v", r.String(), err)
+		}
+		entries = append(entries, t.Entries...)
+		page++
+		if len(t.Entries) == 0 || len(entries) >= t.TotalCount {
+			page = 0
+		}
+	}
+	return entries, nil
+}
+
+// call calls repository API endpoint and returns the response body and headers
+func (g *Gitea) call(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string) ([]byte, http.Header, error) {
+	apiURL := fmt.Sprintf("%s://%s%s/repos/%s/%s%s", r.URL.Scheme, r.URL.Host, apiPath, url.PathEscape(r.Owner), url.PathEscape(r.Repo), endpoint)
+	if len(q) > 0 {
+		apiURL = apiURL + "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, resourcehandlers.ErrResourceNotFound(uri)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("reading %s fails with HTTP status: %d", uri, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Header, nil
+}
+
+// buildAbsLink builds absolute link if <link> is relative using <source> as a base
+// resourcehandlers.ErrResourceNotFound if target resource doesn't exist
+func (g *Gitea) buildAbsLink(ctx context.Context, source *ResourceInfo, link string) (string, error) {
+	l, err := url.Parse(strings.TrimSuffix(link, "/"))
+	if err != nil {
+		return "", err
+	}
+	if l.IsAbs() {
+		return link, nil // already absolute
+	}
+	// build URL based on source path
+	var u *url.URL
+	if u, err = url.Parse("/" + source.Path); err != nil {
+		return "", err
+	}
+	if u, err = u.Parse(l.Path); err != nil {
+		return "", err
+	}
+	relPath := strings.TrimPrefix(u.Path, "/")
+	res, _ := url.Parse(source.build("src", relPath))
+	// set query & fragment
+	res.ForceQuery = l.ForceQuery
+	res.RawQuery = l.RawQuery
+	res.Fragment = l.Fragment
+	absLink := res.String()
+	// check if the resource exists
+	q := url.Values{}
+	q.Set("ref", source.Ref)
+	if _, _, err = g.call(ctx, source, "/contents/"+escape(relPath), q, absLink); err != nil {
+		if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+			return absLink, err
+		}
+		return "", fmt.Errorf("cannot determine resource for path %s and source %s: %v", relPath, source.String(), err)
+	}
+	return absLink, nil
+}
+
+// getResolvedResourceInfo builds ResourceInfo and resolves 'DEFAULT_BRANCH' to repository default branch
+func (g *Gitea) getResolvedResourceInfo(ctx context.Context, uri string) (*ResourceInfo, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return nil, err
+	}
+	if r.Ref == "DEFAULT_BRANCH" {
+		if r.Ref, err = g.getDefaultBranch(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// getDefaultBranch gets the default branch for given repository
+func (g *Gitea) getDefaultBranch(ctx context.Context, r *ResourceInfo) (string, error) {
+	g.muxDefBr.Lock()
+	defer g.muxDefBr.Unlock()
+	key := fmt.Sprintf("%s/%s/%s", r.URL.Host, r.Owner, r.Repo)
+	if def, ok := g.defBranches[key]; ok {
+		return def, nil
+	}
+	body, _, err := g.call(ctx, r, "", nil, r.String())
+	if err != nil {
+		return "", err
+	}
+	repo := &repository{}
+	if err = json.Unmarshal(body, repo); err != nil {
+		return "", fmt.Errorf("unexpected repository response for %s: %v", r.String(), err)
+	}
+	g.defBranches[key] = repo.DefaultBranch
+	return repo.DefaultBranch, nil
+}
+
+// escape encodes a file path preserving the '/' separators
+func escape(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
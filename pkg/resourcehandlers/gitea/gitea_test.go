@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitea_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitea"
+	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const repoAPI = "/api/v1/repos/owner/repo"
+
+var _ = Describe("Gitea", func() {
+	var (
+		server *httptest.Server
+		mux    *http.ServeMux
+		rh     resourcehandlers.ResourceHandler
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		u, _ := url.Parse(server.URL)
+		rh = gitea.NewGitea(server.Client(), "token", []string{u.Host}, map[string]string{}, false)
+		ctx = context.TODO()
+		mux.HandleFunc(repoAPI, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, `{"default_branch": "main"}`)
+		})
+		mux.HandleFunc(repoAPI+"/raw/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("token token"))
+			Expect(r.URL.Query().Get("ref")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, "# README")
+		})
+		mux.HandleFunc(repoAPI+"/raw/manifest.yaml", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, "structure:\n- source: docs/README.md\n")
+		})
+		mux.HandleFunc(repoAPI+"/contents/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, `{"type": "file"}`)
+		})
+		mux.HandleFunc(repoAPI+"/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("recursive")).To(Equal("true"))
+			entries := []map[string]string{
+				{"path": "README.md", "type": "blob"},
+				{"path": "docs", "type": "tree"},
+				{"path": "docs/README.md", "type": "blob"},
+				{"path": "docs/guides/setup.md", "type": "blob"},
+				{"path": "docs/guides/logo.png", "type": "blob"},
+			}
+			if r.URL.Query().Get("page") != "1" {
+				entries = nil
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"tree": entries, "total_count": 5})).To(Succeed())
+		})
+		mux.HandleFunc(repoAPI+"/commits", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("limit") == "1" {
+				Expect(r.URL.Query().Get("sha")).To(Equal("main"))
+				_, _ = fmt.Fprint(w, `[{"sha": "c2"}]`)
+				return
+			}
+			Expect(r.URL.Query().Get("path")).To(Equal("docs/README.md"))
+			Expect(r.URL.Query().Get("sha")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, `[
+  {"sha": "c2", "commit": {"message": "update", "author": {"name": "Jane", "email": "jane@example.com"}, "committer": {"email": "jane@example.com", "date": "2022-02-01T10:00:00Z"}}},
+  {"sha": "c1", "commit": {"message": "initial", "author": {"name": "John", "email": "john@example.com"}, "committer": {"email": "john@example.com", "date": "2022-01-01T10:00:00Z"}}}
+]`)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	src := func(p string) string {
+		return fmt.Sprintf("%s/owner/repo/src/branch/main/%s", server.URL, p)
+	}
+
+	It("accepts Gitea URLs of the configured hosts", func() {
+		Expect(rh.Accept(src("docs/README.md"))).To(BeTrue())
+		Expect(rh.Accept(fmt.Sprintf("%s/owner/repo/blob/main/README.md", server.URL))).To(BeFalse())
+		Expect(rh.Accept("https://gitea.com/owner/repo/src/branch/main/README.md")).To(BeFalse())
+	})
+
+	It("reads files", func() {
+		got, err := rh.Read(ctx, fmt.Sprintf("%s/owner/repo/src/branch/DEFAULT_BRANCH/docs/README.md", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("# README"))
+		_, err = rh.Read(ctx, src("missing.md"))
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+	})
+
+	It("resolves node selector", func() {
+		node := &api.Node{NodeSelector: &api.NodeSelector{Path: src("docs")}}
+		got, err := rh.ResolveNodeSelector(ctx, node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(got)).To(Equal(2))
+		Expect(got[0].Source).To(Equal(src("docs/README.md")))
+		Expect(got[1].Name).To(Equal("guides"))
+		Expect(got[1].Properties[api.ContainerNodeSourceLocation]).To(Equal(src("docs/guides")))
+		Expect(got[1].Nodes[0].Source).To(Equal(src("docs/guides/setup.md")))
+	})
+
+	It("resolves documentation", func() {
+		doc, err := rh.ResolveDocumentation(ctx, src("manifest.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Structure[0].Source).To(Equal(src("docs/README.md")))
+	})
+
+	It("builds absolute links", func() {
+		got, err := rh.BuildAbsLink(src("manifest.yaml"), "docs/README.md#top")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(src("docs/README.md#top")))
+		got, err = rh.BuildAbsLink(src("docs/README.md"), "missing.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		Expect(got).To(Equal(src("docs/missing.md")))
+	})
+
+	It("returns raw links", func() {
+		got, err := rh.GetRawFormatLink(src("docs/logo.png"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(fmt.Sprintf("%s/owner/repo/raw/branch/main/docs/logo.png", server.URL)))
+	})
+
+	It("reads git info", func() {
+		got, err := rh.ReadGitInfo(ctx, src("docs/README.md"))
+		Expect(err).NotTo(HaveOccurred())
+		info := &pg.GitInfo{}
+		Expect(json.Unmarshal(got, info)).To(Succeed())
+		Expect(*info.LastModifiedDate).To(Equal("2022-02-01 10:00:00"))
+		Expect(*info.PublishDate).To(Equal("2022-01-01 10:00:00"))
+		Expect(info.Author.GetEmail()).To(Equal("john@example.com"))
+		Expect(len(info.Contributors)).To(Equal(1))
+	})
+
+	It("resolves and pins refs", func() {
+		rr, ok := rh.(resourcehandlers.RefResolver)
+		Expect(ok).To(BeTrue())
+		repo, ref, err := rr.ResourceRef(src("docs/README.md"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repo).To(Equal(server.URL + "/owner/repo"))
+		Expect(ref).To(Equal("main"))
+		sha, err := rr.ResolveRef(ctx, src("docs/README.md"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sha).To(Equal("c2"))
+		sha = "0123456789abcdef0123456789abcdef01234567"
+		got, err := rr.PinRef(src("docs/README.md")+"#top", sha)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(fmt.Sprintf("%s/owner/repo/src/commit/%s/docs/README.md#top", server.URL, sha)))
+		got, err = rr.PinRef(src("docs/README.md"), "release-v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(fmt.Sprintf("%s/owner/repo/src/branch/release-v1/docs/README.md", server.URL)))
+	})
+})
@@ -408,14 +408,15 @@ func (gh *GitHub) resolveDocumentationRelativePaths(node *api.Node, moduleDocume
 		}
 	}
 	if len(node.MultiSource) > 0 {
-		for idx, src := range node.MultiSource {
+		for idx, ms := range node.MultiSource {
+			src := ms.Source
 			u, err := url.Parse(src)
 			if err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s multiSource[%d]: %s", moduleDocumentationPath, node.FullName("/"), idx, node.MultiSource[idx]))
+				errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s multiSource[%d]: %s", moduleDocumentationPath, node.FullName("/"), idx, src))
 			} else if !u.IsAbs() {
 				// resolve relative path
 				if node.Source, err = gh.BuildAbsLink(moduleDocumentationPath, src); err != nil {
-					errs = multierror.Append(errs, fmt.Errorf("cannot resolve multiSource[%d] relative path %s in node %s and manifest %s", idx, node.MultiSource[idx], node.FullName("/"), moduleDocumentationPath))
+					errs = multierror.Append(errs, fmt.Errorf("cannot resolve multiSource[%d] relative path %s in node %s and manifest %s", idx, src, node.FullName("/"), moduleDocumentationPath))
 				}
 			}
 		}
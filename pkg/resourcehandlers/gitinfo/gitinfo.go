@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitinfo
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+	"github.com/google/go-github/v43/github"
+)
+
+// Commit is a backend independent commit, used to build pg.GitInfo
+// by resource handlers that are not backed by the GitHub API
+type Commit struct {
+	SHA            string
+	Message        string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+	Date           time.Time
+}
+
+// Transform builds pg.GitInfo from a commits list, internal commits are skipped
+func Transform(commits []*Commit) *pg.GitInfo {
+	var nonInternalCommits []*Commit
+	// skip internal commits
+	for _, c := range commits {
+		if !isInternalCommit(c) {
+			nonInternalCommits = append(nonInternalCommits, c)
+		}
+	}
+	if len(nonInternalCommits) == 0 {
+		return nil
+	}
+	sort.SliceStable(nonInternalCommits, func(i, j int) bool {
+		return nonInternalCommits[i].Date.After(nonInternalCommits[j].Date)
+	})
+	gitInfo := &pg.GitInfo{}
+	lastModifiedDate := nonInternalCommits[0].Date.Format(pg.DateFormat)
+	gitInfo.LastModifiedDate = &lastModifiedDate
+	sha := nonInternalCommits[0].SHA
+	gitInfo.SHA = &sha
+	first := nonInternalCommits[len(nonInternalCommits)-1]
+	publishDate := first.Date.Format(pg.DateFormat)
+	gitInfo.PublishDate = &publishDate
+	gitInfo.Author = getCommitAuthor(first)
+	if len(nonInternalCommits) > 1 {
+		gitInfo.Contributors = []*github.User{}
+		registered := map[string]bool{gitInfo.Author.GetEmail(): true}
+		for _, c := range nonInternalCommits {
+			contributor := getCommitAuthor(c)
+			if !registered[contributor.GetEmail()] {
+				gitInfo.Contributors = append(gitInfo.Contributors, contributor)
+				registered[contributor.GetEmail()] = true
+			}
+		}
+	}
+	return gitInfo
+}
+
+// Marshal serializes pg.GitInfo to byte array
+func Marshal(gitInfo *pg.GitInfo) ([]byte, error) {
+	return json.MarshalIndent(gitInfo, "", "  ")
+}
+
+func isInternalCommit(c *Commit) bool {
+	return strings.HasPrefix(c.Message, "[int]") ||
+		strings.Contains(c.Message, "[skip ci]") ||
+		strings.HasPrefix(c.CommitterEmail, "gardener.ci") ||
+		strings.HasPrefix(c.CommitterEmail, "gardener.opensource")
+}
+
+// getCommitAuthor returns the commit author as github.User, for consistency with the GitHub git info
+func getCommitAuthor(c *Commit) *github.User {
+	name, email := c.AuthorName, c.AuthorEmail
+	if name == "" && email == "" {
+		name, email = c.CommitterName, c.CommitterEmail
+	}
+	return &github.User{Name: &name, Email: &email}
+}
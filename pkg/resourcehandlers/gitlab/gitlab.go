@@ -0,0 +1,622 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitinfo"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// apiPath is the path of GitLab REST API v4
+	apiPath = "/api/v4"
+	// perPage is the page size used for paginated API calls
+	perPage = 100
+)
+
+// GitLab implements resourcehandlers.ResourceHandler interface using GitLab REST API v4
+type GitLab struct {
+	client        httpclient.Client
+	token         string
+	acceptedHosts []string
+	flagVars      map[string]string
+	hugoEnabled   bool
+	filesCache    map[string]string
+	muxFiles      sync.RWMutex
+	defBranches   map[string]string
+	muxDefBr      sync.Mutex
+	rateLimit     rateLimit
+	muxRate       sync.Mutex
+}
+
+// rateLimit holds the last rate limit reported by the GitLab API
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// NewGitLab creates new GitLab resource handler.
+// The token, if not empty, is sent as `PRIVATE-TOKEN` header on each API call.
+func NewGitLab(client httpclient.Client, token string, acceptedHosts []string, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &GitLab{
+		client:        client,
+		token:         token,
+		acceptedHosts: acceptedHosts,
+		flagVars:      flagVars,
+		hugoEnabled:   hugoEnabled,
+		filesCache:    make(map[string]string),
+		defBranches:   make(map[string]string),
+		rateLimit:     rateLimit{limit: -1, remaining: -1},
+	}
+}
+
+// ResourceInfo describes a GitLab resource URL like
+// https://gitlab.com/group/subgroup/project/-/(blob|tree|raw)/ref/path
+type ResourceInfo struct {
+	URL     *url.URL
+	Project string
+	Type    string
+	Ref     string
+	Path    string
+}
+
+// BuildResourceInfo parses GitLab resource URL
+func BuildResourceInfo(uri string) (*ResourceInfo, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	idx := strings.Index(u.Path, "/-/")
+	if idx < 0 {
+		return nil, fmt.Errorf("unsupported GitLab URL: %s. Need /<project>/-/(blob|tree|raw)/<ref>/<path>", uri)
+	}
+	project := strings.Trim(u.Path[:idx], "/")
+	parts := strings.SplitN(u.Path[idx+len("/-/"):], "/", 3)
+	if project == "" || len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("unsupported GitLab URL: %s. Need /<project>/-/(blob|tree|raw)/<ref>/<path>", uri)
+	}
+	r := &ResourceInfo{
+		URL:     u,
+		Project: project,
+		Type:    parts[0],
+		Ref:     parts[1],
+	}
+	if r.Type != "blob" && r.Type != "tree" && r.Type != "raw" {
+		return nil, fmt.Errorf("unsupported GitLab URL type %s: %s", r.Type, uri)
+	}
+	if len(parts) > 2 {
+		r.Path = strings.Trim(parts[2], "/")
+	}
+	return r, nil
+}
+
+// String returns the GitLab resource URL
+func (r *ResourceInfo) String() string {
+	return r.build(r.Type, r.Path)
+}
+
+// build returns the GitLab URL for the resource type and path in the same project and ref
+func (r *ResourceInfo) build(tp string, p string) string {
+	uri := fmt.Sprintf("%s://%s/%s/-/%s/%s", r.URL.Scheme, r.URL.Host, r.Project, tp, r.Ref)
+	if p != "" {
+		uri = fmt.Sprintf("%s/%s", uri, p)
+	}
+	return uri
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (g *GitLab) Accept(uri string) bool {
+	r, err := BuildResourceInfo(uri)
+	if err != nil || (r.URL.Scheme != "https" && r.URL.Scheme != "http") {
+		return false
+	}
+	for _, h := range g.acceptedHosts {
+		if h == r.URL.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (g *GitLab) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if r.Type == "tree" {
+		return nil, fmt.Errorf("not a blob url: %s", uri)
+	}
+	cnt, err := g.readFile(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, r.Ref, g.flagVars, g.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, uri, func(link string) (string, error) { return g.buildAbsLink(ctx, r, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (g *GitLab) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.Node, error) {
+	r, err := g.getResolvedResourceInfo(ctx, node.NodeSelector.Path)
+	if err != nil {
+		return nil, err
+	}
+	if r.Type != "tree" {
+		return nil, fmt.Errorf("not a tree url: %s", node.NodeSelector.Path)
+	}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := g.getTree(ctx, r, r.Path, node.NodeSelector.Depth != 1)
+	if err != nil {
+		return nil, err
+	}
+	bPrefix := r.build("blob", r.Path)
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = r.build("tree", r.Path)
+	for _, e := range entries {
+		ePath := strings.TrimPrefix(e.Path, r.Path)
+		ePath = strings.TrimPrefix(ePath, "/")
+		// skip node if it is not a markdown file
+		if e.Type != "blob" || !strings.HasSuffix(strings.ToLower(ePath), ".md") {
+			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, ePath)
+			continue
+		}
+		if resourcehandlers.FilterPath(node, pfs, ePath) {
+			continue
+		}
+		resourcehandlers.BuildNode(vr, bPrefix, ePath)
+	}
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	for _, cn := range vr.Nodes {
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (g *GitLab) Read(ctx context.Context, uri string) ([]byte, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if r.Type == "tree" {
+		return nil, fmt.Errorf("not a blob url: %s", uri)
+	}
+	return g.readFile(ctx, r)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+func (g *GitLab) ReadGitInfo(ctx context.Context, uri string) ([]byte, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("path", r.Path)
+	q.Set("ref_name", r.Ref)
+	var commits []*commit
+	if err = g.getAll(ctx, r, "/repository/commits", q, uri, func(body []byte) (int, error) {
+		var page []*commit
+		if err := json.Unmarshal(body, &page); err != nil {
+			return 0, err
+		}
+		commits = append(commits, page...)
+		return len(page), nil
+	}); err != nil {
+		return nil, err
+	}
+	gitInfo := gitinfo.Transform(toGitInfoCommits(commits))
+	if gitInfo == nil {
+		return nil, nil
+	}
+	if len(r.Ref) > 0 {
+		gitInfo.SHAAlias = &r.Ref
+	}
+	if len(r.Path) > 0 {
+		gitInfo.Path = &r.Path
+	}
+	webURL := fmt.Sprintf("%s://%s/%s", r.URL.Scheme, r.URL.Host, r.Project)
+	gitInfo.WebURL = &webURL
+	return gitinfo.Marshal(gitInfo)
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (g *GitLab) ResourceName(link string) (string, string) {
+	r, err := BuildResourceInfo(link)
+	if err != nil {
+		return "", ""
+	}
+	ext := path.Ext(r.Path)
+	name := strings.TrimSuffix(path.Base(r.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+func (g *GitLab) BuildAbsLink(source, link string) (string, error) {
+	r, err := BuildResourceInfo(source)
+	if err != nil {
+		return "", err
+	}
+	return g.buildAbsLink(context.Background(), r, link)
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (g *GitLab) GetRawFormatLink(absLink string) (string, error) {
+	r, err := BuildResourceInfo(absLink)
+	if err != nil {
+		return "", err
+	}
+	if r.Type != "blob" {
+		return absLink, nil
+	}
+	return r.build("raw", r.Path), nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (g *GitLab) GetClient() httpclient.Client {
+	return g.client
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+// GitLab doesn't provide a rate limit API, the values reported by the last API call are returned
+func (g *GitLab) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	g.muxRate.Lock()
+	defer g.muxRate.Unlock()
+	if g.rateLimit.reset.IsZero() {
+		return g.rateLimit.limit, g.rateLimit.remaining, time.Now(), nil
+	}
+	return g.rateLimit.limit, g.rateLimit.remaining, g.rateLimit.reset, nil
+}
+
+//========================= resourcehandlers.RefResolver =======================================================
+
+// ResourceRef implements the resourcehandlers.RefResolver#ResourceRef
+func (g *GitLab) ResourceRef(uri string) (string, string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s://%s/%s", r.URL.Scheme, r.URL.Host, r.Project), r.Ref, nil
+}
+
+// ResolveRef implements the resourcehandlers.RefResolver#ResolveRef
+func (g *GitLab) ResolveRef(ctx context.Context, uri string) (string, error) {
+	r, err := g.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	body, err := g.get(ctx, r, "/repository/commits/"+escape(r.Ref), nil, uri)
+	if err != nil {
+		return "", err
+	}
+	c := &commit{}
+	if err = json.Unmarshal(body, c); err != nil {
+		return "", fmt.Errorf("unexpected commit response for %s: %v", uri, err)
+	}
+	return c.ID, nil
+}
+
+// PinRef implements the resourcehandlers.RefResolver#PinRef
+func (g *GitLab) PinRef(uri string, sha string) (string, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return "", err
+	}
+	r.Ref = sha
+	u, err := url.Parse(r.String())
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = r.URL.RawQuery
+	u.Fragment = r.URL.Fragment
+	return u.String(), nil
+}
+
+//==============================================================================================================
+
+// treeEntry is GitLab repository tree entry
+type treeEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// commit is GitLab repository commit
+type commit struct {
+	ID             string    `json:"id"`
+	Message        string    `json:"message"`
+	AuthorName     string    `json:"author_name"`
+	AuthorEmail    string    `json:"author_email"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedDate  time.Time `json:"committed_date"`
+}
+
+// toGitInfoCommits converts GitLab commits to gitinfo.Commit list
+func toGitInfoCommits(commits []*commit) []*gitinfo.Commit {
+	var res []*gitinfo.Commit
+	for _, c := range commits {
+		res = append(res, &gitinfo.Commit{
+			SHA:            c.ID,
+			Message:        c.Message,
+			AuthorName:     c.AuthorName,
+			AuthorEmail:    c.AuthorEmail,
+			CommitterName:  c.CommitterName,
+			CommitterEmail: c.CommitterEmail,
+			Date:           c.CommittedDate,
+		})
+	}
+	return res
+}
+
+// project is GitLab project
+type project struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+// readFile reads a raw file from GitLab
+func (g *GitLab) readFile(ctx context.Context, r *ResourceInfo) ([]byte, error) {
+	q := url.Values{}
+	q.Set("ref", r.Ref)
+	return g.get(ctx, r, "/repository/files/"+escape(r.Path)+"/raw", q, r.String())
+}
+
+// getTree lists the repository tree at path p
+func (g *GitLab) getTree(ctx context.Context, r *ResourceInfo, p string, recursive bool) ([]*treeEntry, error) {
+	q := url.Values{}
+	q.Set("ref", r.Ref)
+	if p != "" {
+		q.Set("path", p)
+	}
+	if recursive {
+		q.Set("recursive", "true")
+	}
+	var entries []*treeEntry
+	if err := g.getAll(ctx, r, "/repository/tree", q, r.build("tree", p), func(body []byte) (int, error) {
+		var page []*treeEntry
+		if err := json.Unmarshal(body, &page); err != nil {
+			return 0, err
+		}
+		entries = append(entries, page...)
+		return len(page), nil
+	}); err != nil {
+		return nil, err
+	}
+	// cache the type of the entries
+	g.muxFiles.Lock()
+	defer g.muxFiles.Unlock()
+	for _, e := range entries {
+		g.filesCache[r.build(e.Type, e.Path)] = e.ID
+	}
+	return entries, nil
+}
+
+// getAll calls paginated project API endpoint until all pages are read
+func (g *GitLab) getAll(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string, collect func(body []byte) (int, error)) error {
+	q.Set("per_page", strconv.Itoa(perPage))
+	page := 1
+	for page > 0 {
+		q.Set("page", strconv.Itoa(page))
+		body, next, err := g.call(ctx, r, endpoint, q, uri)
+		if err != nil {
+			return err
+		}
+		var n int
+		if n, err = collect(body); err != nil {
+			return fmt.Errorf("unexpected response for %s: %v", uri, err)
+		}
+		page = 0
+		if next != "" {
+			if page, err = strconv.Atoi(next); err != nil {
+				return fmt.Errorf("invalid next page %s for %s: %v", next, uri, err)
+			}
+		} else if n == perPage {
+			// pagination headers are omitted for large collections
+			page++
+		}
+	}
+	return nil
+}
+
+// get calls project API endpoint and returns the response body
+func (g *GitLab) get(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string) ([]byte, error) {
+	body, _, err := g.call(ctx, r, endpoint, q, uri)
+	return body, err
+}
+
+// call calls project API endpoint and returns the response body and the next page, if any
+func (g *GitLab) call(ctx context.Context, r *ResourceInfo, endpoint string, q url.Values, uri string) ([]byte, string, error) {
+	apiURL := fmt.Sprintf("%s://%s%s/projects/%s%s", r.URL.Scheme, r.URL.Host, apiPath, escape(r.Project), endpoint)
+	if len(q) > 0 {
+		apiURL = apiURL + "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	g.updateRateLimit(resp.Header)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", resourcehandlers.ErrResourceNotFound(uri)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("reading %s fails with HTTP status: %d", uri, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, resp.Header.Get("X-Next-Page"), nil
+}
+
+// updateRateLimit records the rate limit headers of API response
+func (g *GitLab) updateRateLimit(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	var reset time.Time
+	if s, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(s, 0)
+	}
+	g.muxRate.Lock()
+	defer g.muxRate.Unlock()
+	g.rateLimit = rateLimit{limit: limit, remaining: remaining, reset: reset}
+}
+
+// buildAbsLink builds absolute link if <link> is relative using <source> as a base
+// resourcehandlers.ErrResourceNotFound if target resource doesn't exist
+func (g *GitLab) buildAbsLink(ctx context.Context, source *ResourceInfo, link string) (string, error) {
+	l, err := url.Parse(strings.TrimSuffix(link, "/"))
+	if err != nil {
+		return "", err
+	}
+	if l.IsAbs() {
+		return link, nil // already absolute
+	}
+	// build URL based on source path
+	var u *url.URL
+	if u, err = url.Parse("/" + source.Path); err != nil {
+		return "", err
+	}
+	if u, err = u.Parse(l.Path); err != nil {
+		return "", err
+	}
+	relPath := strings.TrimPrefix(u.Path, "/")
+	// determine the type of the resource: (blob|tree)
+	var tp string
+	if tp, err = g.determineLinkType(ctx, source, relPath); err != nil {
+		return tp, err
+	}
+	res, _ := url.Parse(source.build(tp, relPath))
+	// set query & fragment
+	res.ForceQuery = l.ForceQuery
+	res.RawQuery = l.RawQuery
+	res.Fragment = l.Fragment
+	return res.String(), nil
+}
+
+// determineLinkType returns the type of relative link (blob|tree)
+// resourcehandlers.ErrResourceNotFound if target resource doesn't exist
+func (g *GitLab) determineLinkType(ctx context.Context, source *ResourceInfo, relPath string) (string, error) {
+	gtp := "tree" // guess the type of resource
+	if len(path.Ext(relPath)) > 0 {
+		gtp = "blob"
+	}
+	expURI := source.build(gtp, relPath)
+	for _, tp := range []string{"blob", "tree"} {
+		if _, ok := g.getCachedID(source.build(tp, relPath)); ok {
+			return tp, nil
+		}
+	}
+	dir := path.Dir(relPath)
+	if dir == "." {
+		dir = ""
+	}
+	entries, err := g.getTree(ctx, source, dir, false)
+	if err != nil {
+		if _, ok := err.(resourcehandlers.ErrResourceNotFound); ok {
+			return expURI, resourcehandlers.ErrResourceNotFound(source.build("tree", dir))
+		}
+		return "", fmt.Errorf("cannot determine resource type for path %s and source %s: %v", relPath, source.String(), err)
+	}
+	for _, e := range entries {
+		if e.Path == relPath {
+			return e.Type, nil
+		}
+	}
+	return expURI, resourcehandlers.ErrResourceNotFound(expURI)
+}
+
+// getResolvedResourceInfo builds ResourceInfo and resolves 'DEFAULT_BRANCH' to project default branch
+func (g *GitLab) getResolvedResourceInfo(ctx context.Context, uri string) (*ResourceInfo, error) {
+	r, err := BuildResourceInfo(uri)
+	if err != nil {
+		return nil, err
+	}
+	if r.Ref == "DEFAULT_BRANCH" {
+		if r.Ref, err = g.getDefaultBranch(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// getDefaultBranch gets the default branch for given project
+func (g *GitLab) getDefaultBranch(ctx context.Context, r *ResourceInfo) (string, error) {
+	g.muxDefBr.Lock()
+	defer g.muxDefBr.Unlock()
+	key := fmt.Sprintf("%s/%s", r.URL.Host, r.Project)
+	if def, ok := g.defBranches[key]; ok {
+		return def, nil
+	}
+	body, err := g.get(ctx, r, "", nil, r.String())
+	if err != nil {
+		return "", err
+	}
+	p := &project{}
+	if err = json.Unmarshal(body, p); err != nil {
+		return "", fmt.Errorf("unexpected project response for %s: %v", r.String(), err)
+	}
+	g.defBranches[key] = p.DefaultBranch
+	return p.DefaultBranch, nil
+}
+
+func (g *GitLab) getCachedID(key string) (string, bool) {
+	g.muxFiles.RLock()
+	defer g.muxFiles.RUnlock()
+	val, ok := g.filesCache[key]
+	return val, ok
+}
+
+// escape encodes a project or file path as single URL path segment
+func escape(p string) string {
+	return strings.ReplaceAll(url.PathEscape(p), "/", "%2F")
+}
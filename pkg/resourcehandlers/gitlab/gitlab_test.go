@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitlab_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/gitlab"
+	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const projectAPI = "/api/v4/projects/group%2Fsub%2Fproject"
+
+var _ = Describe("GitLab", func() {
+	var (
+		server *httptest.Server
+		mux    *http.ServeMux
+		rh     resourcehandlers.ResourceHandler
+		host   string
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// GitLab project & file paths are URL encoded path segments
+			r.URL.Path = r.URL.EscapedPath()
+			w.Header().Set("RateLimit-Limit", "600")
+			w.Header().Set("RateLimit-Remaining", "599")
+			w.Header().Set("RateLimit-Reset", "1700000000")
+			mux.ServeHTTP(w, r)
+		}))
+		u, _ := url.Parse(server.URL)
+		host = u.Host
+		rh = gitlab.NewGitLab(server.Client(), "token", []string{host}, map[string]string{}, false)
+		ctx = context.TODO()
+		mux.HandleFunc(projectAPI, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, `{"default_branch": "main"}`)
+		})
+		mux.HandleFunc(projectAPI+"/repository/files/docs%2FREADME.md/raw", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("PRIVATE-TOKEN")).To(Equal("token"))
+			Expect(r.URL.Query().Get("ref")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, "# README")
+		})
+		mux.HandleFunc(projectAPI+"/repository/files/manifest.yaml/raw", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, "structure:\n- source: docs/README.md\n- name: guides\n  nodesSelector:\n    path: docs/guides\n")
+		})
+		mux.HandleFunc(projectAPI+"/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+			var entries []map[string]string
+			switch r.URL.Query().Get("path") {
+			case "":
+				entries = []map[string]string{
+					{"id": "1", "name": "docs", "type": "tree", "path": "docs"},
+					{"id": "2", "name": "manifest.yaml", "type": "blob", "path": "manifest.yaml"},
+				}
+			case "docs":
+				if r.URL.Query().Get("recursive") == "true" {
+					if r.URL.Query().Get("page") == "1" {
+						w.Header().Set("X-Next-Page", "2")
+						entries = []map[string]string{
+							{"id": "3", "name": "README.md", "type": "blob", "path": "docs/README.md"},
+							{"id": "4", "name": "guides", "type": "tree", "path": "docs/guides"},
+						}
+					} else {
+						entries = []map[string]string{
+							{"id": "5", "name": "setup.md", "type": "blob", "path": "docs/guides/setup.md"},
+							{"id": "6", "name": "logo.png", "type": "blob", "path": "docs/guides/logo.png"},
+						}
+					}
+				} else {
+					entries = []map[string]string{
+						{"id": "3", "name": "README.md", "type": "blob", "path": "docs/README.md"},
+						{"id": "4", "name": "guides", "type": "tree", "path": "docs/guides"},
+					}
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(entries)).To(Succeed())
+		})
+		mux.HandleFunc(projectAPI+"/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("path")).To(Equal("docs/README.md"))
+			Expect(r.URL.Query().Get("ref_name")).To(Equal("main"))
+			_, _ = fmt.Fprint(w, `[
+  {"id": "c3", "message": "[int] internal", "author_name": "bot", "author_email": "bot@example.com", "committed_date": "2022-03-01T10:00:00Z"},
+  {"id": "c2", "message": "update", "author_name": "Jane", "author_email": "jane@example.com", "committed_date": "2022-02-01T10:00:00Z"},
+  {"id": "c1", "message": "initial", "author_name": "John", "author_email": "john@example.com", "committed_date": "2022-01-01T10:00:00Z"}
+]`)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	blob := func(p string) string {
+		return fmt.Sprintf("%s/group/sub/project/-/blob/main/%s", server.URL, p)
+	}
+	tree := func(p string) string {
+		return fmt.Sprintf("%s/group/sub/project/-/tree/main/%s", server.URL, p)
+	}
+
+	Describe("BuildResourceInfo", func() {
+		It("parses nested group URLs", func() {
+			r, err := gitlab.BuildResourceInfo("https://gitlab.com/group/sub/project/-/blob/main/docs/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Project).To(Equal("group/sub/project"))
+			Expect(r.Type).To(Equal("blob"))
+			Expect(r.Ref).To(Equal("main"))
+			Expect(r.Path).To(Equal("docs/README.md"))
+		})
+		It("rejects non GitLab URLs", func() {
+			_, err := gitlab.BuildResourceInfo("https://github.com/owner/repo/blob/main/README.md")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Accept", func() {
+		It("accepts GitLab URLs of the configured hosts", func() {
+			Expect(rh.Accept(blob("docs/README.md"))).To(BeTrue())
+			Expect(rh.Accept("https://gitlab.com/group/project/-/blob/main/README.md")).To(BeFalse())
+			Expect(rh.Accept(fmt.Sprintf("%s/owner/repo/blob/main/README.md", server.URL))).To(BeFalse())
+		})
+	})
+
+	Describe("Read", func() {
+		It("reads blob and raw URLs", func() {
+			got, err := rh.Read(ctx, blob("docs/README.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(got)).To(Equal("# README"))
+			got, err = rh.Read(ctx, fmt.Sprintf("%s/group/sub/project/-/raw/DEFAULT_BRANCH/docs/README.md", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(got)).To(Equal("# README"))
+		})
+		It("returns not found error", func() {
+			_, err := rh.Read(ctx, blob("missing.md"))
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		})
+	})
+
+	Describe("ResolveNodeSelector", func() {
+		It("lists the tree", func() {
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: tree("docs")}}
+			got, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(got)).To(Equal(2))
+			Expect(got[0].Source).To(Equal(blob("docs/README.md")))
+			Expect(got[1].Name).To(Equal("guides"))
+			Expect(got[1].Properties[api.ContainerNodeSourceLocation]).To(Equal(tree("docs/guides")))
+			Expect(len(got[1].Nodes)).To(Equal(1))
+			Expect(got[1].Nodes[0].Source).To(Equal(blob("docs/guides/setup.md")))
+		})
+	})
+
+	Describe("ResolveDocumentation", func() {
+		It("resolves relative paths", func() {
+			doc, err := rh.ResolveDocumentation(ctx, blob("manifest.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(doc.Structure)).To(Equal(2))
+			Expect(doc.Structure[0].Source).To(Equal(blob("docs/README.md")))
+			Expect(doc.Structure[1].NodeSelector.Path).To(Equal(tree("docs/guides")))
+		})
+	})
+
+	Describe("BuildAbsLink", func() {
+		It("builds links to existing resources", func() {
+			got, err := rh.BuildAbsLink(blob("docs/README.md"), "guides#setup")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(tree("docs/guides") + "#setup"))
+		})
+		It("returns not found error", func() {
+			got, err := rh.BuildAbsLink(blob("docs/README.md"), "missing.md")
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+			Expect(got).To(Equal(blob("docs/missing.md")))
+		})
+	})
+
+	Describe("GetRawFormatLink", func() {
+		It("returns raw URL for blobs", func() {
+			got, err := rh.GetRawFormatLink(blob("docs/logo.png"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(fmt.Sprintf("%s/group/sub/project/-/raw/main/docs/logo.png", server.URL)))
+		})
+	})
+
+	Describe("ReadGitInfo", func() {
+		It("transforms the commit history", func() {
+			got, err := rh.ReadGitInfo(ctx, blob("docs/README.md"))
+			Expect(err).NotTo(HaveOccurred())
+			info := &pg.GitInfo{}
+			Expect(json.Unmarshal(got, info)).To(Succeed())
+			Expect(*info.LastModifiedDate).To(Equal("2022-02-01 10:00:00"))
+			Expect(*info.PublishDate).To(Equal("2022-01-01 10:00:00"))
+			Expect(*info.SHA).To(Equal("c2"))
+			Expect(info.Author.GetEmail()).To(Equal("john@example.com"))
+			Expect(len(info.Contributors)).To(Equal(1))
+			Expect(info.Contributors[0].GetEmail()).To(Equal("jane@example.com"))
+		})
+	})
+
+	Describe("RefResolver", func() {
+		BeforeEach(func() {
+			mux.HandleFunc(projectAPI+"/repository/commits/main", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, `{"id": "c2", "message": "update"}`)
+			})
+		})
+		It("resolves and pins refs", func() {
+			rr, ok := rh.(resourcehandlers.RefResolver)
+			Expect(ok).To(BeTrue())
+			repo, ref, err := rr.ResourceRef(fmt.Sprintf("%s/group/sub/project/-/blob/DEFAULT_BRANCH/docs/README.md", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo).To(Equal(server.URL + "/group/sub/project"))
+			Expect(ref).To(Equal("DEFAULT_BRANCH"))
+			sha, err := rr.ResolveRef(ctx, fmt.Sprintf("%s/group/sub/project/-/blob/DEFAULT_BRANCH/docs/README.md", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sha).To(Equal("c2"))
+			got, err := rr.PinRef(tree("docs")+"?x=1#top", "c2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(fmt.Sprintf("%s/group/sub/project/-/tree/c2/docs?x=1#top", server.URL)))
+		})
+	})
+
+	Describe("GetRateLimit", func() {
+		It("reports the last API rate limit", func() {
+			_, err := rh.Read(ctx, blob("docs/README.md"))
+			Expect(err).NotTo(HaveOccurred())
+			limit, remaining, _, err := rh.GetRateLimit(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(limit).To(Equal(600))
+			Expect(remaining).To(Equal(599))
+		})
+	})
+})
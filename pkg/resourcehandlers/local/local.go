@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+	"github.com/gardener/docforge/pkg/util/osshim"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Scheme is the URI scheme of local file system resources
+	Scheme = "file"
+)
+
+// Local implements resourcehandlers.ResourceHandler interface for resources on the local file system.
+// It accepts `file://` URIs and plain paths. Relative paths are resolved against the current working directory.
+type Local struct {
+	os          osshim.Os
+	flagVars    map[string]string
+	hugoEnabled bool
+}
+
+// NewLocal creates new Local resource handler
+func NewLocal(os osshim.Os, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &Local{
+		os:          os,
+		flagVars:    flagVars,
+		hugoEnabled: hugoEnabled,
+	}
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (l *Local) Accept(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == Scheme {
+		return true
+	}
+	return u.Scheme == "" && u.Host == "" && u.Path != ""
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (l *Local) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	fn, err := toFilePath(uri)
+	if err != nil {
+		return nil, err
+	}
+	source := ToURI(fn)
+	cnt, err := l.readFile(ctx, fn, uri)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, "master", l.flagVars, l.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, source, func(link string) (string, error) { return l.BuildAbsLink(source, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (l *Local) ResolveNodeSelector(_ context.Context, node *api.Node) ([]*api.Node, error) {
+	dn, err := toFilePath(node.NodeSelector.Path)
+	if err != nil {
+		return nil, err
+	}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	info, err := l.os.Lstat(dn)
+	if err != nil {
+		if l.os.IsNotExist(err) {
+			return nil, resourcehandlers.ErrResourceNotFound(node.NodeSelector.Path)
+		}
+		return nil, fmt.Errorf("nodeSelector path stats %s for node %s fails: %v", dn, node.FullName("/"), err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("nodeSelector path %s for node %s is not a directory", dn, node.FullName("/"))
+	}
+	// the paths are joined unescaped, see setSourceURIs
+	vr := &api.Node{Name: "vRoot", Properties: make(map[string]interface{})}
+	vr.Properties[api.ContainerNodeSourceLocation] = ""
+	err = filepath.WalkDir(dn, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		lPath := filepath.ToSlash(strings.TrimPrefix(p, dn))
+		lPath = strings.TrimPrefix(lPath, "/")
+		// skip entry if it is not a markdown
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(lPath), ".md") {
+			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, lPath)
+			return nil
+		}
+		if resourcehandlers.FilterPath(node, pfs, lPath) {
+			return nil
+		}
+		resourcehandlers.BuildNode(vr, "", lPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking nodeSelector path %s for node %s: %v", dn, node.FullName("/"), err)
+	}
+	setSourceURIs(vr, dn)
+	vr.SetParentsDownwards()
+	vr.Cleanup()
+	vr.Sort()
+	for _, cn := range vr.Nodes {
+		cn.SetParent(nil)
+	}
+	return vr.Nodes, nil
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (l *Local) Read(ctx context.Context, uri string) ([]byte, error) {
+	fn, err := toFilePath(uri)
+	if err != nil {
+		return nil, err
+	}
+	return l.readFile(ctx, fn, uri)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+// Git info is not available for local resources
+func (l *Local) ReadGitInfo(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (l *Local) ResourceName(link string) (string, string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", ""
+	}
+	ext := path.Ext(u.Path)
+	name := strings.TrimSuffix(path.Base(u.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+func (l *Local) BuildAbsLink(source, link string) (string, error) {
+	lu, err := url.Parse(strings.TrimSuffix(link, "/"))
+	if err != nil {
+		return "", err
+	}
+	if lu.IsAbs() {
+		return link, nil // already absolute
+	}
+	fn := filepath.FromSlash(lu.Path)
+	if !filepath.IsAbs(fn) {
+		var base string
+		if base, err = toFilePath(source); err != nil {
+			return "", err
+		}
+		fn = filepath.Join(filepath.Dir(base), fn)
+	}
+	res, _ := url.Parse(ToURI(fn))
+	res.ForceQuery = lu.ForceQuery
+	res.RawQuery = lu.RawQuery
+	res.Fragment = lu.Fragment
+	absLink := res.String()
+	if _, err = l.os.Lstat(fn); err != nil {
+		if l.os.IsNotExist(err) {
+			return absLink, resourcehandlers.ErrResourceNotFound(absLink)
+		}
+		return "", fmt.Errorf("cannot determine resource type for path %s and source %s: %v", link, source, err)
+	}
+	return absLink, nil
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (l *Local) GetRawFormatLink(absLink string) (string, error) {
+	return absLink, nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (l *Local) GetClient() httpclient.Client {
+	return nil
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+func (l *Local) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return -1, -1, time.Now(), nil
+}
+
+//==============================================================================================================
+
+// ToURI returns the `file://` URI for a file system path, reserved characters in the path are escaped
+func ToURI(fn string) string {
+	return (&url.URL{Scheme: Scheme, Path: filepath.ToSlash(fn)}).String()
+}
+
+// setSourceURIs replaces the node sources & container locations relative to the directory with their `file://` URIs
+func setSourceURIs(node *api.Node, dn string) {
+	if node.Source != "" {
+		node.Source = ToURI(filepath.Join(dn, filepath.FromSlash(node.Source)))
+	}
+	if loc, ok := node.Properties[api.ContainerNodeSourceLocation].(string); ok {
+		node.Properties[api.ContainerNodeSourceLocation] = ToURI(filepath.Join(dn, filepath.FromSlash(loc)))
+	}
+	for _, n := range node.Nodes {
+		setSourceURIs(n, dn)
+	}
+}
+
+// toFilePath returns the absolute file system path of a local resource uri
+func toFilePath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "" && u.Scheme != Scheme {
+		return "", fmt.Errorf("not a local resource: %s", uri)
+	}
+	return filepath.Abs(filepath.FromSlash(u.Path))
+}
+
+// readFile reads a file from FS
+func (l *Local) readFile(_ context.Context, fn string, uri string) ([]byte, error) {
+	cnt, err := l.os.ReadFile(fn)
+	if err != nil {
+		if l.os.IsNotExist(err) {
+			return nil, resourcehandlers.ErrResourceNotFound(uri)
+		}
+		return nil, fmt.Errorf("reading file %s for uri %s fails: %v", fn, uri, err)
+	}
+	return cnt, nil
+}
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package local_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/local"
+	"github.com/gardener/docforge/pkg/util/osshim"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Local", func() {
+	var (
+		dir string
+		rh  resourcehandlers.ResourceHandler
+		ctx context.Context
+	)
+
+	writeFile := func(name string, content string) {
+		fn := filepath.Join(dir, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(fn), 0755)).To(Succeed())
+		Expect(os.WriteFile(fn, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "docforge-local")
+		Expect(err).NotTo(HaveOccurred())
+		rh = local.NewLocal(&osshim.OsShim{}, map[string]string{}, false)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Describe("Accept", func() {
+		It("accepts file URIs and paths", func() {
+			Expect(rh.Accept("file:///docs/README.md")).To(BeTrue())
+			Expect(rh.Accept("docs/README.md")).To(BeTrue())
+			Expect(rh.Accept("/docs/README.md")).To(BeTrue())
+		})
+		It("rejects remote URLs", func() {
+			Expect(rh.Accept("https://github.com/gardener/docforge/blob/master/README.md")).To(BeFalse())
+			Expect(rh.Accept("")).To(BeFalse())
+		})
+	})
+
+	Describe("Read", func() {
+		It("reads file content", func() {
+			writeFile("README.md", "# Readme")
+			got, err := rh.Read(ctx, local.ToURI(filepath.Join(dir, "README.md")))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(got)).To(Equal("# Readme"))
+		})
+		It("returns not found error", func() {
+			_, err := rh.Read(ctx, local.ToURI(filepath.Join(dir, "missing.md")))
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+		})
+		It("reads file with reserved characters in the path", func() {
+			writeFile("my docs/a #1 100%?.md", "# A")
+			uri := local.ToURI(filepath.Join(dir, "my docs", "a #1 100%?.md"))
+			Expect(uri).NotTo(ContainSubstring("#"))
+			u, err := url.Parse(uri)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.Path).To(Equal(filepath.ToSlash(filepath.Join(dir, "my docs", "a #1 100%?.md"))))
+			got, err := rh.Read(ctx, uri)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(got)).To(Equal("# A"))
+		})
+	})
+
+	Describe("BuildAbsLink", func() {
+		It("resolves relative links against the source", func() {
+			writeFile("docs/a.md", "a")
+			writeFile("img/b.png", "b")
+			source := local.ToURI(filepath.Join(dir, "docs", "a.md"))
+			got, err := rh.BuildAbsLink(source, "../img/b.png?raw=true#top")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(local.ToURI(filepath.Join(dir, "img", "b.png")) + "?raw=true#top"))
+		})
+		It("keeps absolute links", func() {
+			got, err := rh.BuildAbsLink(local.ToURI(dir), "https://github.com/gardener/docforge")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal("https://github.com/gardener/docforge"))
+		})
+		It("returns not found error with the resolved link", func() {
+			got, err := rh.BuildAbsLink(local.ToURI(filepath.Join(dir, "a.md")), "b.md")
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+			Expect(got).To(Equal(local.ToURI(filepath.Join(dir, "b.md"))))
+		})
+	})
+
+	Describe("ResourceName", func() {
+		It("returns name and extension", func() {
+			name, ext := rh.ResourceName("file:///docs/README.md")
+			Expect(name).To(Equal("README"))
+			Expect(ext).To(Equal(".md"))
+		})
+	})
+
+	Describe("ResolveNodeSelector", func() {
+		It("builds the node hierarchy of a directory", func() {
+			writeFile("docs/README.md", "r")
+			writeFile("docs/guides/setup.md", "s")
+			writeFile("docs/guides/deep/skip.md", "d")
+			writeFile("docs/images/logo.png", "l")
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: local.ToURI(filepath.Join(dir, "docs")), Depth: 2}}
+			got, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(got)).To(Equal(2))
+			Expect(got[0].Name).To(Equal("README.md"))
+			Expect(got[0].Source).To(Equal(local.ToURI(filepath.Join(dir, "docs", "README.md"))))
+			Expect(got[1].Name).To(Equal("guides"))
+			Expect(got[1].Properties[api.ContainerNodeSourceLocation]).To(Equal(local.ToURI(filepath.Join(dir, "docs", "guides"))))
+			Expect(len(got[1].Nodes)).To(Equal(1))
+			Expect(got[1].Nodes[0].Name).To(Equal("setup.md"))
+		})
+		It("applies exclude paths", func() {
+			writeFile("docs/README.md", "r")
+			writeFile("docs/internal/notes.md", "n")
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: local.ToURI(filepath.Join(dir, "docs")), ExcludePaths: []string{"^internal/"}}}
+			got, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(got)).To(Equal(1))
+			Expect(got[0].Name).To(Equal("README.md"))
+		})
+		It("escapes the node sources", func() {
+			writeFile("docs/my guides/a #1.md", "a")
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: local.ToURI(filepath.Join(dir, "docs"))}}
+			got, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(got)).To(Equal(1))
+			Expect(got[0].Properties[api.ContainerNodeSourceLocation]).To(Equal(local.ToURI(filepath.Join(dir, "docs", "my guides"))))
+			Expect(got[0].Nodes[0].Name).To(Equal("a #1.md"))
+			Expect(got[0].Nodes[0].Source).To(Equal(local.ToURI(filepath.Join(dir, "docs", "my guides", "a #1.md"))))
+			Expect(got[0].Nodes[0].Source).To(HaveSuffix("/my%20guides/a%20%231.md"))
+		})
+		It("fails on a file path", func() {
+			writeFile("docs/README.md", "r")
+			node := &api.Node{NodeSelector: &api.NodeSelector{Path: local.ToURI(filepath.Join(dir, "docs", "README.md"))}}
+			_, err := rh.ResolveNodeSelector(ctx, node)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ResolveDocumentation", func() {
+		It("resolves relative paths in the manifest", func() {
+			writeFile("docs/README.md", "r")
+			writeFile("docs/a.md", "a")
+			writeFile("docs/b.md", "b")
+			writeFile("docs/guides/setup.md", "s")
+			writeFile("manifest.yaml", `structure:
+- source: docs/README.md
+- name: multi.md
+  multiSource:
+  - docs/a.md
+  - ./docs/b.md
+- name: guides
+  nodesSelector:
+    path: docs/guides
+`)
+			doc, err := rh.ResolveDocumentation(ctx, filepath.Join(dir, "manifest.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(doc.Structure)).To(Equal(3))
+			Expect(doc.Structure[0].Source).To(Equal(local.ToURI(filepath.Join(dir, "docs", "README.md"))))
+			Expect(doc.Structure[1].MultiSource).To(Equal([]api.MultiSourceItem{
+				{Source: local.ToURI(filepath.Join(dir, "docs", "a.md"))},
+				{Source: local.ToURI(filepath.Join(dir, "docs", "b.md"))},
+			}))
+			Expect(doc.Structure[2].NodeSelector.Path).To(Equal(local.ToURI(filepath.Join(dir, "docs", "guides"))))
+			Expect(doc.Structure[0].Parent()).To(BeNil())
+		})
+		It("fails on missing relative source", func() {
+			writeFile("manifest.yaml", `structure:
+- source: missing.md
+`)
+			_, err := rh.ResolveDocumentation(ctx, local.ToURI(filepath.Join(dir, "manifest.yaml")))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcehandlers
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/klog/v2"
+)
+
+// CompileExcludePaths compiles api.NodeSelector ExcludePaths expressions
+func CompileExcludePaths(selector *api.NodeSelector) ([]*regexp.Regexp, error) {
+	var pfs []*regexp.Regexp
+	for _, ep := range selector.ExcludePaths {
+		rgx, err := regexp.Compile(ep)
+		if err != nil {
+			return nil, fmt.Errorf("nodesSelector %s with invalid path exclude expression %s: %w", selector.Path, ep, err)
+		}
+		pfs = append(pfs, rgx)
+	}
+	return pfs, nil
+}
+
+// FilterPath returns true if path is filtered by api.NodeSelector Depth or ExcludePaths
+func FilterPath(node *api.Node, pfs []*regexp.Regexp, path string) bool {
+	// depth filter
+	depth := strings.Count(path, "/") // depth is 1 for zero '/' 2 for one '/' etc.
+	if node.NodeSelector.Depth > 0 && depth+1 > int(node.NodeSelector.Depth) {
+		klog.V(6).Infof("node selector %s entry %s depth (%d) filter applied\n", node.NodeSelector.Path, path, node.NodeSelector.Depth)
+		return true
+	}
+	// path filter
+	for _, pf := range pfs {
+		if pf.MatchString(path) {
+			klog.V(6).Infof("node selector %s entry %s path filter %s applied\n", node.NodeSelector.Path, path, pf.String())
+			return true
+		}
+	}
+	return false
+}
+
+// BuildNode creates new api.Node for the rPath markdown and adds it to the vRoot tree.
+// The document source is bPrefix/rPath, and the created container nodes inherit the
+// api.ContainerNodeSourceLocation of vRoot extended with their relative path.
+func BuildNode(vRoot *api.Node, bPrefix string, rPath string) {
+	// build node
+	n := &api.Node{Source: fmt.Sprintf("%s/%s", bPrefix, rPath)}
+	n.Name = path.Base(rPath)
+	loc := path.Dir(rPath)
+	if loc == "." { // append in the root
+		n.SetParent(vRoot)
+		vRoot.Nodes = append(vRoot.Nodes, n)
+		return
+	}
+	// find the location in the tree
+	parent := vRoot
+	ls := strings.Split(loc, "/")
+	for _, l := range ls {
+		var found bool
+		for _, pn := range parent.Nodes {
+			if pn.Name == l {
+				found = true
+				parent = pn
+			}
+		}
+		if !found {
+			// create missing container api.Node
+			dn := &api.Node{Name: l}
+			dn.Properties = make(map[string]interface{})
+			dn.Properties[api.ContainerNodeSourceLocation] = fmt.Sprintf("%s/%s", parent.Properties[api.ContainerNodeSourceLocation], l)
+			dn.SetParent(parent)
+			parent.Nodes = append(parent.Nodes, dn)
+			parent = dn
+		}
+	}
+	n.SetParent(parent)
+	parent.Nodes = append(parent.Nodes, n)
+}
+
+// ResolveManifestRelativePaths traverses the node hierarchy of a manifest and resolves the relative
+// paths in Source, MultiSource and NodeSelector Path to absolute ones using the buildAbsLink function
+func ResolveManifestRelativePaths(node *api.Node, manifest string, buildAbsLink func(link string) (string, error)) error {
+	var errs error
+	if src := node.Source; src != "" {
+		u, err := url.Parse(src)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s source: %s", manifest, node.FullName("/"), src))
+		} else if !u.IsAbs() {
+			// resolve relative path
+			if node.Source, err = buildAbsLink(src); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("cannot resolve source relative path %s in node %s and manifest %s: %v", src, node.FullName("/"), manifest, err))
+			}
+		}
+	}
+	for idx := range node.MultiSource {
+		src := node.MultiSource[idx].Source
+		u, err := url.Parse(src)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s multiSource[%d]: %s", manifest, node.FullName("/"), idx, src))
+		} else if !u.IsAbs() {
+			// resolve relative path
+			if node.MultiSource[idx].Source, err = buildAbsLink(src); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("cannot resolve multiSource[%d] relative path %s in node %s and manifest %s: %v", idx, src, node.FullName("/"), manifest, err))
+			}
+		}
+	}
+	if node.NodeSelector != nil {
+		nsPath := node.NodeSelector.Path
+		u, err := url.Parse(nsPath)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid nodeSelector path %s in node %s", manifest, nsPath, node.FullName("/")))
+		} else if !u.IsAbs() {
+			// resolve relative path
+			if node.NodeSelector.Path, err = buildAbsLink(nsPath); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("cannot resolve nodeSelector relative path %s in node %s and manifest %s: %v", nsPath, node.FullName("/"), manifest, err))
+			}
+		}
+	}
+	for _, n := range node.Nodes {
+		if err := ResolveManifestRelativePaths(n, manifest, buildAbsLink); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
@@ -27,7 +27,6 @@ import (
 	"github.com/gardener/docforge/pkg/util/httpclient"
 	"github.com/gardener/docforge/pkg/util/osshim"
 	"github.com/google/go-github/v43/github"
-	"github.com/hashicorp/go-multierror"
 	"k8s.io/klog/v2"
 )
 
@@ -43,6 +42,9 @@ type PG struct {
 	muxSHA        sync.RWMutex
 	defBranches   map[string]string
 	muxDefBr      sync.Mutex
+	refs          map[string]string
+	tags          map[string][]string
+	muxRefs       sync.Mutex
 	muxCnt        sync.Mutex
 	hugoEnabled   bool
 }
@@ -58,6 +60,8 @@ func NewPG(client *github.Client, httpClient *http.Client, os osshim.Os, accepte
 		flagVars:      flagVars,
 		filesCache:    make(map[string]string),
 		defBranches:   make(map[string]string),
+		refs:          make(map[string]string),
+		tags:          make(map[string][]string),
 		hugoEnabled:   hugoEnabled,
 	}
 }
@@ -120,7 +124,7 @@ func (p *PG) ResolveDocumentation(ctx context.Context, uri string) (*api.Documen
 	}
 	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
 	n.SetParentsDownwards()
-	if err = p.resolveManifestRelativePaths(n, r); err != nil {
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, r.Raw, func(link string) (string, error) { return p.buildAbsLink(r, link) }); err != nil {
 		return nil, err
 	}
 	for _, el := range doc.Structure {
@@ -139,15 +143,9 @@ func (p *PG) ResolveNodeSelector(ctx context.Context, node *api.Node) ([]*api.No
 		return nil, fmt.Errorf("not a tree url: %s", r.Raw)
 	}
 	// prepare path filters
-	var pfs []*regexp.Regexp
-	if len(node.NodeSelector.ExcludePaths) > 0 {
-		for _, ep := range node.NodeSelector.ExcludePaths {
-			var rgx *regexp.Regexp
-			if rgx, err = regexp.Compile(ep); err != nil {
-				return nil, fmt.Errorf("manifest %s with invalid path exclude expression %s: %w", r.Raw, ep, err)
-			}
-			pfs = append(pfs, rgx)
-		}
+	pfs, err := resourcehandlers.CompileExcludePaths(node.NodeSelector)
+	if err != nil {
+		return nil, err
 	}
 	// prepare source prefixes
 	srcBlobPrefix := fmt.Sprintf("%s://%s/%s/%s/blob/%s/%s", r.URL.Scheme, r.URL.Host, r.Owner, r.Repo, r.Ref, r.Path)
@@ -239,7 +237,7 @@ func (p *PG) ResourceName(link string) (string, string) {
 
 // BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
 func (p *PG) BuildAbsLink(source, link string) (string, error) {
-	r, err := util.BuildResourceInfo(source)
+	r, err := p.getResolvedResourceInfo(context.Background(), source)
 	if err != nil {
 		return "", err
 	}
@@ -272,6 +270,48 @@ func (p *PG) GetRateLimit(ctx context.Context) (int, int, time.Time, error) {
 	return r.Core.Limit, r.Core.Remaining, r.Core.Reset.Time, nil
 }
 
+//========================= resourcehandlers.RefResolver =======================================================
+
+// ResourceRef implements the resourcehandlers.RefResolver#ResourceRef
+func (p *PG) ResourceRef(uri string) (string, string, error) {
+	r, err := util.BuildResourceInfo(uri)
+	if err != nil {
+		return "", "", err
+	}
+	host := r.URL.Host
+	if host == "raw.githubusercontent.com" {
+		host = "github.com"
+	} else {
+		host = strings.TrimPrefix(host, "raw.")
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", r.URL.Scheme, host, r.Owner, r.Repo), r.Ref, nil
+}
+
+// ResolveRef implements the resourcehandlers.RefResolver#ResolveRef
+func (p *PG) ResolveRef(ctx context.Context, uri string) (string, error) {
+	r, err := p.getResolvedResourceInfo(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	sha, resp, err := p.client.Repositories.GetCommitSHA1(ctx, r.Owner, r.Repo, r.Ref, "")
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", resourcehandlers.ErrResourceNotFound(uri)
+		}
+		return "", err
+	}
+	return sha, nil
+}
+
+// PinRef implements the resourcehandlers.RefResolver#PinRef
+func (p *PG) PinRef(uri string, sha string) (string, error) {
+	r, err := util.BuildResourceInfo(uri)
+	if err != nil {
+		return "", err
+	}
+	return r.GetRefURL(sha), nil
+}
+
 //==============================================================================================================
 
 // checkForLocalMapping returns repository root on file system if local mapping configuration
@@ -413,10 +453,10 @@ func (p *PG) getNodeSelectorTree(ctx context.Context, node *api.Node, r *util.Re
 			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, ePath)
 			continue
 		}
-		if filterPath(node, pfs, ePath) {
+		if resourcehandlers.FilterPath(node, pfs, ePath) {
 			continue
 		}
-		buildNode(vr, bPrefix, ePath)
+		resourcehandlers.BuildNode(vr, bPrefix, ePath)
 	}
 	return vr, nil
 }
@@ -447,13 +487,13 @@ func (p *PG) getLocalNodeSelectorTree(_ context.Context, node *api.Node, r *util
 			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, lPath)
 			return nil
 		}
-		if filterPath(node, pfs, lPath) {
+		if resourcehandlers.FilterPath(node, pfs, lPath) {
 			if entry.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-		buildNode(vr, bPrefix, lPath)
+		resourcehandlers.BuildNode(vr, bPrefix, lPath)
 		return nil
 	})
 	if err != nil {
@@ -500,10 +540,10 @@ func (p *PG) getCachedSubtree(ctx context.Context, node *api.Node, r *util.Resou
 			klog.V(6).Infof("node selector %s skip entry %s\n", node.NodeSelector.Path, fPath)
 			continue
 		}
-		if filterPath(node, pfs, fPath) {
+		if resourcehandlers.FilterPath(node, pfs, fPath) {
 			continue
 		}
-		buildNode(vr, bPrefix, fPath)
+		resourcehandlers.BuildNode(vr, bPrefix, fPath)
 	}
 	return vr, nil
 }
@@ -525,52 +565,6 @@ func (p *PG) getTree(ctx context.Context, r *util.ResourceInfo, recursive bool)
 	return gitTree, nil
 }
 
-// resolveManifestRelativePaths resolves relative paths in module manifest
-func (p *PG) resolveManifestRelativePaths(node *api.Node, r *util.ResourceInfo) error {
-	var errs error
-	if node.Source != "" {
-		u, err := url.Parse(node.Source)
-		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s source: %s", r.Raw, node.FullName("/"), node.Source))
-		} else if !u.IsAbs() {
-			// resolve relative path
-			if node.Source, err = p.buildAbsLink(r, node.Source); err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("cannot resolve source relative path %s in node %s and manifest %s", node.Source, node.FullName("/"), r.Raw))
-			}
-		}
-	}
-	if len(node.MultiSource) > 0 {
-		for idx, src := range node.MultiSource {
-			u, err := url.Parse(src)
-			if err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid node %s multiSource[%d]: %s", r.Raw, node.FullName("/"), idx, node.MultiSource[idx]))
-			} else if !u.IsAbs() {
-				// resolve relative path
-				if node.Source, err = p.buildAbsLink(r, src); err != nil {
-					errs = multierror.Append(errs, fmt.Errorf("cannot resolve multiSource[%d] relative path %s in node %s and manifest %s", idx, node.MultiSource[idx], node.FullName("/"), r.Raw))
-				}
-			}
-		}
-	}
-	if node.NodeSelector != nil {
-		u, err := url.Parse(node.NodeSelector.Path)
-		if err != nil {
-			errs = multierror.Append(errs, fmt.Errorf("manifest %s with invalid nodeSelector path %s in node %s", r.Raw, node.NodeSelector.Path, node.FullName("/")))
-		} else if !u.IsAbs() {
-			// resolve relative path
-			if node.NodeSelector.Path, err = p.buildAbsLink(r, node.NodeSelector.Path); err != nil {
-				errs = multierror.Append(errs, fmt.Errorf("cannot resolve nodeSelector relative path %s in node %s and manifest %s", node.NodeSelector.Path, node.FullName("/"), r.Raw))
-			}
-		}
-	}
-	for _, n := range node.Nodes {
-		if err := p.resolveManifestRelativePaths(n, r); err != nil {
-			errs = multierror.Append(errs, err)
-		}
-	}
-	return errs
-}
-
 // buildAbsLink builds absolute link if <link> is relative using <source> as a base
 // resourcehandlers.ErrResourceNotFound if target resource doesn't exist
 func (p *PG) buildAbsLink(source *util.ResourceInfo, link string) (string, error) {
@@ -672,6 +666,7 @@ func (p *PG) determineLinkType(source *util.ResourceInfo, rel *url.URL) (string,
 }
 
 // getResourceInfo build ResourceInfo and resolves 'DEFAULT_BRANCH' to repo default branch
+// and ref expressions (e.g. 'LATEST_RELEASE' or '~1.24') to repo tags
 func (p *PG) getResolvedResourceInfo(ctx context.Context, uri string) (*util.ResourceInfo, error) {
 	r, err := util.BuildResourceInfo(uri)
 	if err != nil {
@@ -683,6 +678,12 @@ func (p *PG) getResolvedResourceInfo(ctx context.Context, uri string) (*util.Res
 			return nil, err
 		}
 		r.Ref = defaultBranch
+	} else if resourcehandlers.IsRefExpression(r.Ref) {
+		ref, err := p.getRefExpression(ctx, r.Owner, r.Repo, r.Ref)
+		if err != nil {
+			return nil, err
+		}
+		r.Ref = ref
 	}
 	return r, nil
 }
@@ -704,70 +705,67 @@ func (p *PG) getDefaultBranch(ctx context.Context, owner string, repository stri
 	return def, nil
 }
 
-func (p *PG) getFileSHA(key string) (string, bool) {
-	p.muxSHA.RLock()
-	defer p.muxSHA.RUnlock()
-	val, ok := p.filesCache[key]
-	return val, ok
-}
-
-// filterPath returns true if path is filtered by api.NodeSelector Depth or ExcludePaths
-func filterPath(node *api.Node, pfs []*regexp.Regexp, path string) bool {
-	// depth filter
-	depth := strings.Count(path, "/") // depth is 1 for zero '/' 2 for one '/' etc.
-	if node.NodeSelector.Depth > 0 && depth+1 > int(node.NodeSelector.Depth) {
-		klog.V(6).Infof("node selector %s entry %s depth (%d) filter applied\n", node.NodeSelector.Path, path, node.NodeSelector.Depth)
-		return true
+// getRefExpression resolves ref expression to a tag of given repo
+func (p *PG) getRefExpression(ctx context.Context, owner string, repository string, expr string) (string, error) {
+	p.muxRefs.Lock()
+	defer p.muxRefs.Unlock()
+	repoKey := fmt.Sprintf("%s/%s", owner, repository)
+	key := fmt.Sprintf("%s/%s", repoKey, expr)
+	if ref, ok := p.refs[key]; ok {
+		return ref, nil
 	}
-	// path filter
-	var rgx *regexp.Regexp
-	for _, pf := range pfs {
-		if rgx.Match([]byte(path)) {
-			rgx = pf
-			break
+	var ref string
+	if expr == resourcehandlers.LatestRelease {
+		release, resp, err := p.client.Repositories.GetLatestRelease(ctx, owner, repository)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return "", fmt.Errorf("no release found for repository %s", repoKey)
+			}
+			return "", err
+		}
+		ref = release.GetTagName()
+	} else {
+		tags, ok := p.tags[repoKey]
+		if !ok {
+			var err error
+			if tags, err = p.listTags(ctx, owner, repository); err != nil {
+				return "", err
+			}
+			p.tags[repoKey] = tags
+		}
+		var err error
+		if ref, err = resourcehandlers.MatchTag(expr, tags); err != nil {
+			return "", fmt.Errorf("resolving ref of repository %s fails: %v", repoKey, err)
 		}
 	}
-	if rgx != nil {
-		klog.V(6).Infof("node selector %s entry %s path filter %s applied\n", node.NodeSelector.Path, path, rgx.String())
-		return true
-	}
-	return false
+	p.refs[key] = ref
+	return ref, nil
 }
 
-// buildNode creates new api.Node for the rPath markdown and adds it to the vRoot tree
-func buildNode(vRoot *api.Node, bPrefix string, rPath string) {
-	// build node
-	n := &api.Node{Source: fmt.Sprintf("%s/%s", bPrefix, rPath)}
-	n.Name = path.Base(rPath)
-	loc := path.Dir(rPath)
-	if loc == "." { // append in the root
-		n.SetParent(vRoot)
-		vRoot.Nodes = append(vRoot.Nodes, n)
-		return
-	}
-	// find the location in the tree
-	parent := vRoot
-	ls := strings.Split(loc, "/")
-	for _, l := range ls {
-		var found bool
-		for _, pn := range parent.Nodes {
-			if pn.Name == l {
-				found = true
-				parent = pn
-			}
+// listTags lists the tag names of given repo
+func (p *PG) listTags(ctx context.Context, owner string, repository string) ([]string, error) {
+	var tags []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		ts, resp, err := p.client.Repositories.ListTags(ctx, owner, repository, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ts {
+			tags = append(tags, t.GetName())
 		}
-		if !found {
-			// create missing container api.Node
-			dn := &api.Node{Name: l}
-			dn.Properties = make(map[string]interface{})
-			dn.Properties[api.ContainerNodeSourceLocation] = fmt.Sprintf("%s/%s", parent.Properties[api.ContainerNodeSourceLocation], l)
-			dn.SetParent(parent)
-			parent.Nodes = append(parent.Nodes, dn)
-			parent = dn
+		if resp == nil || resp.NextPage == 0 {
+			return tags, nil
 		}
+		opts.Page = resp.NextPage
 	}
-	n.SetParent(parent)
-	parent.Nodes = append(parent.Nodes, n)
+}
+
+func (p *PG) getFileSHA(key string) (string, bool) {
+	p.muxSHA.RLock()
+	defer p.muxSHA.RUnlock()
+	val, ok := p.filesCache[key]
+	return val, ok
 }
 
 // transform builds git.Info from a commits list
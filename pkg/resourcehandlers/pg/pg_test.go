@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pg_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/pg"
+	"github.com/gardener/docforge/pkg/util/osshim"
+	"github.com/google/go-github/v43/github"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PG", func() {
+	var (
+		server   *httptest.Server
+		rh       resourcehandlers.ResourceHandler
+		tagLists int
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		server = httptest.NewServer(mux)
+		client := github.NewClient(server.Client())
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+		rh = pg.NewPG(client, server.Client(), &osshim.OsShim{}, []string{"github.com"}, map[string]string{}, map[string]string{}, false)
+		tagLists = 0
+		mux.HandleFunc("/repos/owner/repo/tags", func(w http.ResponseWriter, r *http.Request) {
+			tagLists++
+			_, _ = fmt.Fprint(w, `[{"name": "v1.25.0"}, {"name": "v1.24.2"}, {"name": "v1.24.1"}]`)
+		})
+		mux.HandleFunc("/repos/owner/repo/contents/docs/guides", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("ref")).To(Equal("v1.24.2"))
+			_, _ = fmt.Fprint(w, `[{"type": "file", "name": "setup.md", "sha": "s1", "html_url": "https://github.com/owner/repo/blob/v1.24.2/docs/guides/setup.md"}]`)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("BuildAbsLink", func() {
+		It("resolves the ref expression of the source", func() {
+			link, err := rh.BuildAbsLink("https://github.com/owner/repo/blob/~1.24/docs/README.md", "./guides/setup.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("https://github.com/owner/repo/blob/v1.24.2/docs/guides/setup.md"))
+			link, err = rh.BuildAbsLink("https://github.com/owner/repo/blob/~1.24/docs/README.md", "./guides/setup.md#usage")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("https://github.com/owner/repo/blob/v1.24.2/docs/guides/setup.md#usage"))
+			Expect(tagLists).To(Equal(1))
+		})
+		It("reports missing resources at the resolved ref", func() {
+			_, err := rh.BuildAbsLink("https://github.com/owner/repo/blob/~1.24/docs/README.md", "./guides/missing.md")
+			Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+			Expect(err.Error()).To(ContainSubstring("/blob/v1.24.2/docs/guides/missing.md"))
+		})
+	})
+})
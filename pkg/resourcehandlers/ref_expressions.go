@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcehandlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// LatestRelease is a ref expression referencing the tag of the latest repository release
+	LatestRelease = "LATEST_RELEASE"
+	// LatestTag is a ref expression referencing the highest semantic version tag
+	LatestTag = "LATEST_TAG"
+)
+
+// IsRefExpression returns true if ref is LATEST_RELEASE, LATEST_TAG or a semantic version constraint
+// resolved to the highest matching tag, e.g. `~1.24`, `^1.2`, `=1.24.x` or `>=1.2,<2`
+func IsRefExpression(ref string) bool {
+	if ref == LatestRelease || ref == LatestTag {
+		return true
+	}
+	_, ok := versionConstraint(ref)
+	return ok
+}
+
+// MatchTag returns the highest semantic version tag matching the LATEST_TAG or semantic version
+// constraint ref expression. Pre-release tags are matched only by constraints with pre-release.
+func MatchTag(expr string, tags []string) (string, error) {
+	var c *semver.Constraints
+	if expr != LatestTag {
+		var ok bool
+		if c, ok = versionConstraint(expr); !ok {
+			return "", fmt.Errorf("invalid ref expression %s", expr)
+		}
+	}
+	var (
+		match string
+		max   *semver.Version
+	)
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil {
+			continue // not a semantic version tag
+		}
+		if c == nil && v.Prerelease() != "" || c != nil && !c.Check(v) {
+			continue
+		}
+		if max == nil || v.GreaterThan(max) {
+			match, max = t, v
+		}
+	}
+	if max == nil {
+		return "", fmt.Errorf("no tag matches %s", expr)
+	}
+	return match, nil
+}
+
+// versionConstraint parses ref as semantic version constraint. Constraints start with an operator,
+// refs without it, e.g. `v1.24.0` or `v1.24.x`, reference the branch or tag with the same name
+func versionConstraint(ref string) (*semver.Constraints, bool) {
+	if ref == "" || !strings.ContainsAny(ref[:1], "~^<>=!") {
+		return nil, false
+	}
+	c, err := semver.NewConstraint(ref)
+	if err != nil {
+		return nil, false
+	}
+	return c, true
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcehandlers_test
+
+import (
+	"testing"
+
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRefExpression(t *testing.T) {
+	testCases := []struct {
+		ref  string
+		want bool
+	}{
+		{"LATEST_RELEASE", true},
+		{"LATEST_TAG", true},
+		{"~1.24", true},
+		{"^v1.2", true},
+		{">=1.2,<2", true},
+		{"=v1.24.x", true},
+		{"v1.24.x", false},
+		{"1.x", false},
+		{"1.2.*", false},
+		{"master", false},
+		{"DEFAULT_BRANCH", false},
+		{"v1.24.0", false},
+		{"1.24", false},
+		{"release-v1.24", false},
+		{"~release", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			assert.Equal(t, tc.want, resourcehandlers.IsRefExpression(tc.ref))
+		})
+	}
+}
+
+func TestMatchTag(t *testing.T) {
+	tags := []string{"release-1.25", "v1.23.5", "v1.24.0", "v1.24.3", "v1.24.10", "v1.25.0", "v1.26.0-rc.1", "v2.0.0-alpha"}
+	testCases := []struct {
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{"LATEST_TAG", "v1.25.0", false},
+		{"~1.24", "v1.24.10", false},
+		{"=1.24.x", "v1.24.10", false},
+		{"v1.24.x", "", true},
+		{"^1.23", "v1.25.0", false},
+		{">=1.23,<1.24", "v1.23.5", false},
+		{">=1.26.0-rc.0", "v2.0.0-alpha", false},
+		{"~1.27", "", true},
+		{"master", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := resourcehandlers.MatchTag(tc.expr, tags)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
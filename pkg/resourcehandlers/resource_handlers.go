@@ -9,6 +9,7 @@ package resourcehandlers
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"reflect"
 	"time"
 
@@ -56,6 +57,46 @@ type ResourceHandler interface {
 	GetRateLimit(ctx context.Context) (int, int, time.Time, error)
 }
 
+// RefResolver is implemented by the resource handlers of versioned repositories, which
+// resources are referenced by a ref (branch, tag or commit SHA) in their URIs
+//counterfeiter:generate . RefResolver
+type RefResolver interface {
+	// ResourceRef returns the repository URL and the ref of the resource at uri
+	ResourceRef(uri string) (string, string, error)
+	// ResolveRef returns the commit SHA the ref of the resource at uri points to
+	ResolveRef(ctx context.Context, uri string) (string, error)
+	// PinRef returns the uri with the ref replaced by the commit SHA, or by another ref (branch or tag)
+	PinRef(uri string, sha string) (string, error)
+}
+
+// SourceKeyer is implemented by the resource handlers which URIs carry the ref in the query,
+// e.g. `?at=<ref>` of Bitbucket Server
+type SourceKeyer interface {
+	// SourceKey returns the key identifying the resource at uri, i.e. the uri without the query
+	// and the fragment that don't select the resource
+	SourceKey(uri string) string
+}
+
+// SourceKey returns the key identifying the resource at uri with the handler h, see SourceKeyer.
+// The key is the uri without query & fragment, if h is not a SourceKeyer.
+func SourceKey(h ResourceHandler, uri string) string {
+	if sk, ok := h.(SourceKeyer); ok {
+		return sk.SourceKey(uri)
+	}
+	u, err := url.Parse(uri)
+	if err != nil || (u.RawQuery == "" && !u.ForceQuery && u.Fragment == "") {
+		return uri
+	}
+	return fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, u.Path)
+}
+
+// LinkPinner is implemented by the resource handlers rewriting the refs of the absolute links
+// in the documents, e.g. to the commit SHAs of a lock file
+type LinkPinner interface {
+	// Pin returns the link with its ref pinned
+	Pin(link string) (string, error)
+}
+
 // Registry can register and return resource handlers for an url
 //counterfeiter:generate . Registry
 type Registry interface {
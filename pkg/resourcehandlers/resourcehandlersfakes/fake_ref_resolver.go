@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+// Code generated by counterfeiter. DO NOT EDIT.
+package resourcehandlersfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+)
+
+type FakeRefResolver struct {
+	PinRefStub        func(string, string) (string, error)
+	pinRefMutex       sync.RWMutex
+	pinRefArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	pinRefReturns struct {
+		result1 string
+		result2 error
+	}
+	pinRefReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ResolveRefStub        func(context.Context, string) (string, error)
+	resolveRefMutex       sync.RWMutex
+	resolveRefArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	resolveRefReturns struct {
+		result1 string
+		result2 error
+	}
+	resolveRefReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ResourceRefStub        func(string) (string, string, error)
+	resourceRefMutex       sync.RWMutex
+	resourceRefArgsForCall []struct {
+		arg1 string
+	}
+	resourceRefReturns struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	resourceRefReturnsOnCall map[int]struct {
+		result1 string
+		result2 string
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRefResolver) PinRef(arg1 string, arg2 string) (string, error) {
+	fake.pinRefMutex.Lock()
+	ret, specificReturn := fake.pinRefReturnsOnCall[len(fake.pinRefArgsForCall)]
+	fake.pinRefArgsForCall = append(fake.pinRefArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.PinRefStub
+	fakeReturns := fake.pinRefReturns
+	fake.recordInvocation("PinRef", []interface{}{arg1, arg2})
+	fake.pinRefMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRefResolver) PinRefCallCount() int {
+	fake.pinRefMutex.RLock()
+	defer fake.pinRefMutex.RUnlock()
+	return len(fake.pinRefArgsForCall)
+}
+
+func (fake *FakeRefResolver) PinRefCalls(stub func(string, string) (string, error)) {
+	fake.pinRefMutex.Lock()
+	defer fake.pinRefMutex.Unlock()
+	fake.PinRefStub = stub
+}
+
+func (fake *FakeRefResolver) PinRefArgsForCall(i int) (string, string) {
+	fake.pinRefMutex.RLock()
+	defer fake.pinRefMutex.RUnlock()
+	argsForCall := fake.pinRefArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRefResolver) PinRefReturns(result1 string, result2 error) {
+	fake.pinRefMutex.Lock()
+	defer fake.pinRefMutex.Unlock()
+	fake.PinRefStub = nil
+	fake.pinRefReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRefResolver) PinRefReturnsOnCall(i int, result1 string, result2 error) {
+	fake.pinRefMutex.Lock()
+	defer fake.pinRefMutex.Unlock()
+	fake.PinRefStub = nil
+	if fake.pinRefReturnsOnCall == nil {
+		fake.pinRefReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.pinRefReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRefResolver) ResolveRef(arg1 context.Context, arg2 string) (string, error) {
+	fake.resolveRefMutex.Lock()
+	ret, specificReturn := fake.resolveRefReturnsOnCall[len(fake.resolveRefArgsForCall)]
+	fake.resolveRefArgsForCall = append(fake.resolveRefArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ResolveRefStub
+	fakeReturns := fake.resolveRefReturns
+	fake.recordInvocation("ResolveRef", []interface{}{arg1, arg2})
+	fake.resolveRefMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRefResolver) ResolveRefCallCount() int {
+	fake.resolveRefMutex.RLock()
+	defer fake.resolveRefMutex.RUnlock()
+	return len(fake.resolveRefArgsForCall)
+}
+
+func (fake *FakeRefResolver) ResolveRefCalls(stub func(context.Context, string) (string, error)) {
+	fake.resolveRefMutex.Lock()
+	defer fake.resolveRefMutex.Unlock()
+	fake.ResolveRefStub = stub
+}
+
+func (fake *FakeRefResolver) ResolveRefArgsForCall(i int) (context.Context, string) {
+	fake.resolveRefMutex.RLock()
+	defer fake.resolveRefMutex.RUnlock()
+	argsForCall := fake.resolveRefArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRefResolver) ResolveRefReturns(result1 string, result2 error) {
+	fake.resolveRefMutex.Lock()
+	defer fake.resolveRefMutex.Unlock()
+	fake.ResolveRefStub = nil
+	fake.resolveRefReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRefResolver) ResolveRefReturnsOnCall(i int, result1 string, result2 error) {
+	fake.resolveRefMutex.Lock()
+	defer fake.resolveRefMutex.Unlock()
+	fake.ResolveRefStub = nil
+	if fake.resolveRefReturnsOnCall == nil {
+		fake.resolveRefReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.resolveRefReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRefResolver) ResourceRef(arg1 string) (string, string, error) {
+	fake.resourceRefMutex.Lock()
+	ret, specificReturn := fake.resourceRefReturnsOnCall[len(fake.resourceRefArgsForCall)]
+	fake.resourceRefArgsForCall = append(fake.resourceRefArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ResourceRefStub
+	fakeReturns := fake.resourceRefReturns
+	fake.recordInvocation("ResourceRef", []interface{}{arg1})
+	fake.resourceRefMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRefResolver) ResourceRefCallCount() int {
+	fake.resourceRefMutex.RLock()
+	defer fake.resourceRefMutex.RUnlock()
+	return len(fake.resourceRefArgsForCall)
+}
+
+func (fake *FakeRefResolver) ResourceRefCalls(stub func(string) (string, string, error)) {
+	fake.resourceRefMutex.Lock()
+	defer fake.resourceRefMutex.Unlock()
+	fake.ResourceRefStub = stub
+}
+
+func (fake *FakeRefResolver) ResourceRefArgsForCall(i int) string {
+	fake.resourceRefMutex.RLock()
+	defer fake.resourceRefMutex.RUnlock()
+	argsForCall := fake.resourceRefArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRefResolver) ResourceRefReturns(result1 string, result2 string, result3 error) {
+	fake.resourceRefMutex.Lock()
+	defer fake.resourceRefMutex.Unlock()
+	fake.ResourceRefStub = nil
+	fake.resourceRefReturns = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRefResolver) ResourceRefReturnsOnCall(i int, result1 string, result2 string, result3 error) {
+	fake.resourceRefMutex.Lock()
+	defer fake.resourceRefMutex.Unlock()
+	fake.ResourceRefStub = nil
+	if fake.resourceRefReturnsOnCall == nil {
+		fake.resourceRefReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 string
+			result3 error
+		})
+	}
+	fake.resourceRefReturnsOnCall[i] = struct {
+		result1 string
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRefResolver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.pinRefMutex.RLock()
+	defer fake.pinRefMutex.RUnlock()
+	fake.resolveRefMutex.RLock()
+	defer fake.resolveRefMutex.RUnlock()
+	fake.resourceRefMutex.RLock()
+	defer fake.resourceRefMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRefResolver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ resourcehandlers.RefResolver = new(FakeRefResolver)
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/util/httpclient"
+)
+
+// Web implements resourcehandlers.ResourceHandler interface for resources served over HTTP(S).
+// It accepts any http(s) URL, hence it should be registered after all other handlers.
+// Reading directory content is not supported.
+type Web struct {
+	client      httpclient.Client
+	flagVars    map[string]string
+	hugoEnabled bool
+}
+
+// NewWeb creates new Web resource handler.
+// The client is expected to use caching transport (e.g. httpcache), so that the content is revalidated with conditional requests.
+func NewWeb(client httpclient.Client, flagVars map[string]string, hugoEnabled bool) resourcehandlers.ResourceHandler {
+	return &Web{
+		client:      client,
+		flagVars:    flagVars,
+		hugoEnabled: hugoEnabled,
+	}
+}
+
+//========================= resourcehandlers.ResourceHandler ===================================================
+
+// Accept implements the resourcehandlers.ResourceHandler#Accept
+func (w *Web) Accept(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// ResolveDocumentation implements the resourcehandlers.ResourceHandler#ResolveDocumentation
+func (w *Web) ResolveDocumentation(ctx context.Context, uri string) (*api.Documentation, error) {
+	cnt, err := w.Read(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var doc *api.Documentation
+	if doc, err = api.ParseWithMetadata(cnt, "master", w.flagVars, w.hugoEnabled); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %s. %+v", uri, err)
+	}
+	n := &api.Node{Nodes: doc.Structure, NodeSelector: doc.NodeSelector}
+	n.SetParentsDownwards()
+	if err = resourcehandlers.ResolveManifestRelativePaths(n, uri, func(link string) (string, error) { return w.BuildAbsLink(uri, link) }); err != nil {
+		return nil, err
+	}
+	for _, el := range doc.Structure {
+		el.SetParent(nil)
+	}
+	return doc, nil
+}
+
+// ResolveNodeSelector implements the resourcehandlers.ResourceHandler#ResolveNodeSelector
+func (w *Web) ResolveNodeSelector(_ context.Context, node *api.Node) ([]*api.Node, error) {
+	return nil, fmt.Errorf("nodeSelector path %s for node %s: directory listing is not supported over HTTP", node.NodeSelector.Path, node.FullName("/"))
+}
+
+// Read implements the resourcehandlers.ResourceHandler#Read
+func (w *Web) Read(ctx context.Context, uri string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resourcehandlers.ErrResourceNotFound(uri)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("reading %s fails with HTTP status: %d", uri, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ReadGitInfo implements the resourcehandlers.ResourceHandler#ReadGitInfo
+// Git info is not available for web resources
+func (w *Web) ReadGitInfo(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+// ResourceName implements the resourcehandlers.ResourceHandler#ResourceName
+func (w *Web) ResourceName(link string) (string, string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", ""
+	}
+	ext := path.Ext(u.Path)
+	name := strings.TrimSuffix(path.Base(u.Path), ext)
+	return name, ext
+}
+
+// BuildAbsLink implements the resourcehandlers.ResourceHandler#BuildAbsLink
+// The link is resolved as URL reference of the source, the existence of the target is not verified
+func (w *Web) BuildAbsLink(source, link string) (string, error) {
+	l, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	if l.IsAbs() {
+		return link, nil // already absolute
+	}
+	s, err := url.Parse(source)
+	if err != nil {
+		return "", err
+	}
+	return s.ResolveReference(l).String(), nil
+}
+
+// GetRawFormatLink implements the resourcehandlers.ResourceHandler#GetRawFormatLink
+func (w *Web) GetRawFormatLink(absLink string) (string, error) {
+	return absLink, nil
+}
+
+// GetClient implements the resourcehandlers.ResourceHandler#GetClient
+func (w *Web) GetClient() httpclient.Client {
+	return w.client
+}
+
+// GetRateLimit implements the resourcehandlers.ResourceHandler#GetRateLimit
+func (w *Web) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return -1, -1, time.Now(), nil
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2022 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gardener/docforge/pkg/api"
+	"github.com/gardener/docforge/pkg/resourcehandlers"
+	"github.com/gardener/docforge/pkg/resourcehandlers/web"
+	"github.com/gregjones/httpcache"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Web", func() {
+	var (
+		server      *httptest.Server
+		rh          resourcehandlers.ResourceHandler
+		ctx         context.Context
+		conditional int
+	)
+
+	BeforeEach(func() {
+		conditional = 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/docs/README.md", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "no-cache")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				conditional++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = fmt.Fprint(w, "# README")
+		})
+		mux.HandleFunc("/docs/manifest.yaml", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, "structure:\n- source: README.md\n- source: ../api/ref.md\n")
+		})
+		server = httptest.NewServer(mux)
+		rh = web.NewWeb(httpcache.NewMemoryCacheTransport().Client(), map[string]string{}, false)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("accepts http(s) URLs", func() {
+		Expect(rh.Accept("https://example.com/docs/README.md")).To(BeTrue())
+		Expect(rh.Accept("http://example.com/docs/README.md")).To(BeTrue())
+		Expect(rh.Accept("file:///docs/README.md")).To(BeFalse())
+		Expect(rh.Accept("docs/README.md")).To(BeFalse())
+	})
+
+	It("reads content with conditional requests", func() {
+		got, err := rh.Read(ctx, server.URL+"/docs/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("# README"))
+		got, err = rh.Read(ctx, server.URL+"/docs/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("# README"))
+		Expect(conditional).To(Equal(1))
+	})
+
+	It("returns not found error", func() {
+		_, err := rh.Read(ctx, server.URL+"/missing.md")
+		Expect(err).To(BeAssignableToTypeOf(resourcehandlers.ErrResourceNotFound("")))
+	})
+
+	It("resolves documentation relative paths", func() {
+		doc, err := rh.ResolveDocumentation(ctx, server.URL+"/docs/manifest.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Structure[0].Source).To(Equal(server.URL + "/docs/README.md"))
+		Expect(doc.Structure[1].Source).To(Equal(server.URL + "/api/ref.md"))
+	})
+
+	It("builds absolute links", func() {
+		got, err := rh.BuildAbsLink("https://example.com/docs/guide/README.md", "../images/logo.png?v=1#top")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal("https://example.com/docs/images/logo.png?v=1#top"))
+	})
+
+	It("does not support node selectors", func() {
+		_, err := rh.ResolveNodeSelector(ctx, &api.Node{NodeSelector: &api.NodeSelector{Path: server.URL + "/docs"}})
+		Expect(err).To(HaveOccurred())
+	})
+})
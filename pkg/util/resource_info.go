@@ -88,6 +88,24 @@ func (ri *ResourceInfo) GetRepoURL() string {
 	return fmt.Sprintf("%s://%s/%s/%s", ri.URL.Scheme, ri.URL.Host, ri.Owner, ri.Repo)
 }
 
+// GetRefURL returns the resource URL with the ref replaced by ref
+func (ri *ResourceInfo) GetRefURL(ref string) string {
+	// the ref is the 3rd path segment in raw host URLs, otherwise the 4th one
+	idx := 3
+	if strings.HasPrefix(ri.URL.Host, "raw.") {
+		idx = 2
+	}
+	segments := strings.Split(ri.URL.Path, "/")
+	if len(segments) <= idx+1 {
+		return ri.Raw
+	}
+	segments[idx+1] = ref // the path starts with '/'
+	u := *ri.URL
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
 // GetRawURL returns the GitHub raw URL if the resource is 'blob', otherwise returns the origin URL
 func (ri *ResourceInfo) GetRawURL() string {
 	if ri.IsRaw || ri.Type != "blob" { // if already raw or if not a blob -> return without modification
@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // FSWriter is implementation of Writer interface for writing blobs to the file system
@@ -19,6 +20,10 @@ type FSWriter struct {
 	Root string
 	Ext  string
 	Hugo bool
+	// PageBundles writes the Hugo documents as leaf bundles, i.e. `name.md` as `name/index.md`
+	PageBundles bool
+	// MkDocs writes the section files `_index.md` as `index.md`
+	MkDocs bool
 }
 
 func (f *FSWriter) Write(name, path string, docBlob []byte, node *api.Node) error {
@@ -45,6 +50,14 @@ func (f *FSWriter) Write(name, path string, docBlob []byte, node *api.Node) erro
 			path = filepath.Join(path, name)
 			name = "_index.md"
 		}
+		if f.PageBundles && len(docBlob) > 0 && name != "_index.md" && name != "index.md" {
+			path = filepath.Join(path, strings.TrimSuffix(name, ".md"))
+			name = "index.md"
+		}
+	}
+
+	if f.MkDocs && node != nil && name == "_index.md" {
+		name = "index.md"
 	}
 
 	p := filepath.Join(f.Root, path)
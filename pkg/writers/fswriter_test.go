@@ -22,6 +22,8 @@ func TestWrite(t *testing.T) {
 		path         string
 		docBlob      []byte
 		node         *api.Node
+		pageBundles  bool
+		mkdocs       bool
 		wantErr      error
 		wantFileName string
 		wantContent  string
@@ -44,13 +46,46 @@ func TestWrite(t *testing.T) {
 			wantFileName: `test`,
 			wantContent:  `# Test`,
 		},
+		{
+			name:         "test.md",
+			path:         "a/b",
+			docBlob:      []byte("# Test"),
+			node:         &api.Node{},
+			pageBundles:  true,
+			wantErr:      nil,
+			wantFileName: `test/index.md`,
+			wantContent:  `# Test`,
+		},
+		{
+			name:         "_index.md",
+			path:         "a/b",
+			docBlob:      []byte("# Test"),
+			node:         &api.Node{},
+			pageBundles:  true,
+			wantErr:      nil,
+			wantFileName: `_index.md`,
+			wantContent:  `# Test`,
+		},
+		{
+			name:         "_index.md",
+			path:         "a/b",
+			docBlob:      []byte("# Test"),
+			node:         &api.Node{},
+			mkdocs:       true,
+			wantErr:      nil,
+			wantFileName: `index.md`,
+			wantContent:  `# Test`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
 			testFolder := fmt.Sprintf("test%s", uuid.New().String())
 			testPath := filepath.Join(os.TempDir(), testFolder)
 			fs := &FSWriter{
-				Root: testPath,
+				Root:        testPath,
+				Hugo:        tc.pageBundles,
+				PageBundles: tc.pageBundles,
+				MkDocs:      tc.mkdocs,
 			}
 			fPath := filepath.Join(fs.Root, tc.path, tc.wantFileName)
 			defer func() {